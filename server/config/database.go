@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
@@ -40,6 +41,10 @@ func InitDatabase() {
 		panic("Failed to connect to database: " + err.Error())
 	}
 
+	if err := migrateLegacyRoles(DB); err != nil {
+		panic("Role migration failed: " + err.Error())
+	}
+
 	// Migration database schema
 	if err := DB.AutoMigrate(
 		&models.User{},
@@ -50,6 +55,22 @@ func InitDatabase() {
 		&models.Payment{},
 		&models.UserTicket{},
 		&models.WithdrawalRequest{},
+		&models.BalanceHold{},
+		&models.PayoutAccount{},
+		&models.WithdrawalStatusHistory{},
+		&models.PayoutBatch{},
+		&models.IdentityVerification{},
+		&models.LedgerEntry{},
+		&models.WithdrawalPolicyViolation{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.RecoveryCode{},
+		&models.LinkedIdentity{},
+		&models.APIClient{},
+		&models.RoleAssignment{},
+		&models.OrganizerEarning{},
+		&models.AuditLog{},
+		&models.ExportJob{},
 	); err != nil {
 		panic("Migration failed: " + err.Error())
 	}
@@ -65,3 +86,34 @@ func InitDatabase() {
 
 	fmt.Println("✅ Database configured")
 }
+
+// migrateLegacyRoles moves accounts of the old admin role to super_admin before AutoMigrate narrows the
+// role enum, MySQL would refuse the change or blank the role of every admin otherwise. The enum is
+// widened first so both values are valid while the rows are updated
+func migrateLegacyRoles(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.User{}) {
+		return nil
+	}
+
+	var columnType string
+	if err := db.Raw(`SELECT COLUMN_TYPE FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users' AND COLUMN_NAME = 'role'`).
+		Scan(&columnType).Error; err != nil {
+		return err
+	}
+	if !strings.Contains(columnType, "'admin'") {
+		return nil
+	}
+
+	if err := db.Exec(`ALTER TABLE users MODIFY role
+		enum('admin','super_admin','finance','event_manager','checkin_staff','organizer','user') DEFAULT 'user'`).Error; err != nil {
+		return err
+	}
+
+	res := db.Exec("UPDATE users SET role = 'super_admin' WHERE role = 'admin'")
+	if res.Error != nil {
+		return res.Error
+	}
+	fmt.Printf("✅ Moved %d admin accounts to super_admin\n", res.RowsAffected)
+	return nil
+}
@@ -15,7 +15,7 @@ type Config struct {
 	ServerHost string
 
 	// Security settings
-	ApiKeys             string
+	ApiKeys             string // bootstrap keys, seeded as first-party API clients
 	AllowedOrigins      []string
 	RateLimitAttempts   int
 	RateLimitDuration   time.Duration
@@ -37,6 +37,13 @@ type Config struct {
 	AccessTokenSecret  string
 	RefreshTokenSecret string
 
+	// two-factor settings
+	TwoFactorEncryptionKey string
+
+	// lifetime of an admin "act as user" token, it can't be refreshed. Values above the 60 minute
+	// access token lifetime are capped to it since a revoked session is only remembered that long
+	ImpersonationTTL time.Duration
+
 	// Email settings
 	SMTPHost     string
 	SMTPPort     int
@@ -60,6 +67,19 @@ type Config struct {
 	GoogleRedirectURL   string
 	FrontendRedirectURL string
 
+	// other sign-in providers, each one is only enabled when its client id is set
+	GithubClientID       string
+	GithubClientSecret   string
+	GithubRedirectURL    string
+	FacebookClientID     string
+	FacebookClientSecret string
+	FacebookRedirectURL  string
+	OIDCProviderName     string
+	OIDCIssuer           string
+	OIDCClientID         string
+	OIDCClientSecret     string
+	OIDCRedirectURL      string
+
 	// stripe settings
 	StripeWebhookSecret  string
 	StripeCancelUrlDev   string
@@ -68,6 +88,35 @@ type Config struct {
 	StripeSuccessUrlProd string
 	StripeSecretKey      string
 	StripePublishableKey string
+
+	// withdrawal policy, a zero cap or threshold disables that rule
+	WithdrawalMinAmount        float64
+	WithdrawalMaxAmount        float64
+	WithdrawalFeeType          string
+	WithdrawalFeeValue         float64
+	WithdrawalDailyCap         float64
+	WithdrawalMonthlyCap       float64
+	WithdrawalRefundCoolingOff time.Duration
+	WithdrawalKYCThreshold     float64
+
+	// organizer marketplace, the fee is copied onto each event when it is created
+	PlatformFeePercent       float64
+	OrganizerSettlementDelay time.Duration
+
+	// audit entries older than this are purged every night
+	AuditRetentionDays int
+
+	// PDF exports are built in memory, longer reports are cut off at this many rows
+	ExportPDFMaxRows int
+	// XLSX workbooks are zipped in memory when they are written, longer reports are refused
+	ExportXLSXMaxRows int
+
+	// export jobs, finished files are kept in the storage directory until they expire
+	ExportStorageDir         string
+	ExportFileTTL            time.Duration
+	ExportWorkerConcurrency  int
+	ExportWorkerPollInterval time.Duration
+	ExportJobTimeout         time.Duration
 }
 
 var AppConfig *Config
@@ -84,6 +133,18 @@ func LoadConfig() {
 		GoogleRedirectURL:   getEnvOrDefault("GOOGLE_REDIRECT_URL", "http://localhost:5005/api/v1/users/google/callback"),
 		FrontendRedirectURL: getEnvOrDefault("FRONTEND_REDIRECT_URL", "http://localhost:5173"),
 
+		GithubClientID:       getEnvOrDefault("GITHUB_CLIENT_ID", ""),
+		GithubClientSecret:   getEnvOrDefault("GITHUB_CLIENT_SECRET", ""),
+		GithubRedirectURL:    getEnvOrDefault("GITHUB_REDIRECT_URL", "http://localhost:5005/api/v1/auth/oauth/github/callback"),
+		FacebookClientID:     getEnvOrDefault("FACEBOOK_CLIENT_ID", ""),
+		FacebookClientSecret: getEnvOrDefault("FACEBOOK_CLIENT_SECRET", ""),
+		FacebookRedirectURL:  getEnvOrDefault("FACEBOOK_REDIRECT_URL", "http://localhost:5005/api/v1/auth/oauth/facebook/callback"),
+		OIDCProviderName:     getEnvOrDefault("OIDC_PROVIDER_NAME", "oidc"),
+		OIDCIssuer:           getEnvOrDefault("OIDC_ISSUER", ""),
+		OIDCClientID:         getEnvOrDefault("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:     getEnvOrDefault("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:      getEnvOrDefault("OIDC_REDIRECT_URL", "http://localhost:5005/api/v1/auth/oauth/oidc/callback"),
+
 		StripeWebhookSecret:  getEnvOrDefault("STRIPE_WEBHOOK_SECRET", "your-stripe-webhook-secret"),
 		StripeCancelUrlDev:   getEnvOrDefault("STRIPE_CANCEL_URL_DEV", "http://localhost:5173/checkout/cancel"),
 		StripeSuccessUrlDev:  getEnvOrDefault("STRIPE_SUCCESS_URL_DEV", "http://localhost:5173/checkout/success"),
@@ -92,6 +153,32 @@ func LoadConfig() {
 		StripeSecretKey:      getEnvOrDefault("STRIPE_SECRET_KEY", "your-stripe-secret-key"),
 		StripePublishableKey: getEnvOrDefault("STRIPE_PUBLISHABLE_KEY", "your-stripe-publishable-key"),
 
+		// Withdrawal policy
+		WithdrawalMinAmount:        getEnvAsFloat("WITHDRAWAL_MIN_AMOUNT", 50000),
+		WithdrawalMaxAmount:        getEnvAsFloat("WITHDRAWAL_MAX_AMOUNT", 50000000),
+		WithdrawalFeeType:          getEnvOrDefault("WITHDRAWAL_FEE_TYPE", "flat"),
+		WithdrawalFeeValue:         getEnvAsFloat("WITHDRAWAL_FEE_VALUE", 2500),
+		WithdrawalDailyCap:         getEnvAsFloat("WITHDRAWAL_DAILY_CAP", 10000000),
+		WithdrawalMonthlyCap:       getEnvAsFloat("WITHDRAWAL_MONTHLY_CAP", 100000000),
+		WithdrawalRefundCoolingOff: getEnvAsDuration("WITHDRAWAL_REFUND_COOLING_OFF", "72h"),
+		WithdrawalKYCThreshold:     getEnvAsFloat("WITHDRAWAL_KYC_THRESHOLD", 5000000),
+
+		// Organizer settlements
+		PlatformFeePercent:       getEnvAsFloat("PLATFORM_FEE_PERCENT", 10),
+		OrganizerSettlementDelay: getEnvAsDuration("ORGANIZER_SETTLEMENT_DELAY", "24h"),
+
+		// Audit trail
+		AuditRetentionDays: getEnvAsInt("AUDIT_RETENTION_DAYS", 365),
+
+		// Report exports
+		ExportPDFMaxRows:         getEnvAsInt("EXPORT_PDF_MAX_ROWS", 5000),
+		ExportXLSXMaxRows:        getEnvAsInt("EXPORT_XLSX_MAX_ROWS", 100000),
+		ExportStorageDir:         getEnvOrDefault("EXPORT_STORAGE_DIR", "./storage/exports"),
+		ExportFileTTL:            getEnvAsDuration("EXPORT_FILE_TTL", "24h"),
+		ExportWorkerConcurrency:  getEnvAsInt("EXPORT_WORKER_CONCURRENCY", 2),
+		ExportWorkerPollInterval: getEnvAsDuration("EXPORT_WORKER_POLL_INTERVAL", "5s"),
+		ExportJobTimeout:         getEnvAsDuration("EXPORT_JOB_TIMEOUT", "30m"),
+
 		// Security
 		CookieDomain:        getEnvOrDefault("COOKIE_DOMAIN", "localhost"),
 		ApiKeys:             getEnvOrDefault("API_KEY", "your-api-keys"),
@@ -115,6 +202,12 @@ func LoadConfig() {
 		AccessTokenSecret:  getEnvOrDefault("ACCESS_TOKEN_SECRET", "your-secret-key"),
 		RefreshTokenSecret: getEnvOrDefault("REFRESH_TOKEN_SECRET", "your-refresh-token-secret"),
 
+		// Two-factor
+		TwoFactorEncryptionKey: getEnvOrDefault("TWO_FACTOR_ENCRYPTION_KEY", "your-two-factor-encryption-key"),
+
+		// Impersonation
+		ImpersonationTTL: getEnvAsDuration("IMPERSONATION_TTL", "15m"),
+
 		// mailer configuration
 		SMTPEmail:    getEnvOrDefault("SMTP_EMAIL", ""),
 		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
@@ -162,6 +255,15 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue string) time.Duration {
 	value := os.Getenv(key)
 	if value == "" {
@@ -1,22 +0,0 @@
-package config
-
-import (
-	"fmt"
-
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-)
-
-var GoogleOAuthConfig *oauth2.Config
-
-func InitGoogleOAuthConfig() {
-	GoogleOAuthConfig = &oauth2.Config{
-		ClientID:     AppConfig.GoogleClientID,
-		ClientSecret: AppConfig.GoogleClientSecret,
-		RedirectURL:  AppConfig.GoogleRedirectURL,
-		Scopes:       []string{"openid", "email", "profile"},
-		Endpoint:     google.Endpoint,
-	}
-
-	fmt.Println("✅ Google OAuth configured")
-}
@@ -6,6 +6,6 @@ func InitConfiguration() {
 	InitMailer()
 	InitDatabase()
 	InitCloudinary()
-	InitGoogleOAuthConfig()
+	InitOAuthProviders()
 	InitStripe()
 }
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"golang.org/x/oauth2/google"
+)
+
+// OAuthProvider is one entry of the sign-in provider registry,
+// Kind decides how the user's identity is read after the code exchange
+type OAuthProvider struct {
+	Name        string
+	Kind        string // google, github, facebook or oidc
+	Config      *oauth2.Config
+	UserInfoURL string
+
+	// checks the signature, issuer, audience and expiry of oidc ID tokens
+	Verifier *oidc.IDTokenVerifier
+}
+
+var OAuthProviders = map[string]*OAuthProvider{}
+
+func InitOAuthProviders() {
+	OAuthProviders = map[string]*OAuthProvider{}
+
+	OAuthProviders["google"] = &OAuthProvider{
+		Name: "google",
+		Kind: "google",
+		Config: &oauth2.Config{
+			ClientID:     AppConfig.GoogleClientID,
+			ClientSecret: AppConfig.GoogleClientSecret,
+			RedirectURL:  AppConfig.GoogleRedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+			Endpoint:     google.Endpoint,
+		},
+	}
+
+	if AppConfig.GithubClientID != "" {
+		OAuthProviders["github"] = &OAuthProvider{
+			Name: "github",
+			Kind: "github",
+			Config: &oauth2.Config{
+				ClientID:     AppConfig.GithubClientID,
+				ClientSecret: AppConfig.GithubClientSecret,
+				RedirectURL:  AppConfig.GithubRedirectURL,
+				Scopes:       []string{"read:user", "user:email"},
+				Endpoint:     endpoints.GitHub,
+			},
+			UserInfoURL: "https://api.github.com/user",
+		}
+	}
+
+	if AppConfig.FacebookClientID != "" {
+		OAuthProviders["facebook"] = &OAuthProvider{
+			Name: "facebook",
+			Kind: "facebook",
+			Config: &oauth2.Config{
+				ClientID:     AppConfig.FacebookClientID,
+				ClientSecret: AppConfig.FacebookClientSecret,
+				RedirectURL:  AppConfig.FacebookRedirectURL,
+				Scopes:       []string{"email", "public_profile"},
+				Endpoint:     endpoints.Facebook,
+			},
+			UserInfoURL: "https://graph.facebook.com/me?fields=id,name,email,picture",
+		}
+	}
+
+	if AppConfig.OIDCIssuer != "" && AppConfig.OIDCClientID != "" {
+		provider, err := discoverOIDCProvider()
+		if err != nil {
+			fmt.Println("⚠️ OIDC provider disabled:", err)
+		} else {
+			OAuthProviders[provider.Name] = provider
+		}
+	}
+
+	var names []string
+	for name := range OAuthProviders {
+		names = append(names, name)
+	}
+	fmt.Println("✅ OAuth providers configured:", strings.Join(names, ", "))
+}
+
+// discoverOIDCProvider reads the issuer's discovery document for the endpoints and the signing keys
+// that ID tokens are verified against
+func discoverOIDCProvider() (*OAuthProvider, error) {
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: 10 * time.Second})
+	discovered, err := oidc.NewProvider(ctx, AppConfig.OIDCIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		UserinfoEndpoint string `json:"userinfo_endpoint"`
+	}
+	if err := discovered.Claims(&doc); err != nil {
+		return nil, err
+	}
+	if doc.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("discovery document is missing the userinfo endpoint")
+	}
+
+	return &OAuthProvider{
+		Name: AppConfig.OIDCProviderName,
+		Kind: "oidc",
+		Config: &oauth2.Config{
+			ClientID:     AppConfig.OIDCClientID,
+			ClientSecret: AppConfig.OIDCClientSecret,
+			RedirectURL:  AppConfig.OIDCRedirectURL,
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+			Endpoint:     discovered.Endpoint(),
+		},
+		UserInfoURL: doc.UserinfoEndpoint,
+		Verifier:    discovered.Verifier(&oidc.Config{ClientID: AppConfig.OIDCClientID}),
+	}, nil
+}
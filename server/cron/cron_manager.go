@@ -9,16 +9,25 @@ import (
 )
 
 type CronManager struct {
-	c              *cron.Cron
-	paymentService services.PaymentService
+	c                *cron.Cron
+	paymentService   services.PaymentService
+	organizerService services.OrganizerService
+	auditService     services.AuditService
+	exportService    services.ExportService
 }
 
 func NewCronManager(
 	payment services.PaymentService,
+	organizer services.OrganizerService,
+	audit services.AuditService,
+	export services.ExportService,
 ) *CronManager {
 	return &CronManager{
-		c:              cron.New(cron.WithSeconds()),
-		paymentService: payment,
+		c:                cron.New(cron.WithSeconds()),
+		paymentService:   payment,
+		organizerService: organizer,
+		auditService:     audit,
+		exportService:    export,
 	}
 }
 
@@ -32,6 +41,33 @@ func (cm *CronManager) RegisterJobs() {
 			log.Println("Payment status updated (pending → failed)")
 		}
 	})
+
+	// Credit organizer earnings of finished events (check every hour)
+	cm.c.AddFunc("0 0 * * * *", func() {
+		log.Println("Cron: Settling organizer earnings...")
+		if err := cm.organizerService.SettleDueEarnings(); err != nil {
+			log.Println("Error settling organizer earnings:", err)
+		}
+	})
+
+	// Purge audit entries past the retention period (every day at 03:00)
+	cm.c.AddFunc("0 0 3 * * *", func() {
+		log.Println("Cron: Purging expired audit logs...")
+		if err := cm.auditService.PurgeExpiredLogs(); err != nil {
+			log.Println("Error purging audit logs:", err)
+		}
+	})
+
+	// Remove export files past their expiry and requeue lost jobs (every hour at half past)
+	cm.c.AddFunc("0 30 * * * *", func() {
+		log.Println("Cron: Purging expired exports...")
+		if err := cm.exportService.PurgeExpiredExports(); err != nil {
+			log.Println("Error purging exports:", err)
+		}
+		if err := cm.exportService.RequeueStaleJobs(); err != nil {
+			log.Println("Error requeueing stale export jobs:", err)
+		}
+	})
 }
 func (cm *CronManager) Start() {
 	cm.c.Start()
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"mime/multipart"
 	"time"
 )
@@ -20,10 +21,84 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyMagicLinkRequest takes either the token from the emailed link or the email and 6-digit code
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token" binding:"required_without=Code"`
+	Email string `json:"email" binding:"required_with=Code,omitempty,email"`
+	Code  string `json:"code" binding:"required_without=Token,omitempty,len=6,numeric"`
+}
+
 type AuthResponse struct {
 	User         ProfileResponse `json:"user"`
 	AccessToken  string          `json:"accessToken"`
 	RefreshToken string          `json:"refreshToken"`
+
+	// set instead of tokens when the account still has to pass the 2FA step
+	TwoFactor *TwoFactorChallengeResponse `json:"twoFactor,omitempty"`
+}
+
+// TokenResponse is sent instead of cookies to clients that asked for tokens in the body
+type TokenResponse struct {
+	TokenType    string          `json:"tokenType"`
+	AccessToken  string          `json:"accessToken"`
+	RefreshToken string          `json:"refreshToken"`
+	ExpiresIn    int             `json:"expiresIn"`
+	User         ProfileResponse `json:"user"`
+}
+
+type NativeTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int    `json:"expiresIn"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recoveryCode" binding:"required_without=Code,omitempty,len=11"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// OAuthCallbackResult carries exactly one outcome: tokens (or a 2FA challenge), a completed link,
+// or a link token when the email already belongs to an account
+type OAuthCallbackResult struct {
+	Provider  string
+	ReturnURL string
+	Auth      *AuthResponse
+	Linked    bool
+	LinkToken string
+}
+
+type LinkedIdentityResponse struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	LinkedAt    time.Time  `json:"linkedAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+type ConfirmIdentityLinkRequest struct {
+	LinkToken string `json:"linkToken" binding:"required"`
 }
 
 type ResendOTPRequest struct {
@@ -43,6 +118,30 @@ type ProfileResponse struct {
 	Role     string    `json:"role"`
 	Balance  float64   `json:"balance"`
 	JoinedAt time.Time `json:"joinedAt"`
+
+	TwoFactorEnabled bool `json:"twoFactorEnabled"`
+	HasPassword      bool `json:"hasPassword"`
+
+	// only set while an admin is acting as this user, the client shows it as a banner
+	Impersonation *ImpersonationBanner `json:"impersonation,omitempty"`
+}
+
+type ImpersonationBanner struct {
+	Active         bool      `json:"active"`
+	ImpersonatorID string    `json:"impersonatorId"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=255"`
+}
+
+type ImpersonationResponse struct {
+	TokenType   string          `json:"tokenType"`
+	AccessToken string          `json:"accessToken"`
+	ExpiresIn   int             `json:"expiresIn"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+	User        ProfileResponse `json:"user"`
 }
 
 type UpdateProfileRequest struct {
@@ -51,18 +150,33 @@ type UpdateProfileRequest struct {
 	AvatarURL string                `form:"avatarURL"`
 }
 
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	Location   string    `json:"location"`
+	Client     string    `json:"client"`
+	Current    bool      `json:"current"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
 type UserQueryParams struct {
-	Q     string `form:"search"`
-	Role  string `form:"role"`
-	Sort  string `form:"sort"`
-	Page  int    `form:"page,default=1"`
-	Limit int    `form:"limit,default=10"`
+	Q      string `form:"search"`
+	Role   string `form:"role"`
+	Status string `form:"status" binding:"omitempty,oneof=active suspended banned"`
+	Sort   string `form:"sort"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=10"`
 }
 
 type UserListResponse struct {
 	ID       string    `json:"id"`
 	Email    string    `json:"email"`
 	Role     string    `json:"role"`
+	Status   string    `json:"status"`
 	Fullname string    `json:"fullname"`
 	Avatar   string    `json:"avatar"`
 	JoinedAt time.Time `json:"joinedAt"`
@@ -77,11 +191,43 @@ type UserDetailResponse struct {
 	JoinedAt time.Time `json:"joinedAt"`
 }
 
+// admin view of an account, including what blocks it from signing in
+type AdminUserResponse struct {
+	ID                    string     `json:"id"`
+	Email                 string     `json:"email"`
+	Fullname              string     `json:"fullname"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	StatusReason          string     `json:"statusReason"`
+	StatusChangedAt       *time.Time `json:"statusChangedAt"`
+	PasswordResetRequired bool       `json:"passwordResetRequired"`
+	TwoFactorEnabled      bool       `json:"twoFactorEnabled"`
+	FailedLoginAttempts   int        `json:"failedLoginAttempts"`
+	LoginLocked           bool       `json:"loginLocked"`
+	JoinedAt              time.Time  `json:"joinedAt"`
+}
+
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active suspended banned"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
 type ChangePasswordRequest struct {
-	CurrentPassword string `json:"currentPassword" binding:"required,min=6"`
+	CurrentPassword string `json:"currentPassword" binding:"omitempty,min=6"` // empty when an OAuth-only account sets its first password
 	NewPassword     string `json:"newPassword" binding:"required,min=6"`
 	ConfirmPassword string `json:"confirmPassword" binding:"required,min=6"`
 }
+
+// ChangeEmailRequest asks for the current password unless the account signs in through a provider only
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"newEmail" binding:"required,email,max=100"`
+	CurrentPassword string `json:"currentPassword"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type ForgotPasswordRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
@@ -108,9 +254,12 @@ type EventQueryParams struct {
 	StartDate string `form:"startDate"`
 	EndDate   string `form:"endDate"`
 	Location  string `form:"location"`
+	Category  string `form:"category"`
 	Sort      string `form:"sort"`
 	Page      int    `form:"page" default:"1"`
 	Limit     int    `form:"limit" default:"10"`
+
+	OrganizerID string `form:"organizerId"`
 }
 
 type EventResponse struct {
@@ -119,12 +268,14 @@ type EventResponse struct {
 	Image       string    `json:"image"`
 	Description string    `json:"description"`
 	Location    string    `json:"location"`
+	Category    string    `json:"category"`
 	IsAvailable bool      `json:"isAvailable"`
 	StartPrice  float64   `json:"startPrice"`
 	StartTime   int       `json:"startTime"`
 	EndTime     int       `json:"endTime"`
 	Date        time.Time `json:"date"`
 	Status      string    `json:"status"`
+	OrganizerID string    `json:"organizerId,omitempty"`
 	CreatedAt   time.Time `json:"createdAt"`
 }
 
@@ -135,6 +286,7 @@ type EventDetailResponse struct {
 	StartPrice  float64   `json:"startPrice"`
 	Description string    `json:"description"`
 	Location    string    `json:"location"`
+	Category    string    `json:"category"`
 	Status      string    `json:"status"`
 	StartTime   int       `json:"startTime"`
 	Date        time.Time `json:"date"`
@@ -148,6 +300,7 @@ type UpdateEventRequest struct {
 	Title       string `form:"title" binding:"required,min=5,max=150"`
 	Description string `form:"description" binding:"required"`
 	Location    string `form:"location" binding:"required"`
+	Category    string `form:"category" binding:"omitempty,max=50"`
 	Date        string `form:"date" binding:"required"`
 	StartTime   int    `form:"startTime" binding:"required,min=0,max=23"`
 	EndTime     int    `form:"endTime" binding:"required,min=1,max=24"`
@@ -166,11 +319,16 @@ type CreateEventRequest struct {
 	Title       string                `form:"title" binding:"required,min=5,max=150"`
 	Description string                `form:"description" binding:"required"`
 	Location    string                `form:"location" binding:"required"`
+	Category    string                `form:"category" binding:"omitempty,max=50"`
 	Date        string                `form:"date" binding:"required"`
 	StartTime   int                   `form:"startTime" binding:"required,min=0,max=23"`
 	EndTime     int                   `form:"endTime" binding:"required,min=1,max=24"`
 	Image       *multipart.FileHeader `form:"image" binding:"required"`
 	ImageURL    string                `form:"-"`
+
+	// set by admins to create the event for an organizer, organizers always own the events they create
+	OrganizerID        string   `form:"organizerId" binding:"omitempty,uuid"`
+	PlatformFeePercent *float64 `form:"platformFeePercent" binding:"omitempty,min=0,max=100"`
 }
 
 type CreateTicketRequest struct {
@@ -218,6 +376,7 @@ type CreateOrderRequest struct {
 	Fullname     string               `json:"fullname" binding:"required,min=3,max=100"`
 	Email        string               `json:"email" binding:"required,email"`
 	Phone        string               `json:"phone" binding:"required,min=10,max=15"`
+	WalletAmount float64              `json:"walletAmount" binding:"omitempty,min=0"`
 }
 
 type OrderDetailRequest struct {
@@ -226,9 +385,13 @@ type OrderDetailRequest struct {
 }
 
 type CheckoutSessionResponse struct {
-	PaymentID string
-	SessionID string
-	URL       string
+	OrderID      string
+	PaymentID    string
+	SessionID    string
+	URL          string
+	Status       string
+	WalletAmount float64
+	AmountDue    float64
 }
 
 type OrderQueryParams struct {
@@ -240,17 +403,18 @@ type OrderQueryParams struct {
 }
 
 type OrderResponse struct {
-	ID         string    `json:"id"`
-	EventID    string    `json:"eventId"`
-	EventName  string    `json:"eventName"`
-	EventImage string    `json:"eventImage"`
-	Fullname   string    `json:"fullname"`
-	Email      string    `json:"email"`
-	Phone      string    `json:"phone"`
-	TotalPrice float64   `json:"totalPrice"`
-	PaymentURL string    `json:"paymentUrl"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID           string    `json:"id"`
+	EventID      string    `json:"eventId"`
+	EventName    string    `json:"eventName"`
+	EventImage   string    `json:"eventImage"`
+	Fullname     string    `json:"fullname"`
+	Email        string    `json:"email"`
+	Phone        string    `json:"phone"`
+	TotalPrice   float64   `json:"totalPrice"`
+	WalletAmount float64   `json:"walletAmount"`
+	PaymentURL   string    `json:"paymentUrl"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"createdAt"`
 }
 
 type OrderDetailResponse struct {
@@ -293,19 +457,137 @@ type RefundOrderResponse struct {
 // 7. WITHDRAWAL MODULE MANAGEMENT =============
 
 type CreateWithdrawalRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"`
-	Reason string  `json:"reason" binding:"required"`
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Reason          string  `json:"reason" binding:"required"`
+	PayoutAccountID string  `json:"payoutAccountId" binding:"required,uuid"`
+}
+
+type ReviewWithdrawalRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Note   string `json:"note" binding:"omitempty,max=255"`
+}
+
+type WithdrawalQueryParams struct {
+	Status string `form:"status"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=10"`
 }
 
 type WithdrawalResponse struct {
-	ID         string     `json:"id"`
-	UserID     string     `json:"userId"`
-	Amount     float64    `json:"amount"`
-	Status     string     `json:"status"`
-	Reason     string     `json:"reason"`
-	CreatedAt  time.Time  `json:"createdAt"`
-	ReviewedBy string     `json:"reviewedBy,omitempty"`
-	ApprovedAt *time.Time `json:"approvedAt,omitempty"`
+	ID            string                       `json:"id"`
+	UserID        string                       `json:"userId"`
+	Amount        float64                      `json:"amount"`
+	Fee           float64                      `json:"fee"`
+	NetAmount     float64                      `json:"netAmount"`
+	Status        string                       `json:"status"`
+	Reason        string                       `json:"reason"`
+	CreatedAt     time.Time                    `json:"createdAt"`
+	ReviewedBy    string                       `json:"reviewedBy,omitempty"`
+	ApprovedAt    *time.Time                   `json:"approvedAt,omitempty"`
+	PaidAt        *time.Time                   `json:"paidAt,omitempty"`
+	PayoutBatchID string                       `json:"payoutBatchId,omitempty"`
+	PayoutAccount *PayoutAccountResponse       `json:"payoutAccount,omitempty"`
+	Timeline      []WithdrawalTimelineResponse `json:"timeline,omitempty"`
+}
+
+type WithdrawalTimelineResponse struct {
+	Status    string    `json:"status"`
+	Note      string    `json:"note,omitempty"`
+	ActorID   string    `json:"actorId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreatePayoutAccountRequest struct {
+	BankCode      string `json:"bankCode" binding:"required,alphanum,max=20"`
+	AccountNumber string `json:"accountNumber" binding:"required,numeric,min=5,max=34"`
+	HolderName    string `json:"holderName" binding:"required,min=3,max=100"`
+}
+
+type VerifyPayoutAccountRequest struct {
+	Status string `json:"status" binding:"required,oneof=verified rejected"`
+}
+
+type PayoutAccountResponse struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"userId"`
+	BankCode      string     `json:"bankCode"`
+	AccountNumber string     `json:"accountNumber"`
+	HolderName    string     `json:"holderName"`
+	Status        string     `json:"status"`
+	VerifiedAt    *time.Time `json:"verifiedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type SubmitIdentityRequest struct {
+	FullName       string                `form:"fullName" binding:"required,min=3,max=100"`
+	DocumentType   string                `form:"documentType" binding:"required,oneof=ktp passport sim"`
+	DocumentNumber string                `form:"documentNumber" binding:"required,alphanum,min=5,max=50"`
+	Document       *multipart.FileHeader `form:"document" binding:"required"`
+	DocumentID     string                `form:"-"`
+}
+
+type ReviewIdentityRequest struct {
+	Status string `json:"status" binding:"required,oneof=verified rejected"`
+}
+
+type IdentityQueryParams struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending verified rejected"`
+}
+
+// IdentityResponse never carries the document itself, DocumentURL is only set for reviewers and points
+// to the permission-gated download endpoint
+type IdentityResponse struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"userId"`
+	FullName       string     `json:"fullName"`
+	DocumentType   string     `json:"documentType"`
+	DocumentNumber string     `json:"documentNumber"`
+	HasDocument    bool       `json:"hasDocument"`
+	DocumentURL    string     `json:"documentUrl,omitempty"`
+	Status         string     `json:"status"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type WithdrawalPolicyResponse struct {
+	MinAmount          float64 `json:"minAmount"`
+	MaxAmount          float64 `json:"maxAmount"`
+	FeeType            string  `json:"feeType"`
+	FeeValue           float64 `json:"feeValue"`
+	DailyCap           float64 `json:"dailyCap"`
+	MonthlyCap         float64 `json:"monthlyCap"`
+	CoolingOffHours    float64 `json:"coolingOffHours"`
+	KYCThreshold       float64 `json:"kycThreshold"`
+	WithdrawnToday     float64 `json:"withdrawnToday"`
+	WithdrawnThisMonth float64 `json:"withdrawnThisMonth"`
+	IdentityStatus     string  `json:"identityStatus"`
+}
+
+type CreatePayoutBatchRequest struct {
+	Format string `json:"format" binding:"required,oneof=csv fixed"`
+}
+
+type ReconcilePayoutBatchRequest struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+type PayoutBatchResponse struct {
+	ID           string               `json:"id"`
+	Format       string               `json:"format"`
+	Status       string               `json:"status"`
+	ItemCount    int                  `json:"itemCount"`
+	TotalAmount  float64              `json:"totalAmount"`
+	CreatedBy    string               `json:"createdBy"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	ReconciledAt *time.Time           `json:"reconciledAt,omitempty"`
+	Withdrawals  []WithdrawalResponse `json:"withdrawals,omitempty"`
+}
+
+type PayoutReconcileResponse struct {
+	BatchID string   `json:"batchId"`
+	Paid    int      `json:"paid"`
+	Failed  int      `json:"failed"`
+	Skipped []string `json:"skipped,omitempty"`
 }
 
 // 8. REPORT MODULE MANAGEMENT =============
@@ -317,7 +599,9 @@ type OrderReportQueryParams struct {
 	EventID  string `form:"eventId"`
 	DateFrom string `form:"dateFrom"`
 	DateTo   string `form:"dateTo"`
-	Export   string `form:"export" binding:"omitempty,oneof=csv pdf"`
+	Export   string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+
+	OrganizerID string `form:"organizerId"`
 }
 
 type OrderReportResponse struct {
@@ -325,8 +609,8 @@ type OrderReportResponse struct {
 	Fullname   string    `json:"fullname"`
 	Email      string    `json:"email"`
 	EventTitle string    `json:"eventTitle"`
-	TotalPrice float64   `json:"totalPrice"`
-	Status     string    `json:"status"`
+	TotalPrice float64   `json:"totalPrice" export:"money,total"`
+	Status     string    `json:"status" export:"status"`
 	CreatedAt  time.Time `json:"createdAt"`
 }
 
@@ -334,16 +618,18 @@ type TicketReportQueryParams struct {
 	Q      string `form:"search"`
 	Page   int    `form:"page,default=1"`
 	Limit  int    `form:"limit,default=10"`
-	Export string `form:"export" binding:"omitempty,oneof=csv pdf"`
+	Export string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+
+	OrganizerID string `form:"organizerId"`
 }
 
 type TicketSalesReportResponse struct {
 	EventTitle  string  `json:"eventTitle"`
 	TicketName  string  `json:"ticketName"`
-	TicketPrice float64 `json:"ticketPrice"`
-	Quota       int     `json:"quota"`
-	Sold        int     `json:"sold"`
-	Remaining   int     `json:"remaining"`
+	TicketPrice float64 `json:"ticketPrice" export:"money"`
+	Quota       int     `json:"quota" export:"total"`
+	Sold        int     `json:"sold" export:"total"`
+	Remaining   int     `json:"remaining" export:"total"`
 }
 
 // payment report response
@@ -353,7 +639,9 @@ type PaymentReportQueryParams struct {
 	Limit  int    `form:"limit,default=10"`
 	Status string `form:"status"`
 	Method string `form:"method"`
-	Export string `form:"export" binding:"omitempty,oneof=csv pdf"`
+	Export string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+
+	OrganizerID string `form:"organizerId"`
 }
 
 type PaymentReportResponse struct {
@@ -361,9 +649,9 @@ type PaymentReportResponse struct {
 	OrderID   string     `json:"orderId"`
 	Fullname  string     `json:"fullname"`
 	Email     string     `json:"email"`
-	Method    string     `json:"method"`
-	Amount    float64    `json:"amount"`
-	Status    string     `json:"status"`
+	Method    string     `json:"method" export:"status"`
+	Amount    float64    `json:"amount" export:"money,total"`
+	Status    string     `json:"status" export:"status"`
 	PaidAt    *time.Time `json:"paidAt,omitempty"`
 }
 
@@ -372,7 +660,9 @@ type RefundReportQueryParams struct {
 	Q      string `form:"search"`
 	Page   int    `form:"page,default=1"`
 	Limit  int    `form:"limit,default=10"`
-	Export string `form:"export" binding:"omitempty,oneof=csv pdf"`
+	Export string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+
+	OrganizerID string `form:"organizerId"`
 }
 
 type RefundReportResponse struct {
@@ -380,7 +670,7 @@ type RefundReportResponse struct {
 	Fullname     string     `json:"fullname"`
 	Email        string     `json:"email"`
 	EventTitle   string     `json:"eventTitle"`
-	RefundAmount float64    `json:"refundAmount"`
+	RefundAmount float64    `json:"refundAmount" export:"money,total"`
 	RefundReason string     `json:"refundReason"`
 	RefundedAt   *time.Time `json:"refundedAt,omitempty"`
 }
@@ -390,7 +680,7 @@ type WithdrawalReportQueryParams struct {
 	Q      string `form:"search"`
 	Page   int    `form:"page,default=1"`
 	Limit  int    `form:"limit,default=10"`
-	Export string `form:"export" binding:"omitempty,oneof=csv pdf"`
+	Export string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
 }
 
 type WithdrawalReportResponse struct {
@@ -398,13 +688,60 @@ type WithdrawalReportResponse struct {
 	UserID       string     `json:"userId"`
 	Fullname     string     `json:"fullname"`
 	Email        string     `json:"email"`
-	Amount       float64    `json:"amount"`
-	Status       string     `json:"status"`
+	Amount       float64    `json:"amount" export:"money,total"`
+	Fee          float64    `json:"fee" export:"money,total"`
+	Status       string     `json:"status" export:"status"`
 	Reason       string     `json:"reason"`
 	CreatedAt    time.Time  `json:"createdAt"`
 	ApprovedAt   *time.Time `json:"approvedAt,omitempty"`
 }
 
+type WithdrawalViolationQueryParams struct {
+	Q      string `form:"search"`
+	Rule   string `form:"rule"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=10"`
+	Export string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+}
+
+type WithdrawalViolationReportResponse struct {
+	ViolationID string    `json:"violationId"`
+	UserID      string    `json:"userId"`
+	Fullname    string    `json:"fullname"`
+	Email       string    `json:"email"`
+	Rule        string    `json:"rule" export:"status"`
+	Amount      float64   `json:"amount" export:"money,total"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// organizer earnings, one row per paid order of an organizer's event
+type OrganizerEarningQueryParams struct {
+	Q           string `form:"search"`
+	Status      string `form:"status" binding:"omitempty,oneof=pending settled"`
+	EventID     string `form:"eventId"`
+	OrganizerID string `form:"organizerId"`
+	Page        int    `form:"page,default=1"`
+	Limit       int    `form:"limit,default=10"`
+	Export      string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+}
+
+type OrganizerEarningResponse struct {
+	EarningID      string     `json:"earningId"`
+	OrganizerID    string     `json:"organizerId"`
+	OrganizerName  string     `json:"organizerName"`
+	EventTitle     string     `json:"eventTitle"`
+	OrderID        string     `json:"orderId"`
+	GrossAmount    float64    `json:"grossAmount" export:"money,total"`
+	RefundedAmount float64    `json:"refundedAmount" export:"money,total"`
+	FeePercent     float64    `json:"feePercent" export:"percent"`
+	PlatformFee    float64    `json:"platformFee" export:"money,total"`
+	NetAmount      float64    `json:"netAmount" export:"money,total"`
+	Status         string     `json:"status" export:"status"`
+	SettledAt      *time.Time `json:"settledAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
 // PAGINATION RESPONSE
 type PaginationResponse struct {
 	Page       int `json:"page"`
@@ -420,6 +757,7 @@ type SummaryReportResponse struct {
 	Orders      OrdersSummary      `json:"orders"`
 	Revenue     RevenueSummary     `json:"revenue"`
 	Withdrawals WithdrawalsSummary `json:"withdrawals"`
+	Organizers  OrganizersSummary  `json:"organizers"`
 }
 
 type UsersSummary struct {
@@ -455,12 +793,32 @@ type WithdrawalsSummary struct {
 	TotalAmount float64 `json:"totalAmount"`
 }
 
+type OrganizersSummary struct {
+	PlatformFees    float64 `json:"platformFees"`
+	PendingEarnings float64 `json:"pendingEarnings"`
+	SettledEarnings float64 `json:"settledEarnings"`
+}
+
+// OrganizerSummaryResponse is the dashboard of a single organizer, amounts only cover the organizer's events
+type OrganizerSummaryResponse struct {
+	OrganizerID     string  `json:"organizerId"`
+	Events          int     `json:"events"`
+	PaidOrders      int     `json:"paidOrders"`
+	GrossSales      float64 `json:"grossSales"`
+	RefundedAmount  float64 `json:"refundedAmount"`
+	PlatformFees    float64 `json:"platformFees"`
+	PendingEarnings float64 `json:"pendingEarnings"`
+	SettledEarnings float64 `json:"settledEarnings"`
+	Balance         float64 `json:"balance"`
+}
+
 type AdminEventResponse struct {
 	ID          string    `json:"id"`
 	Image       string    `json:"image"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Location    string    `json:"location"`
+	Category    string    `json:"category"`
 	StartPrice  float64   `json:"start_price"`
 	IsAvailable bool      `json:"is_available"`
 	StartTime   int       `json:"start_time"`
@@ -471,6 +829,8 @@ type AdminEventResponse struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 
 	// Admin-specific fields
+	OrganizerID          string           `json:"organizerId,omitempty"`
+	PlatformFeePercent   float64          `json:"platformFeePercent"`
 	TicketCount          int              `json:"ticketCount"`
 	TotalQuota           int              `json:"totalQuota"`
 	TotalSold            int              `json:"totalSold"`
@@ -479,3 +839,301 @@ type AdminEventResponse struct {
 	CompletionPercentage float64          `json:"completionPercentage"`
 	Tickets              []TicketResponse `json:"tickets,omitempty"`
 }
+
+// 10. ACCOUNT DATA MODULE MANAGEMENT =============
+// UserDataExport is the personal data archive, each field is written as its own JSON file
+type UserDataExport struct {
+	ExportedAt       time.Time                `json:"exportedAt"`
+	Profile          ProfileResponse          `json:"profile"`
+	Orders           []ExportOrderResponse    `json:"orders"`
+	Payments         []PaymentReportResponse  `json:"payments"`
+	Tickets          []UserTicketResponse     `json:"tickets"`
+	Withdrawals      []WithdrawalResponse     `json:"withdrawals"`
+	PayoutAccounts   []PayoutAccountResponse  `json:"payoutAccounts"`
+	Sessions         []SessionResponse        `json:"sessions"`
+	LinkedIdentities []LinkedIdentityResponse `json:"linkedIdentities"`
+	AuditLogs        []ExportAuditLogResponse `json:"auditLogs"`
+}
+
+type ExportOrderResponse struct {
+	OrderResponse
+	Items []OrderDetailResponse `json:"items"`
+}
+
+type ExportAuditLogResponse struct {
+	Action      string    `json:"action"`
+	Resource    string    `json:"resource"`
+	Description string    `json:"description"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"userAgent"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// DeleteAccountRequest asks for the current password unless the account signs in through a provider only
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	Confirmation    string `json:"confirmation" binding:"required,eq=DELETE"`
+}
+
+// 11. API CLIENT MODULE MANAGEMENT =============
+type CreateAPIClientRequest struct {
+	Name      string     `json:"name" binding:"required,min=3,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	RateLimit *int       `json:"rateLimit" binding:"omitempty,min=0,max=100000"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type APIClientResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rateLimit"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `json:"lastUsedIp"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// APIClientKeyResponse is the only response that carries the plain key, it cannot be shown again
+type APIClientKeyResponse struct {
+	Client APIClientResponse `json:"client"`
+	APIKey string            `json:"apiKey"`
+}
+
+// 12. ROLE & PERMISSION MODULE MANAGEMENT =============
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	Scopable    bool     `json:"scopable"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=super_admin finance event_manager checkin_staff organizer user"`
+}
+
+type CreateRoleAssignmentRequest struct {
+	Role    string `json:"role" binding:"required,oneof=event_manager checkin_staff"`
+	EventID string `json:"eventId" binding:"required,uuid"`
+}
+
+type RoleAssignmentResponse struct {
+	ID         string    `json:"id"`
+	Role       string    `json:"role"`
+	EventID    string    `json:"eventId"`
+	EventTitle string    `json:"eventTitle"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type UserRolesResponse struct {
+	UserID      string                   `json:"userId"`
+	Role        string                   `json:"role"`
+	Permissions []string                 `json:"permissions"`
+	Assignments []RoleAssignmentResponse `json:"assignments"`
+}
+
+// 13. AUDIT LOG MODULE MANAGEMENT =============
+type AuditLogQueryParams struct {
+	ActorID        string `form:"actorId"`
+	ImpersonatorID string `form:"impersonatorId"`
+	Resource       string `form:"resource"`
+	ResourceID     string `form:"resourceId"`
+	Action         string `form:"action"`
+	DateFrom       string `form:"dateFrom"`
+	DateTo         string `form:"dateTo"`
+	Page           int    `form:"page,default=1"`
+	Limit          int    `form:"limit,default=10"`
+	Export         string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+}
+
+type AuditLogResponse struct {
+	ID             string    `json:"id"`
+	ActorID        string    `json:"actorId"`
+	ActorEmail     string    `json:"actorEmail"`
+	ImpersonatorID string    `json:"impersonatorId"`
+	Action         string    `json:"action"`
+	Resource       string    `json:"resource"`
+	ResourceID     string    `json:"resourceId"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+	StatusCode     int       `json:"statusCode"`
+	Description    string    `json:"description"`
+	IP             string    `json:"ip"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type AuditLogDetailResponse struct {
+	AuditLogResponse
+	UserAgent string          `json:"userAgent"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Changes   json.RawMessage `json:"changes,omitempty"`
+}
+
+// 14. ANALYTICS MODULE MANAGEMENT =============
+type AnalyticsQueryParams struct {
+	Granularity string `form:"granularity,default=day" binding:"omitempty,oneof=day week month"`
+	DateFrom    string `form:"dateFrom"`
+	DateTo      string `form:"dateTo"`
+	Timezone    string `form:"timezone,default=UTC"`
+	EventID     string `form:"eventId" binding:"omitempty,uuid"`
+	Category    string `form:"category"`
+	Compare     bool   `form:"compare"`
+
+	OrganizerID string `form:"organizerId"`
+}
+
+// AnalyticsPoint holds the metrics of one bucket, Period is the local date the bucket starts on
+type AnalyticsPoint struct {
+	Period       string  `json:"period,omitempty"`
+	Revenue      float64 `json:"revenue"`
+	Orders       int     `json:"orders"`
+	TicketsSold  int     `json:"ticketsSold"`
+	Refunds      int     `json:"refunds"`
+	RefundAmount float64 `json:"refundAmount"`
+	NewUsers     int     `json:"newUsers"`
+}
+
+type AnalyticsPeriod struct {
+	DateFrom string           `json:"dateFrom"`
+	DateTo   string           `json:"dateTo"`
+	Series   []AnalyticsPoint `json:"series"`
+	Totals   AnalyticsPoint   `json:"totals"`
+}
+
+// AnalyticsChange is the percent change of each total against the previous period, nil when the
+// previous total was zero
+type AnalyticsChange struct {
+	Revenue      *float64 `json:"revenue"`
+	Orders       *float64 `json:"orders"`
+	TicketsSold  *float64 `json:"ticketsSold"`
+	Refunds      *float64 `json:"refunds"`
+	RefundAmount *float64 `json:"refundAmount"`
+	NewUsers     *float64 `json:"newUsers"`
+}
+
+type AnalyticsResponse struct {
+	Granularity string `json:"granularity"`
+	Timezone    string `json:"timezone"`
+	AnalyticsPeriod
+	Previous *AnalyticsPeriod `json:"previous,omitempty"`
+	Change   *AnalyticsChange `json:"change,omitempty"`
+}
+
+// 15. EVENT REPORT MODULE MANAGEMENT =============
+type EventReportQueryParams struct {
+	Timezone string `form:"timezone,default=UTC"`
+
+	OrganizerID string `form:"organizerId"`
+}
+
+type EventReportSummary struct {
+	GrossRevenue   float64 `json:"grossRevenue"`
+	RefundedAmount float64 `json:"refundedAmount"`
+	NetRevenue     float64 `json:"netRevenue"`
+	PaidOrders     int     `json:"paidOrders"`
+	RefundedOrders int     `json:"refundedOrders"`
+	TicketsSold    int     `json:"ticketsSold"`
+	Capacity       int     `json:"capacity"`
+	CheckedIn      int     `json:"checkedIn"`
+	NotCheckedIn   int     `json:"notCheckedIn"`
+	NoShows        int     `json:"noShows"`
+	CheckInRate    float64 `json:"checkInRate"`
+	EventEnded     bool    `json:"eventEnded"`
+}
+
+type TierRevenueResponse struct {
+	TicketID       string  `json:"ticketId"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	Quota          int     `json:"quota"`
+	Sold           int     `json:"sold"`
+	Revenue        float64 `json:"revenue"`
+	RefundedAmount float64 `json:"refundedAmount"`
+	CheckedIn      int     `json:"checkedIn"`
+}
+
+type EventOrdersPoint struct {
+	Date         string  `json:"date"`
+	Orders       int     `json:"orders"`
+	Revenue      float64 `json:"revenue"`
+	Refunds      int     `json:"refunds"`
+	RefundAmount float64 `json:"refundAmount"`
+}
+
+// EventCheckInPoint counts the check-ins of one local hour, Hour is formatted as "2006-01-02 15:00".
+// CheckInRate is the percentage of the tickets sold checked in by the end of that hour
+type EventCheckInPoint struct {
+	Hour        string  `json:"hour"`
+	CheckIns    int     `json:"checkIns"`
+	CheckedIn   int     `json:"checkedIn"`
+	CheckInRate float64 `json:"checkInRate"`
+}
+
+type EventReportResponse struct {
+	EventID        string                `json:"eventId"`
+	Title          string                `json:"title"`
+	Date           time.Time             `json:"date"`
+	Location       string                `json:"location"`
+	Category       string                `json:"category"`
+	Status         string                `json:"status"`
+	Timezone       string                `json:"timezone"`
+	Summary        EventReportSummary    `json:"summary"`
+	RevenueByTier  []TierRevenueResponse `json:"revenueByTier"`
+	OrdersOverTime []EventOrdersPoint    `json:"ordersOverTime"`
+	CheckInsByHour []EventCheckInPoint   `json:"checkInsByHour"`
+}
+
+type EventAttendeeQueryParams struct {
+	Q         string `form:"q"`
+	TicketID  string `form:"ticketId" binding:"omitempty,uuid"`
+	CheckedIn string `form:"checkedIn" binding:"omitempty,oneof=true false"`
+	Timezone  string `form:"timezone,default=UTC"`
+	Page      int    `form:"page,default=1"`
+	Limit     int    `form:"limit,default=10"`
+	Export    string `form:"export" binding:"omitempty,oneof=csv xlsx pdf"`
+
+	OrganizerID string `form:"organizerId"`
+}
+
+// AttendeeResponse is one row of the attendee manifest, CheckInTime is local to the requested timezone
+// and empty until the ticket is scanned
+type AttendeeResponse struct {
+	TicketID    string `json:"ticketId"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	Tier        string `json:"tier"`
+	CheckInTime string `json:"checkInTime"`
+}
+
+// 16. EXPORT JOB MODULE MANAGEMENT =============
+// CreateExportRequest queues a report export, Filters takes the same query parameters as the report
+// endpoint, e.g. {"status": "paid", "dateFrom": "2025-01-01"}. The attendee manifest needs an eventId
+type CreateExportRequest struct {
+	Report  string            `json:"report" binding:"required,oneof=orders ticket-sales payments refunds withdrawals withdrawal-violations organizer-earnings audit-logs event-attendees"`
+	Format  string            `json:"format" binding:"required,oneof=csv xlsx pdf"`
+	Filters map[string]string `json:"filters"`
+}
+
+type ExportJobResponse struct {
+	ID            string            `json:"id"`
+	Report        string            `json:"report"`
+	Format        string            `json:"format"`
+	Filters       map[string]string `json:"filters"`
+	Status        string            `json:"status"`
+	Progress      int               `json:"progress"`
+	TotalRows     int               `json:"totalRows"`
+	ProcessedRows int               `json:"processedRows"`
+	FileName      string            `json:"fileName,omitempty"`
+	FileSize      int64             `json:"fileSize,omitempty"`
+	DownloadURL   string            `json:"downloadUrl,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	StartedAt     *time.Time        `json:"startedAt,omitempty"`
+	CompletedAt   *time.Time        `json:"completedAt,omitempty"`
+	ExpiresAt     *time.Time        `json:"expiresAt,omitempty"`
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service services.AccountService
+}
+
+func NewAccountHandler(service services.AccountService) *AccountHandler {
+	return &AccountHandler{service}
+}
+
+func (h *AccountHandler) ExportMyData(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	filename, content, err := h.service.ExportUserData(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "export", "personal_data", filename)
+
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", content)
+}
+
+func (h *AccountHandler) DeleteMyAccount(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.DeleteAccountRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.DeleteAccount(userID, &req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "delete", "account", userID)
+
+	// the session is already revoked, drop the cookies as well
+	utils.ClearAccessTokenCookie(c)
+	utils.ClearRefreshTokenCookie(c)
+
+	response.OK(c, "Account deleted successfully", nil)
+}
@@ -92,6 +92,20 @@ func (h *AdminHandler) GetOrderReports(c *gin.Context) {
 		return
 	}
 
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetOrderReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "orders_reports", count, func(write func(dto.OrderReportResponse) error) error {
+			return h.service.StreamOrderReports(params, write)
+		})
+		return
+	}
+
 	// fetch order reports
 	lists, total, err := h.service.GetOrderReports(params)
 	if err != nil {
@@ -99,18 +113,6 @@ func (h *AdminHandler) GetOrderReports(c *gin.Context) {
 		return
 	}
 
-	// export as CSV
-	if params.Export == "csv" {
-		utils.ExportCSV(c, "orders_reports.csv", lists)
-		return
-	}
-
-	// export as PDF
-	if params.Export == "pdf" {
-		utils.ExportPDF(c, "orders_reports.pdf", lists)
-		return
-	}
-
 	// build pagination meta
 	paginate := pagination.Build(params.Page, params.Limit, total)
 	response.OKWithPagination(c, "Orders retrieved successfully", lists, paginate)
@@ -129,6 +131,20 @@ func (h *AdminHandler) GetTicketSalesReports(c *gin.Context) {
 		return
 	}
 
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetTicketSalesReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "tickets_reports", count, func(write func(dto.TicketSalesReportResponse) error) error {
+			return h.service.StreamTicketSalesReports(params, write)
+		})
+		return
+	}
+
 	// fetch ticket reports
 	lists, total, err := h.service.GetTicketSalesReports(params)
 	if err != nil {
@@ -136,18 +152,6 @@ func (h *AdminHandler) GetTicketSalesReports(c *gin.Context) {
 		return
 	}
 
-	// export as CSV
-	if params.Export == "csv" {
-		utils.ExportCSV(c, "tickets_reports.csv", lists)
-		return
-	}
-
-	// export as PDF
-	if params.Export == "pdf" {
-		utils.ExportPDF(c, "tickets_reports.pdf", lists)
-		return
-	}
-
 	// build pagination meta
 	paginate := pagination.Build(params.Page, params.Limit, total)
 	response.OKWithPagination(c, "Ticket sales reports retrieved successfully", lists, paginate)
@@ -166,6 +170,20 @@ func (h *AdminHandler) GetPaymentReports(c *gin.Context) {
 		return
 	}
 
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetPaymentReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "payments_reports", count, func(write func(dto.PaymentReportResponse) error) error {
+			return h.service.StreamPaymentReports(params, write)
+		})
+		return
+	}
+
 	// fetch payment reports
 	lists, total, err := h.service.GetPaymentReports(params)
 	if err != nil {
@@ -173,26 +191,53 @@ func (h *AdminHandler) GetPaymentReports(c *gin.Context) {
 		return
 	}
 
-	// export as CSV
-	if params.Export == "csv" {
-		utils.ExportCSV(c, "payments_reports.csv", lists)
+	// build pagination meta
+	paginate := pagination.Build(params.Page, params.Limit, total)
+	response.OKWithPagination(c, "Payment reports retrieved successfully", lists, paginate)
+}
+
+func (h *AdminHandler) GetRefundReports(c *gin.Context) {
+	// bind query params
+	var params dto.RefundReportQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
 		return
 	}
 
-	// export as PDF
-	if params.Export == "pdf" {
-		utils.ExportPDF(c, "payments_reports.pdf", lists)
+	// apply pagination defaults
+	if err := pagination.BindAndSetDefaults(c, &params); err != nil {
+		response.Error(c, response.BadRequest(err.Error()))
+		return
+	}
+
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetRefundReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "refund_reports", count, func(write func(dto.RefundReportResponse) error) error {
+			return h.service.StreamRefundReports(params, write)
+		})
+		return
+	}
+
+	// fetch refund reports
+	lists, total, err := h.service.GetRefundReports(params)
+	if err != nil {
+		response.Error(c, err)
 		return
 	}
 
 	// build pagination meta
 	paginate := pagination.Build(params.Page, params.Limit, total)
-	response.OKWithPagination(c, "Payment reports retrieved successfully", lists, paginate)
+	response.OKWithPagination(c, "Refund reports retrieved successfully", lists, paginate)
 }
 
-func (h *AdminHandler) GetRefundReports(c *gin.Context) {
+func (h *AdminHandler) GetWithdrawalReports(c *gin.Context) {
 	// bind query params
-	var params dto.RefundReportQueryParams
+	var params dto.WithdrawalReportQueryParams
 	if !utils.BindAndValidateForm(c, &params) {
 		return
 	}
@@ -203,33 +248,74 @@ func (h *AdminHandler) GetRefundReports(c *gin.Context) {
 		return
 	}
 
-	// fetch refund reports
-	lists, total, err := h.service.GetRefundReports(params)
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetWithdrawalReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "withdrawal_reports", count, func(write func(dto.WithdrawalReportResponse) error) error {
+			return h.service.StreamWithdrawalReports(params, write)
+		})
+		return
+	}
+
+	// fetch withdrawal reports
+	lists, total, err := h.service.GetWithdrawalReports(params)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	// export as CSV
-	if params.Export == "csv" {
-		utils.ExportCSV(c, "refund_reports.csv", lists)
+	// build pagination meta
+	paginate := pagination.Build(params.Page, params.Limit, total)
+	response.OKWithPagination(c, "withdrawal reports retrieved successfully", lists, paginate)
+}
+
+func (h *AdminHandler) GetWithdrawalViolationReports(c *gin.Context) {
+	// bind query params
+	var params dto.WithdrawalViolationQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
+		return
+	}
+
+	// apply pagination defaults
+	if err := pagination.BindAndSetDefaults(c, &params); err != nil {
+		response.Error(c, response.BadRequest(err.Error()))
+		return
+	}
+
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetWithdrawalViolationReports(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "withdrawal_violation_reports", count, func(write func(dto.WithdrawalViolationReportResponse) error) error {
+			return h.service.StreamWithdrawalViolationReports(params, write)
+		})
 		return
 	}
 
-	// export as PDF
-	if params.Export == "pdf" {
-		utils.ExportPDF(c, "refund_reports.pdf", lists)
+	// fetch withdrawal policy violations
+	lists, total, err := h.service.GetWithdrawalViolationReports(params)
+	if err != nil {
+		response.Error(c, err)
 		return
 	}
 
 	// build pagination meta
 	paginate := pagination.Build(params.Page, params.Limit, total)
-	response.OKWithPagination(c, "Refund reports retrieved successfully", lists, paginate)
+	response.OKWithPagination(c, "withdrawal violation reports retrieved successfully", lists, paginate)
 }
 
-func (h *AdminHandler) GetWithdrawalReports(c *gin.Context) {
+func (h *AdminHandler) GetOrganizerEarnings(c *gin.Context) {
 	// bind query params
-	var params dto.WithdrawalReportQueryParams
+	var params dto.OrganizerEarningQueryParams
 	if !utils.BindAndValidateForm(c, &params) {
 		return
 	}
@@ -240,26 +326,116 @@ func (h *AdminHandler) GetWithdrawalReports(c *gin.Context) {
 		return
 	}
 
-	// fetch withdrawal reports
-	lists, total, err := h.service.GetWithdrawalReports(params)
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetOrganizerEarnings(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "organizer_earnings", count, func(write func(dto.OrganizerEarningResponse) error) error {
+			return h.service.StreamOrganizerEarnings(params, write)
+		})
+		return
+	}
+
+	// fetch organizer earnings
+	lists, total, err := h.service.GetOrganizerEarnings(params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// build pagination meta
+	paginate := pagination.Build(params.Page, params.Limit, total)
+	response.OKWithPagination(c, "Organizer earnings retrieved successfully", lists, paginate)
+}
+
+func (h *AdminHandler) GetOrganizerSummary(c *gin.Context) {
+	// /admin/organizers/:id/summary names the organizer, /organizer/summary gets the caller's filter
+	organizerID := c.Param("id")
+	if organizerID == "" {
+		organizerID = c.Query("organizerId")
+	}
+
+	// fetch summary data
+	data, err := h.service.GetOrganizerSummary(organizerID)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	// export as CSV
-	if params.Export == "csv" {
-		utils.ExportCSV(c, "withdrawal_reports.csv", lists)
+	response.OK(c, "Organizer summary retrieved successfully", data)
+}
+
+func (h *AdminHandler) GetSalesAnalytics(c *gin.Context) {
+	// bind query params
+	var params dto.AnalyticsQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
 		return
 	}
 
-	// export as PDF
-	if params.Export == "pdf" {
-		utils.ExportPDF(c, "withdrawal_reports.pdf", lists)
+	analytics, err := h.service.GetSalesAnalytics(params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sales analytics retrieved successfully", analytics)
+}
+
+func (h *AdminHandler) GetEventReport(c *gin.Context) {
+	// bind query params
+	var params dto.EventReportQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
+		return
+	}
+
+	report, err := h.service.GetEventReport(c.Param("id"), params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Event report retrieved successfully", report)
+}
+
+func (h *AdminHandler) GetEventAttendees(c *gin.Context) {
+	// bind query params
+	var params dto.EventAttendeeQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
+		return
+	}
+
+	// apply pagination defaults
+	if err := pagination.BindAndSetDefaults(c, &params); err != nil {
+		response.Error(c, response.BadRequest(err.Error()))
+		return
+	}
+
+	// exports stream the whole manifest instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetEventAttendees(c.Param("id"), p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "attendees", count, func(write func(dto.AttendeeResponse) error) error {
+			return h.service.StreamEventAttendees(c.Param("id"), params, write)
+		})
+		return
+	}
+
+	// fetch the manifest
+	lists, total, err := h.service.GetEventAttendees(c.Param("id"), params)
+	if err != nil {
+		response.Error(c, err)
 		return
 	}
 
 	// build pagination meta
 	paginate := pagination.Build(params.Page, params.Limit, total)
-	response.OKWithPagination(c, "withdrawal reports retrieved successfully", lists, paginate)
+	response.OKWithPagination(c, "Attendees retrieved successfully", lists, paginate)
 }
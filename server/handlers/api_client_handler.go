@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type APIClientHandler struct {
+	service services.APIClientService
+}
+
+func NewAPIClientHandler(service services.APIClientService) *APIClientHandler {
+	return &APIClientHandler{service}
+}
+
+func (h *APIClientHandler) GetScopes(c *gin.Context) {
+	response.OK(c, "API scopes retrieved successfully", h.service.GetScopes())
+}
+
+func (h *APIClientHandler) GetClients(c *gin.Context) {
+	clients, err := h.service.GetClients(c.Query("includeRevoked") == "true")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "API clients retrieved successfully", clients)
+}
+
+func (h *APIClientHandler) GetClientByID(c *gin.Context) {
+	client, err := h.service.GetClientByID(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "API client retrieved successfully", client)
+}
+
+func (h *APIClientHandler) IssueClient(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+
+	var req dto.CreateAPIClientRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.IssueClient(adminID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "issue", "api_client", result.Client)
+
+	response.Created(c, "API client created, store the key now as it won't be shown again", result)
+}
+
+func (h *APIClientHandler) RotateClientKey(c *gin.Context) {
+	result, err := h.service.RotateClientKey(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "rotate", "api_client", result.Client)
+
+	response.OK(c, "API key rotated, store the key now as it won't be shown again", result)
+}
+
+func (h *APIClientHandler) RevokeClient(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.service.RevokeClient(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "revoke", "api_client", id)
+
+	response.OK(c, "API client revoked successfully", nil)
+}
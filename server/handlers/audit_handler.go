@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/pagination"
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{service}
+}
+
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	// bind query params
+	var params dto.AuditLogQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
+		return
+	}
+
+	// apply pagination defaults
+	if err := pagination.BindAndSetDefaults(c, &params); err != nil {
+		response.Error(c, response.BadRequest(err.Error()))
+		return
+	}
+
+	// exports stream every matching row instead of the requested page
+	if params.Export != "" {
+		count := func() (int, error) {
+			p := params
+			p.Page, p.Limit = 1, 1
+			_, total, err := h.service.GetAuditLogs(p)
+			return total, err
+		}
+		utils.StreamExport(c, params.Export, "audit_logs", count, func(write func(dto.AuditLogResponse) error) error {
+			return h.service.StreamAuditLogs(params, write)
+		})
+		return
+	}
+
+	// fetch audit logs
+	lists, total, err := h.service.GetAuditLogs(params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// build pagination meta
+	paginate := pagination.Build(params.Page, params.Limit, total)
+	response.OKWithPagination(c, "Audit logs retrieved successfully", lists, paginate)
+}
+
+func (h *AuditHandler) GetAuditLogByID(c *gin.Context) {
+	entry, err := h.service.GetAuditLogByID(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Audit log retrieved successfully", entry)
+}
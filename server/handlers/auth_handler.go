@@ -1,9 +1,10 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"net/url"
 
-	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
@@ -58,16 +59,12 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	}
 
 	// verify OTP code
-	resp, err := h.service.VerifyOTP(req.Email, req.OTP)
+	resp, err := h.service.VerifyOTP(req.Email, req.OTP, utils.GetClientInfo(c))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
-	// set cookies as httpOnly
-	utils.SetAccessTokenCookie(c, resp.AccessToken)
-	utils.SetRefreshTokenCookie(c, resp.RefreshToken)
-
-	response.OK(c, "OTP verified successfully", resp.User)
+	respondWithTokens(c, "OTP verified successfully", resp)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -78,45 +75,170 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// authenticate user credentials
-	result, err := h.service.Login(&req)
+	result, err := h.service.Login(&req, utils.GetClientInfo(c))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	// set cookies as httpOnly
-	utils.SetAccessTokenCookie(c, result.AccessToken)
-	utils.SetRefreshTokenCookie(c, result.RefreshToken)
+	// account with 2FA, tokens come from the verify step
+	if result.TwoFactor != nil {
+		response.OK(c, "Two-factor verification required", result.TwoFactor)
+		return
+	}
+
+	respondWithTokens(c, "Login successfully", result)
+}
+
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req dto.MagicLinkRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.RequestMagicLink(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// same answer whether or not the email is registered
+	response.OK(c, "If the email is registered, a sign-in link has been sent", nil)
+}
 
-	response.OK(c, "Login successfully", result.User)
+func (h *AuthHandler) VerifyMagicLink(c *gin.Context) {
+	var req dto.VerifyMagicLinkRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.VerifyMagicLink(&req, utils.GetClientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if result.TwoFactor != nil {
+		response.OK(c, "Two-factor verification required", result.TwoFactor)
+		return
+	}
+
+	respondWithTokens(c, "Login successfully", result)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
+	refreshToken, _ := c.Cookie("refreshToken")
+
+	// session of the access token, used when the refresh cookie is gone
+	var sessionID string
+	if accessToken := utils.GetAccessToken(c); accessToken != "" {
+		if claims, err := utils.DecodeAccessToken(accessToken); err == nil {
+			sessionID = claims.SessionID
+		}
+	}
+
+	// revoke current session
+	if err := h.service.Logout(refreshToken, sessionID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
 	// clear cookies
 	utils.ClearAccessTokenCookie(c)
 	utils.ClearRefreshTokenCookie(c)
 	response.OK(c, "Logout successfully", nil)
 }
 
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	// revoke every session of the user
+	if err := h.service.LogoutAll(userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// clear cookies
+	utils.ClearAccessTokenCookie(c)
+	utils.ClearRefreshTokenCookie(c)
+	response.OK(c, "Logged out from all devices successfully", nil)
+}
+
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// get refresh token
 	refreshToken, err := c.Cookie("refreshToken")
+	if err != nil || refreshToken == "" {
+		response.Error(c, response.NewUnauthorized("Refresh token missing"))
+		return
+	}
+
+	// rotate refresh token
+	result, err := h.service.RefreshToken(refreshToken, utils.GetClientInfo(c))
 	if err != nil {
+		utils.ClearAccessTokenCookie(c)
+		utils.ClearRefreshTokenCookie(c)
 		response.Error(c, err)
 		return
 	}
 
-	// generate new token
-	user, token, err := h.service.RefreshToken(c, refreshToken)
+	respondWithTokens(c, "Token refreshed successfully", result)
+}
+
+// NativeRefreshToken is the refresh flow for mobile apps and devices, the refresh token travels
+// in the body both ways and only sessions started by a native client are accepted
+func (h *AuthHandler) NativeRefreshToken(c *gin.Context) {
+	var req dto.NativeTokenRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	client := utils.GetClientInfo(c)
+	client.Native = true
+
+	result, err := h.service.RefreshToken(req.RefreshToken, client)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	// set access cookie
-	utils.SetAccessTokenCookie(c, token)
+	response.OK(c, "Token refreshed successfully", toTokenResponse(result))
+}
+
+// NativeRevokeToken signs a native session out with its refresh token
+func (h *AuthHandler) NativeRevokeToken(c *gin.Context) {
+	var req dto.NativeTokenRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.Logout(req.RefreshToken, ""); err != nil {
+		response.Error(c, err)
+		return
+	}
 
-	response.OK(c, "Token refreshed successfully", user)
+	response.OK(c, "Logout successfully", nil)
+}
+
+// respondWithTokens sets the httpOnly cookies for browsers, clients that asked for body delivery get the tokens instead
+func respondWithTokens(c *gin.Context, message string, result *dto.AuthResponse) {
+	if utils.TokensInBody(c) {
+		response.OK(c, message, toTokenResponse(result))
+		return
+	}
+
+	utils.SetAccessTokenCookie(c, result.AccessToken)
+	utils.SetRefreshTokenCookie(c, result.RefreshToken)
+
+	response.OK(c, message, result.User)
+}
+
+func toTokenResponse(result *dto.AuthResponse) dto.TokenResponse {
+	return dto.TokenResponse{
+		TokenType:    "Bearer",
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+		User:         result.User,
+	}
 }
 
 // step 1 : User requests password reset
@@ -164,27 +286,344 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	response.OK(c, "Password has been reset successfully", nil)
 }
 
-func (h *AuthHandler) GoogleOAuthRedirect(c *gin.Context) {
-	url := h.service.GetGoogleOAuthURL()
-	c.Redirect(http.StatusTemporaryRedirect, url)
+func (h *AuthHandler) GetOAuthProviders(c *gin.Context) {
+	response.OK(c, "OAuth providers retrieved successfully", h.service.GetOAuthProviders())
+}
+
+// OAuthRedirect starts the login, /auth/google keeps working without a provider param
+func (h *AuthHandler) OAuthRedirect(c *gin.Context) {
+	authURL, state, err := h.service.GetOAuthURL(oauthProvider(c), c.Query("returnTo"), "")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// bind state to this browser
+	utils.SetOAuthStateCookie(c, state)
+
+	c.Redirect(http.StatusTemporaryRedirect, authURL)
 }
 
-func (h *AuthHandler) GoogleOAuthCallback(c *gin.Context) {
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	// state must match the cookie set when this browser started the login
+	state := c.Query("state")
+	cookieState, _ := c.Cookie("oauthState")
+	utils.ClearOAuthStateCookie(c)
+
+	if state == "" || cookieState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		response.Error(c, response.NewUnauthorized("Invalid OAuth state"))
+		return
+	}
+
 	code := c.Query("code")
 	if code == "" {
 		response.Error(c, response.NewBadRequest("Authorization code is missing"))
 		return
 	}
 
-	tokens, err := h.service.HandleGoogleOAuthCallback(code)
+	result, err := h.service.HandleOAuthCallback(oauthProvider(c), code, state, utils.GetClientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	switch {
+	case result.Linked:
+		c.Redirect(http.StatusTemporaryRedirect, withQuery(result.ReturnURL, "linked", result.Provider))
+
+	case result.LinkToken != "":
+		// the email belongs to an existing account, the owner has to sign in and confirm the link
+		redirectURL := withQuery(result.ReturnURL, "linkToken", result.LinkToken)
+		c.Redirect(http.StatusTemporaryRedirect, withQuery(redirectURL, "provider", result.Provider))
+
+	case result.Auth.TwoFactor != nil:
+		// let the frontend finish the 2FA step with the challenge token
+		c.Redirect(http.StatusTemporaryRedirect, withQuery(result.ReturnURL, "twoFactorChallenge", result.Auth.TwoFactor.ChallengeToken))
+
+	default:
+		utils.SetAccessTokenCookie(c, result.Auth.AccessToken)
+
+		utils.SetRefreshTokenCookie(c, result.Auth.RefreshToken)
+
+		c.Redirect(http.StatusTemporaryRedirect, result.ReturnURL)
+	}
+}
+
+func oauthProvider(c *gin.Context) string {
+	if provider := c.Param("provider"); provider != "" {
+		return provider
+	}
+	return "google"
+}
+
+func (h *AuthHandler) GetLinkedIdentities(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	identities, err := h.service.GetLinkedIdentities(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Linked identities retrieved successfully", identities)
+}
+
+// LinkIdentity returns the provider URL the frontend sends the signed-in user to
+func (h *AuthHandler) LinkIdentity(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	authURL, state, err := h.service.GetOAuthURL(c.Param("provider"), c.Query("returnTo"), userID)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	utils.SetAccessTokenCookie(c, tokens.AccessToken)
+	utils.SetOAuthStateCookie(c, state)
+
+	response.OK(c, "Continue linking with the provider", gin.H{"url": authURL})
+}
+
+func (h *AuthHandler) ConfirmIdentityLink(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.ConfirmIdentityLinkRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ConfirmIdentityLink(userID, req.LinkToken); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "link", "identity", nil)
+
+	response.OK(c, "Account linked successfully", nil)
+}
+
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+	provider := c.Param("provider")
+
+	if err := h.service.UnlinkIdentity(userID, provider); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "unlink", "identity", provider)
+
+	response.OK(c, "Account unlinked successfully", nil)
+}
+
+func withQuery(rawURL, key, value string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := parsed.Query()
+	query.Set(key, value)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req dto.VerifyTwoFactorRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	// verify second factor and start the session
+	result, err := h.service.VerifyTwoFactor(&req, utils.GetClientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	respondWithTokens(c, "Login successfully", result)
+}
+
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	result, err := h.service.SetupTwoFactor(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Scan the QR code with your authenticator app", result)
+}
+
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.EnableTwoFactor(userID, utils.GetSessionID(c), req.Code)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "enable", "two_factor", userID)
+
+	response.OK(c, "Two-factor authentication enabled, store your recovery codes safely", result)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(userID, req.Code); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "disable", "two_factor", userID)
+
+	response.OK(c, "Two-factor authentication disabled", nil)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "regenerate", "recovery_codes", userID)
+
+	response.OK(c, "Recovery codes regenerated successfully", result)
+}
+
+func (h *AuthHandler) ResetTwoFactor(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	userID := c.Param("id")
+
+	if err := h.service.ResetTwoFactor(adminID, userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "reset", "two_factor", userID)
+
+	response.OK(c, "Two-factor authentication reset successfully", userID)
+}
+
+func (h *AuthHandler) StartImpersonation(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+
+	var req dto.ImpersonateRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.StartImpersonation(adminID, c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "impersonate", "user", gin.H{"reason": req.Reason, "expiresAt": result.ExpiresAt})
+
+	// always in the body, cookies would replace the admin's own session in the browser
+	response.OK(c, "Impersonation started", result)
+}
+
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	if utils.GetImpersonatorID(c) == "" {
+		response.Error(c, response.NewBadRequest("The current token is not an impersonation token"))
+		return
+	}
+
+	h.service.StopImpersonation(utils.GetSessionID(c))
+
+	utils.AuditAction(c, "stop_impersonation", "user", nil)
+
+	response.OK(c, "Impersonation ended", nil)
+}
+
+func (h *AuthHandler) GetUserAccount(c *gin.Context) {
+	account, err := h.service.GetUserAccount(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "User retrieved successfully", account)
+}
+
+func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	userID := c.Param("id")
+
+	var req dto.UpdateUserStatusRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if before, err := h.service.GetUserAccount(userID); err == nil {
+		utils.AuditBefore(c, before)
+	}
+
+	account, err := h.service.UpdateUserStatus(adminID, userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "update_status", "user", account)
+
+	response.OK(c, "User status updated successfully", account)
+}
+
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	if before, err := h.service.GetUserAccount(userID); err == nil {
+		utils.AuditBefore(c, before)
+	}
+
+	account, err := h.service.UnlockUser(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "unlock", "user", account)
+
+	response.OK(c, "User unlocked successfully", account)
+}
+
+func (h *AuthHandler) ForcePasswordReset(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	userID := c.Param("id")
+
+	if before, err := h.service.GetUserAccount(userID); err == nil {
+		utils.AuditBefore(c, before)
+	}
+
+	account, err := h.service.ForcePasswordReset(adminID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
 
-	utils.SetRefreshTokenCookie(c, tokens.RefreshToken)
+	utils.AuditAction(c, "force_password_reset", "user", account)
 
-	c.Redirect(http.StatusTemporaryRedirect, config.AppConfig.FrontendRedirectURL)
+	response.OK(c, "Password reset link sent to the user", account)
 }
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
@@ -14,12 +13,11 @@ import (
 )
 
 type EventHandler struct {
-	service    services.EventService
-	repository repositories.AuditLogRepository
+	service services.EventService
 }
 
-func NewEventHandler(service services.EventService, repository repositories.AuditLogRepository) *EventHandler {
-	return &EventHandler{service, repository}
+func NewEventHandler(service services.EventService) *EventHandler {
+	return &EventHandler{service}
 }
 
 func (h *EventHandler) GetAllEvents(c *gin.Context) {
@@ -67,6 +65,12 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
+	// organizers own what they create and cannot pick their own fee
+	if utils.MustGetRole(c) == utils.RoleOrganizer {
+		req.OrganizerID = utils.MustGetUserID(c)
+		req.PlatformFeePercent = nil
+	}
+
 	imageURL, err := utils.UploadImageWithValidation(req.Image)
 	if err != nil {
 		response.Error(c, err)
@@ -84,21 +88,31 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "create", "event", req)
-
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "create", "event", req)
 
 	response.Created(c, "Event and tickets created successfully", createdEvent)
 }
 
 func (h *EventHandler) UpdateEventByID(c *gin.Context) {
 	eventID := c.Param("id")
+
+	if !utils.CanAccessEvent(c, eventID) {
+		response.Error(c, response.NewForbidden("You are not allowed to manage this event"))
+		return
+	}
 	var req dto.UpdateEventRequest
 
 	if !utils.BindAndValidateForm(c, &req) {
 		return
 	}
 
+	before, err := h.service.GetEventByID(eventID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	utils.AuditBefore(c, before)
+
 	// upload new image
 	if req.Image != nil && req.Image.Filename != "" {
 		imageURL, err := utils.UploadImageWithValidation(req.Image)
@@ -120,9 +134,7 @@ func (h *EventHandler) UpdateEventByID(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "update", "event", req)
-
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "update", "event", updatedEvent)
 
 	response.OK(c, "Event updated successfully", updatedEvent)
 }
@@ -144,6 +156,18 @@ func (h *EventHandler) GetTicketsByEventID(c *gin.Context) {
 func (h *EventHandler) DeleteEventByID(c *gin.Context) {
 	eventID := c.Param("id")
 
+	if !utils.CanAccessEvent(c, eventID) {
+		response.Error(c, response.NewForbidden("You are not allowed to manage this event"))
+		return
+	}
+
+	before, err := h.service.GetEventByID(eventID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	utils.AuditBefore(c, before)
+
 	// delete event record
 	if err := h.service.DeleteEventByID(eventID); err != nil {
 		response.Error(c, err)
@@ -151,9 +175,7 @@ func (h *EventHandler) DeleteEventByID(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "delete", "event", eventID)
-
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "delete", "event", eventID)
 
 	response.OK(c, "Event deleted successfully", eventID)
 }
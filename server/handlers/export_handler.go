@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	service services.ExportService
+}
+
+func NewExportHandler(service services.ExportService) *ExportHandler {
+	return &ExportHandler{service}
+}
+
+func (h *ExportHandler) CreateExportJob(c *gin.Context) {
+	var req dto.CreateExportRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	job, err := h.service.CreateExportJob(utils.MustGetUserID(c), utils.MustGetRole(c), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "create", "exports", job)
+	response.Created(c, "Export job queued successfully", job)
+}
+
+func (h *ExportHandler) GetExportJob(c *gin.Context) {
+	job, err := h.service.GetExportJob(utils.MustGetUserID(c), c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Export job retrieved successfully", job)
+}
+
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, file, err := h.service.OpenExportFile(utils.MustGetUserID(c), c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, job.FileSize, utils.ExportContentType(job.Format), file, map[string]string{
+		"Content-Disposition": "attachment; filename=" + job.FileName,
+		"Cache-Control":       "no-cache",
+	})
+}
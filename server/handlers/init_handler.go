@@ -15,6 +15,12 @@ type Handlers struct {
 	WithdrawalHandler *WithdrawalHandler
 	PaymentHandler    *PaymentHandler
 	AdminHandler      *AdminHandler
+	SessionHandler    *SessionHandler
+	AccountHandler    *AccountHandler
+	APIClientHandler  *APIClientHandler
+	RoleHandler       *RoleHandler
+	AuditHandler      *AuditHandler
+	ExportHandler     *ExportHandler
 }
 
 func InitHandlers(s *services.Services, r *repositories.Repositories) *Handlers {
@@ -22,11 +28,17 @@ func InitHandlers(s *services.Services, r *repositories.Repositories) *Handlers
 		AuthHandler:       NewAuthHandler(s.AuthService),
 		OrderHandler:      NewOrderHandler(s.OrderService),
 		UserTicketHandler: NewUserTicketHandler(s.UserTicketService),
-		UserHandler:       NewUserHandler(s.UserService, r.AuditRepository),
-		EventHandler:      NewEventHandler(s.EventService, r.AuditRepository),
-		TicketHandler:     NewTicketHandler(s.TicketService, r.AuditRepository),
-		WithdrawalHandler: NewWithdrawalHandler(s.WithdrawalService, r.AuditRepository),
+		UserHandler:       NewUserHandler(s.UserService),
+		EventHandler:      NewEventHandler(s.EventService),
+		TicketHandler:     NewTicketHandler(s.TicketService),
+		WithdrawalHandler: NewWithdrawalHandler(s.WithdrawalService),
 		PaymentHandler:    NewPaymentHandler(s.PaymentService),
 		AdminHandler:      NewAdminHandler(s.AdminService),
+		SessionHandler:    NewSessionHandler(s.SessionService),
+		AccountHandler:    NewAccountHandler(s.AccountService),
+		APIClientHandler:  NewAPIClientHandler(s.APIClientService),
+		RoleHandler:       NewRoleHandler(s.RoleService),
+		AuditHandler:      NewAuditHandler(s.AuditService),
+		ExportHandler:     NewExportHandler(s.ExportService),
 	}
 }
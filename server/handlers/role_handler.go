@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	service services.RoleService
+}
+
+func NewRoleHandler(service services.RoleService) *RoleHandler {
+	return &RoleHandler{service}
+}
+
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	response.OK(c, "Roles retrieved successfully", h.service.GetRoles())
+}
+
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	roles, err := h.service.GetUserRoles(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "User roles retrieved successfully", roles)
+}
+
+func (h *RoleHandler) UpdateUserRole(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+
+	var req dto.UpdateUserRoleRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	before, err := h.service.GetUserRoles(c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	utils.AuditBefore(c, before)
+
+	roles, err := h.service.UpdateUserRole(adminID, c.Param("id"), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "update_role", "user", roles)
+
+	response.OK(c, "User role updated successfully", roles)
+}
+
+func (h *RoleHandler) AssignEventRole(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	userID := c.Param("id")
+
+	var req dto.CreateRoleAssignmentRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	assignment, err := h.service.AssignEventRole(adminID, userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "assign_role", "user", gin.H{"userId": userID, "assignment": assignment})
+
+	response.Created(c, "Role assigned successfully", assignment)
+}
+
+func (h *RoleHandler) RevokeEventRole(c *gin.Context) {
+	userID := c.Param("id")
+	assignmentID := c.Param("assignmentId")
+
+	if err := h.service.RevokeEventRole(userID, assignmentID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "revoke_role", "user", gin.H{"userId": userID, "assignmentId": assignmentID})
+
+	response.OK(c, "Role revoked successfully", nil)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	service services.SessionService
+}
+
+func NewSessionHandler(service services.SessionService) *SessionHandler {
+	return &SessionHandler{service}
+}
+
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	sessions, err := h.service.GetUserSessions(userID, utils.GetSessionID(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+	sessionID := c.Param("id")
+
+	if err := h.service.RevokeUserSession(userID, sessionID, "revoked_by_user"); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// revoking the current session is a logout
+	if sessionID == utils.GetSessionID(c) {
+		utils.ClearAccessTokenCookie(c)
+		utils.ClearRefreshTokenCookie(c)
+	}
+
+	utils.AuditAction(c, "revoke", "session", sessionID)
+
+	response.OK(c, "Session revoked successfully", sessionID)
+}
+
+func (h *SessionHandler) GetUserSessions(c *gin.Context) {
+	userID := c.Param("id")
+
+	sessions, err := h.service.GetUserSessions(userID, "")
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Sessions retrieved successfully", sessions)
+}
+
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	if err := h.service.RevokeUserSession(userID, sessionID, "revoked_by_admin"); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "force_revoke", "session", map[string]string{"userId": userID, "sessionId": sessionID})
+
+	response.OK(c, "Session revoked successfully", sessionID)
+}
+
+func (h *SessionHandler) RevokeAllUserSessions(c *gin.Context) {
+	userID := c.Param("id")
+
+	if err := h.service.RevokeAllUserSessions(userID, "revoked_by_admin"); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "force_revoke_all", "session", userID)
+
+	response.OK(c, "All sessions revoked successfully", userID)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
@@ -13,12 +12,11 @@ import (
 )
 
 type TicketHandler struct {
-	service    services.TicketService
-	repository repositories.AuditLogRepository
+	service services.TicketService
 }
 
-func NewTicketHandler(service services.TicketService, repository repositories.AuditLogRepository) *TicketHandler {
-	return &TicketHandler{service, repository}
+func NewTicketHandler(service services.TicketService) *TicketHandler {
+	return &TicketHandler{service}
 }
 
 func (h *TicketHandler) CreateTicket(c *gin.Context) {
@@ -28,6 +26,11 @@ func (h *TicketHandler) CreateTicket(c *gin.Context) {
 		return
 	}
 
+	if !utils.CanAccessEvent(c, eventID) {
+		response.Error(c, response.NewForbidden("You are not allowed to manage this event"))
+		return
+	}
+
 	var req dto.CreateTicketRequest
 	if !utils.BindAndValidateJSON(c, &req) {
 		return
@@ -40,14 +43,17 @@ func (h *TicketHandler) CreateTicket(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "create", "ticket", newTicket)
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "create", "ticket", newTicket)
 
 	response.Created(c, "Ticket created successfully", newTicket)
 }
 
 func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 	id := c.Param("id")
+	if !h.canManageTicket(c, id) {
+		return
+	}
+
 	var req dto.UpdateTicketRequest
 
 	if !utils.BindAndValidateJSON(c, &req) {
@@ -61,8 +67,7 @@ func (h *TicketHandler) UpdateTicket(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "update", "ticket", updatedTicket)
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "update", "ticket", updatedTicket)
 
 	response.OK(c, "Ticket updated successfully", updatedTicket)
 }
@@ -81,6 +86,9 @@ func (h *TicketHandler) GetTicketByID(c *gin.Context) {
 
 func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 	ticketID := c.Param("id")
+	if !h.canManageTicket(c, ticketID) {
+		return
+	}
 
 	if err := h.service.DeleteTicket(ticketID); err != nil {
 		response.Error(c, err)
@@ -88,9 +96,26 @@ func (h *TicketHandler) DeleteTicket(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, utils.MustGetUserID(c), "delete", "ticket", ticketID)
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "delete", "ticket", ticketID)
 
 	response.OK(c, "Ticket deleted successfully", ticketID)
 
 }
+
+// canManageTicket checks the ticket's event against the event scope of the request and keeps the
+// ticket as the audit snapshot before the change
+func (h *TicketHandler) canManageTicket(c *gin.Context, ticketID string) bool {
+	ticket, err := h.service.GetTicketByID(ticketID)
+	if err != nil {
+		response.Error(c, err)
+		return false
+	}
+
+	if !utils.CanAccessEvent(c, ticket.EventID) {
+		response.Error(c, response.NewForbidden("You are not allowed to manage this event"))
+		return false
+	}
+
+	utils.AuditBefore(c, ticket)
+	return true
+}
@@ -1,7 +1,8 @@
 package handlers
 
 import (
-	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"time"
+
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
@@ -13,12 +14,11 @@ import (
 )
 
 type UserHandler struct {
-	service    services.UserService
-	repository repositories.AuditLogRepository
+	service services.UserService
 }
 
-func NewUserHandler(service services.UserService, repository repositories.AuditLogRepository) *UserHandler {
-	return &UserHandler{service, repository}
+func NewUserHandler(service services.UserService) *UserHandler {
+	return &UserHandler{service}
 }
 
 func (h *UserHandler) GetMyProfile(c *gin.Context) {
@@ -30,6 +30,16 @@ func (h *UserHandler) GetMyProfile(c *gin.Context) {
 		return
 	}
 
+	if impersonatorID := utils.GetImpersonatorID(c); impersonatorID != "" {
+		value, _ := c.Get("impersonationExpiresAt")
+		expiresAt, _ := value.(time.Time)
+		userProfile.Impersonation = &dto.ImpersonationBanner{
+			Active:         true,
+			ImpersonatorID: impersonatorID,
+			ExpiresAt:      expiresAt,
+		}
+	}
+
 	response.OK(c, "Profile retrieved successfully", userProfile)
 }
 
@@ -58,9 +68,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// record audit log
-	auditLog := utils.BuildAuditLog(c, userID, "update", "profile", updatedProfile)
-
-	go h.repository.Create(c.Request.Context(), auditLog)
+	utils.AuditAction(c, "update", "profile", updatedProfile)
 
 	response.OK(c, "Profile updated successfully", updatedProfile)
 }
@@ -80,3 +88,36 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 
 	response.OK(c, "Password changed successfully", nil)
 }
+
+func (h *UserHandler) RequestEmailChange(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.ChangeEmailRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.RequestEmailChange(userID, utils.GetSessionID(c), &req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "request", "email_change", req.NewEmail)
+
+	response.OK(c, "A confirmation link has been sent to the new email address", nil)
+}
+
+// ConfirmEmailChange is public, the link may be opened on a device that isn't signed in
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req dto.ConfirmEmailChangeRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ConfirmEmailChange(req.Token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Email changed successfully, other devices have been signed out", nil)
+}
@@ -31,6 +31,18 @@ func (h *UserTicketHandler) GetTicketByID(c *gin.Context) {
 
 func (h *UserTicketHandler) UseTicket(c *gin.Context) {
 	id := c.Param("id")
+
+	ticket, err := h.service.GetUserTicketByID(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if !utils.CanAccessEvent(c, ticket.EventID) {
+		response.Error(c, response.NewForbidden("You are not assigned to this event"))
+		return
+	}
+
 	if err := h.service.MarkTicketUsed(id); err != nil {
 		response.Error(c, err)
 		return
@@ -51,6 +63,11 @@ func (h *UserTicketHandler) ValidateTicket(c *gin.Context) {
 		return
 	}
 
+	if !utils.CanAccessEvent(c, ticket.EventID) {
+		response.Error(c, response.NewForbidden("You are not assigned to this event"))
+		return
+	}
+
 	response.OK(c, "Ticket validated successfully", ticket)
 }
 
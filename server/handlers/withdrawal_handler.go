@@ -1,21 +1,23 @@
 package handlers
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
-	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+	"github.com/fiqrioemry/go-api-toolkit/pagination"
 	"github.com/fiqrioemry/go-api-toolkit/response"
 	"github.com/gin-gonic/gin"
 )
 
 type WithdrawalHandler struct {
-	service    services.WithdrawalService
-	repository repositories.AuditLogRepository
+	service services.WithdrawalService
 }
 
-func NewWithdrawalHandler(service services.WithdrawalService, repository repositories.AuditLogRepository) *WithdrawalHandler {
-	return &WithdrawalHandler{service, repository}
+func NewWithdrawalHandler(service services.WithdrawalService) *WithdrawalHandler {
+	return &WithdrawalHandler{service}
 }
 
 func (h *WithdrawalHandler) CreateWithdrawal(c *gin.Context) {
@@ -46,22 +48,322 @@ func (h *WithdrawalHandler) GetAllWithdrawals(c *gin.Context) {
 func (h *WithdrawalHandler) ReviewWithdrawal(c *gin.Context) {
 	adminID := utils.MustGetUserID(c)
 	id := c.Param("id")
-	var body struct {
-		Status string `json:"status" binding:"required,oneof=approved rejected"`
+
+	var req dto.ReviewWithdrawalRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+	res, err := h.service.ReviewWithdrawal(id, adminID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
 	}
 
-	if !utils.BindAndValidateJSON(c, &body) {
+	utils.AuditAction(c, "review", "withdrawal", res)
+
+	response.OK(c, "Withdrawal reviewed successfully", res)
+}
+
+func (h *WithdrawalHandler) GetMyWithdrawals(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	// bind query params
+	var params dto.WithdrawalQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
 		return
 	}
-	res, err := h.service.ReviewWithdrawal(id, adminID, body.Status)
+
+	// apply pagination defaults
+	if err := pagination.BindAndSetDefaults(c, &params); err != nil {
+		response.Error(c, response.BadRequest(err.Error()))
+		return
+	}
+
+	res, total, err := h.service.GetMyWithdrawals(userID, params)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	auditLog := utils.BuildAuditLog(c, adminID, "review", "withdrawal", res)
+	// build pagination meta
+	pag := pagination.Build(params.Page, params.Limit, total)
 
-	go h.repository.Create(c.Request.Context(), auditLog)
+	response.OKWithPagination(c, "Withdrawals retrieved successfully", res, pag)
+}
 
-	response.OK(c, "Withdrawal reviewed successfully", res)
+func (h *WithdrawalHandler) GetMyWithdrawalDetail(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+	id := c.Param("id")
+
+	res, err := h.service.GetMyWithdrawalByID(id, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Withdrawal retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) CancelWithdrawal(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+	id := c.Param("id")
+
+	res, err := h.service.CancelWithdrawal(id, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "cancel", "withdrawal", res)
+
+	response.OK(c, "Withdrawal cancelled successfully", res)
+}
+
+func (h *WithdrawalHandler) GetPayoutAccounts(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	res, err := h.service.GetPayoutAccounts(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Payout accounts retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) CreatePayoutAccount(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.CreatePayoutAccountRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	res, err := h.service.CreatePayoutAccount(userID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "create", "payout_account", res)
+
+	response.Created(c, "Payout account saved successfully", res)
+}
+
+func (h *WithdrawalHandler) DeletePayoutAccount(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+	id := c.Param("id")
+
+	if err := h.service.DeletePayoutAccount(id, userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "delete", "payout_account", id)
+
+	response.OK(c, "Payout account deleted successfully", id)
+}
+
+func (h *WithdrawalHandler) VerifyPayoutAccount(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.VerifyPayoutAccountRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	res, err := h.service.VerifyPayoutAccount(id, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "verify", "payout_account", res)
+
+	response.OK(c, "Payout account reviewed successfully", res)
+}
+
+func (h *WithdrawalHandler) CreatePayoutBatch(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+
+	var req dto.CreatePayoutBatchRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	res, err := h.service.CreatePayoutBatch(adminID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "create", "payout_batch", res)
+
+	response.Created(c, "Payout batch created successfully", res)
+}
+
+func (h *WithdrawalHandler) GetPayoutBatches(c *gin.Context) {
+	res, err := h.service.GetPayoutBatches()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Payout batches retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) GetPayoutBatchDetail(c *gin.Context) {
+	id := c.Param("id")
+
+	res, err := h.service.GetPayoutBatchByID(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Payout batch retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) ExportPayoutBatch(c *gin.Context) {
+	id := c.Param("id")
+
+	filename, content, err := h.service.ExportPayoutBatch(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if !strings.HasSuffix(filename, ".csv") {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, contentType, content)
+}
+
+func (h *WithdrawalHandler) ReconcilePayoutBatch(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	id := c.Param("id")
+
+	var req dto.ReconcilePayoutBatchRequest
+	if !utils.BindAndValidateForm(c, &req) {
+		return
+	}
+
+	res, err := h.service.ReconcilePayoutBatch(id, adminID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "reconcile", "payout_batch", res)
+
+	response.OK(c, "Payout batch reconciled successfully", res)
+}
+
+func (h *WithdrawalHandler) GetWithdrawalPolicy(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	res, err := h.service.GetWithdrawalPolicy(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Withdrawal policy retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) SubmitIdentity(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	var req dto.SubmitIdentityRequest
+	if !utils.BindAndValidateForm(c, &req) {
+		return
+	}
+
+	documentID, err := utils.UploadPrivateDocument(req.Document)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	req.DocumentID = documentID
+
+	res, err := h.service.SubmitIdentity(userID, req)
+	if err != nil {
+		utils.DeletePrivateDocument(documentID)
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "submit", "identity", res.ID)
+
+	response.Created(c, "Identity submitted for review", res)
+}
+
+func (h *WithdrawalHandler) GetMyIdentity(c *gin.Context) {
+	userID := utils.MustGetUserID(c)
+
+	res, err := h.service.GetMyIdentity(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Identity retrieved successfully", res)
+}
+
+func (h *WithdrawalHandler) GetIdentities(c *gin.Context) {
+	var params dto.IdentityQueryParams
+	if !utils.BindAndValidateForm(c, &params) {
+		return
+	}
+
+	res, err := h.service.GetIdentities(params)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, "Identities retrieved successfully", res)
+}
+
+// GetIdentityDocument streams the identity document to a reviewer, every view is audited
+func (h *WithdrawalHandler) GetIdentityDocument(c *gin.Context) {
+	id := c.Param("id")
+
+	file, size, err := h.service.OpenIdentityDocument(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	defer file.Close()
+
+	utils.AuditAction(c, "view", "identity_document", id)
+
+	c.DataFromReader(http.StatusOK, size, "image/jpeg", file, map[string]string{
+		"Content-Disposition": "inline",
+		"Cache-Control":       "no-store",
+	})
+}
+
+func (h *WithdrawalHandler) ReviewIdentity(c *gin.Context) {
+	adminID := utils.MustGetUserID(c)
+	id := c.Param("id")
+
+	var req dto.ReviewIdentityRequest
+	if !utils.BindAndValidateJSON(c, &req) {
+		return
+	}
+
+	res, err := h.service.ReviewIdentity(id, adminID, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	utils.AuditAction(c, "review", "identity", res)
+
+	response.OK(c, "Identity reviewed successfully", res)
 }
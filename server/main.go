@@ -12,6 +12,7 @@ import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/seeders"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/worker"
 
 	"time"
 
@@ -45,10 +46,15 @@ func main() {
 	s := services.InitServices(repo)
 	h := handlers.InitHandlers(s, repo)
 
-	cronManager := cron.NewCronManager(s.PaymentService)
+	middleware.InitPermissions(repo.RoleRepository)
+
+	cronManager := cron.NewCronManager(s.PaymentService, s.OrganizerService, s.AuditService, s.ExportService)
 	cronManager.RegisterJobs()
 	cronManager.Start()
 
+	exportWorker := worker.NewExportWorker(s.ExportService)
+	exportWorker.Start()
+
 	// ========== Inisialisasi gin engine =======
 	r := gin.Default()
 
@@ -61,7 +67,8 @@ func main() {
 		middleware.CORS(),
 		// middleware.RateLimiter(100, 60*time.Second),
 		middleware.LimitFileSize(config.AppConfig.MaxFileSize),
-		middleware.APIKeyGateway(config.AppConfig.SkippedApiEndpoints),
+		middleware.APIKeyGateway(config.AppConfig.SkippedApiEndpoints, repo.APIClientRepository),
+		middleware.Audit(repo.AuditRepository),
 	)
 
 	// ========== inisialisasi routes ===========
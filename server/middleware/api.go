@@ -1,15 +1,22 @@
 package middleware
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/go-api-toolkit/response"
 	"github.com/gin-gonic/gin"
 )
 
-func APIKeyGateway(skippedPaths []string) gin.HandlerFunc {
+// APIKeyGateway identifies the calling API client by the hash of its X-API-KEY, then checks expiry,
+// the scope the route needs and the client's per-minute rate limit
+func APIKeyGateway(skippedPaths []string, clients repositories.APIClientRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		currentPath := c.Request.URL.Path
 
@@ -40,21 +47,57 @@ func APIKeyGateway(skippedPaths []string) gin.HandlerFunc {
 			return
 		}
 
-		validKeys := strings.Split(config.AppConfig.ApiKeys, ",")
-		isValid := false
-		for _, validKey := range validKeys {
-			if strings.TrimSpace(validKey) == apiKey {
-				isValid = true
-				break
-			}
+		client, err := clients.GetClientByKeyHash(utils.HashAPIKey(apiKey))
+		if err != nil {
+			response.Error(c, response.NewInternalServerError("Failed to verify API key", err))
+			c.Abort()
+			return
 		}
 
-		if !isValid {
+		if client == nil || client.RevokedAt != nil {
 			response.Error(c, response.NewUnauthorized("Unauthorized - invalid API key"))
 			c.Abort()
 			return
 		}
 
+		if client.ExpiresAt != nil && time.Now().After(*client.ExpiresAt) {
+			response.Error(c, response.NewUnauthorized("Unauthorized - API key has expired"))
+			c.Abort()
+			return
+		}
+
+		required := utils.RequiredScope(c.Request.Method, c.FullPath())
+		if !utils.ScopeAllowed(strings.Split(client.Scopes, ","), required) {
+			response.Error(c, response.NewForbidden("API key is missing the "+required+" scope"))
+			c.Abort()
+			return
+		}
+
+		if client.RateLimit > 0 {
+			window := time.Now().Unix() / 60
+			key := fmt.Sprintf("ticket:api_rate:%s:%d", client.ID, window)
+
+			count, _ := config.RedisClient.Incr(config.Ctx, key).Result()
+			if count == 1 {
+				config.RedisClient.Expire(config.Ctx, key, time.Minute)
+			}
+
+			remaining := max(int64(client.RateLimit)-count, 0)
+			c.Header("X-RateLimit-Limit", strconv.Itoa(client.RateLimit))
+			c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+
+			if count > int64(client.RateLimit) {
+				c.Header("Retry-After", strconv.FormatInt(60-time.Now().Unix()%60, 10))
+				response.Error(c, response.NewTooManyRequests("API key rate limit exceeded").
+					WithContext("apiClient", client.Name))
+				c.Abort()
+				return
+			}
+		}
+
+		go clients.TouchClient(client.ID.String(), c.ClientIP())
+
+		c.Set("apiClientID", client.ID.String())
 		c.Next()
 	}
 }
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodies above this size are not copied into the audit trail
+const maxAuditBody = 64 << 10
+
+// Audit writes one audit entry for every mutating request and for every request made with an impersonation
+// token once the handler has run, handlers name the action and attach snapshots with utils.AuditAction and
+// utils.AuditBefore, the rest is derived from the route
+func Audit(logs repositories.AuditLogRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body map[string]any
+		mutating := false
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			mutating = true
+			body = readAuditBody(c)
+		}
+
+		c.Next()
+
+		// unknown routes never reached a handler
+		if c.FullPath() == "" {
+			return
+		}
+
+		// the impersonator is only known once AuthRequired has run
+		if !mutating && utils.GetImpersonatorID(c) == "" {
+			return
+		}
+
+		entry := utils.BuildRequestAuditLog(c, body)
+		go func() {
+			if err := logs.Create(context.Background(), entry); err != nil {
+				log.Printf("failed to write audit log %s: %v", entry.ID, err)
+			}
+		}()
+	}
+}
+
+// readAuditBody copies a JSON body for the audit entry and puts it back for the handler
+func readAuditBody(c *gin.Context) map[string]any {
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return nil
+	}
+
+	raw, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil || len(raw) == 0 || len(raw) > maxAuditBody {
+		return nil
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil
+	}
+	return body
+}
@@ -1,9 +1,7 @@
 package middleware
 
 import (
-	"fmt"
 	"log"
-	"slices"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
@@ -13,8 +11,9 @@ import (
 
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString, err := c.Cookie("accessToken")
-		if err != nil || tokenString == "" {
+		// bearer header for mobile apps and devices, cookie for the browser
+		tokenString := utils.GetAccessToken(c)
+		if tokenString == "" {
 			response.Error(c, response.Unauthorized("Unauthorized!! Token missing"))
 			c.Abort()
 			return
@@ -26,22 +25,29 @@ func AuthRequired() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		if claims.SessionID != "" && utils.IsSessionRevoked(claims.SessionID) {
+			response.Error(c, response.Unauthorized("Session has been revoked"))
+			c.Abort()
+			return
+		}
+
+		if utils.IsUserBlocked(claims.UserID) {
+			response.Error(c, response.Forbidden("Your account is not active"))
+			c.Abort()
+			return
+		}
+
 		log.Println("Authenticated user:", claims.UserID, "Role:", claims.Role)
 		c.Set("role", claims.Role)
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("twoFactor", claims.TwoFactor)
+		if claims.ImpersonatorID != "" {
+			c.Set("impersonatorID", claims.ImpersonatorID)
+			c.Set("impersonationExpiresAt", claims.ExpiresAt.Time)
+		}
 
 		c.Next()
 	}
 }
-
-func RoleOnly(allowedRoles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role := utils.MustGetRole(c)
-		if slices.Contains(allowedRoles, role) {
-			c.Next()
-			return
-		}
-		response.Error(c, response.Forbidden(fmt.Sprintf("Forbidden: Access denied. Only roles %v are allowed", allowedRoles)))
-		c.Abort()
-	}
-}
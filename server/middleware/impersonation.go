@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockImpersonation keeps admins acting as a user away from money movement and account security,
+// it must run after AuthRequired
+func BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if utils.GetImpersonatorID(c) != "" {
+			response.Error(c, response.Forbidden("This action is not available while impersonating a user"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestScopeToOrganizerOverridesTheFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	var organizerID string
+	r.GET("/organizer/orders", func(c *gin.Context) {
+		c.Set("userID", "organizer-1")
+		c.Next()
+	}, ScopeToOrganizer(), func(c *gin.Context) {
+		organizerID = c.Query("organizerId")
+	})
+
+	// another organizer's ID in the query must not widen the report
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/organizer/orders?organizerId=organizer-2&page=2", nil))
+	if organizerID != "organizer-1" {
+		t.Fatalf("expected the report to be scoped to the caller, got %q", organizerID)
+	}
+}
@@ -16,9 +16,110 @@ type User struct {
 	Email     string    `json:"email" gorm:"type:varchar(100);unique;not null"`
 	Password  string    `json:"-" gorm:"type:text;not null"`
 	Avatar    string    `json:"avatar" gorm:"type:varchar(255)"`
-	Role      string    `json:"role" gorm:"type:enum('admin','user');default:'user'"`
+	Role      string    `json:"role" gorm:"type:enum('super_admin','finance','event_manager','checkin_staff','organizer','user');default:'user'"`
 	Balance   float64   `json:"balance" gorm:"type:decimal(12,2);default:0.00"`
 	CreatedAt time.Time `json:"joinedAt" gorm:"autoCreateTime"`
+
+	// refund clawbacks an organizer's balance could not cover, taken from their next settlements
+	ClawbackDue float64 `json:"-" gorm:"type:decimal(12,2);default:0.00"`
+
+	TwoFactorEnabled bool   `json:"twoFactorEnabled" gorm:"default:false"`
+	TwoFactorSecret  string `json:"-" gorm:"type:varchar(255)"`
+
+	// set by admins, suspended and banned accounts can't sign in or use existing tokens
+	Status                string     `json:"status" gorm:"type:enum('active','suspended','banned');default:'active';not null"`
+	StatusReason          string     `json:"statusReason" gorm:"type:varchar(255)"`
+	StatusChangedAt       *time.Time `json:"statusChangedAt"`
+	PasswordResetRequired bool       `json:"passwordResetRequired" gorm:"default:false"`
+
+	// set when the account is closed, personal fields are anonymized at the same time
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// User account statuses
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+)
+
+// RecoveryCode is a bcrypt-hashed one-time code that can replace a TOTP code once
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);index"`
+	CodeHash  string     `gorm:"type:varchar(255);not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// RoleAssignment grants a role on a single event on top of the user's global role
+type RoleAssignment struct {
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_user_role_event"`
+	Role      string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_user_role_event"`
+	EventID   uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_user_role_event;index"`
+	CreatedBy string    `gorm:"type:char(36)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	Event Event `gorm:"foreignKey:EventID"`
+}
+
+// APIClient is an integration calling the API with its own key, only the SHA-256 of the key is stored
+type APIClient struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	Name       string     `gorm:"type:varchar(100);not null"`
+	KeyPrefix  string     `gorm:"type:varchar(16);not null"`
+	KeyHash    string     `gorm:"type:char(64);uniqueIndex;not null"`
+	Scopes     string     `gorm:"type:varchar(500);not null"` // comma separated, "*" grants everything
+	RateLimit  int        `gorm:"not null"`                   // requests per minute, 0 means unlimited
+	ExpiresAt  *time.Time `gorm:"default:null"`
+	LastUsedAt *time.Time `gorm:"default:null"`
+	LastUsedIP string     `gorm:"type:varchar(45)"`
+	RevokedAt  *time.Time `gorm:"default:null"`
+	CreatedBy  string     `gorm:"type:char(36)"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime"`
+}
+
+// LinkedIdentity is an external sign-in account attached to a user, the provider and subject pair is unique
+type LinkedIdentity struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:char(36);index"`
+	Provider    string     `gorm:"type:varchar(30);not null;uniqueIndex:idx_provider_subject"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject"`
+	Email       string     `gorm:"type:varchar(100)"`
+	LastLoginAt *time.Time `gorm:"default:null"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+// UserSession is one signed-in device, every refresh token rotated from the same login shares the session (token family)
+type UserSession struct {
+	ID            uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID  `gorm:"type:char(36);index"`
+	Device        string     `gorm:"type:varchar(100)"`
+	UserAgent     string     `gorm:"type:varchar(255)"`
+	IP            string     `gorm:"type:varchar(45)"`
+	Location      string     `gorm:"type:varchar(100)"`
+	Client        string     `gorm:"type:enum('web','native');default:'web'"` // native sessions refresh through the token endpoints only
+	TwoFactor     bool       `gorm:"default:false"`                           // the login passed a TOTP or recovery code check
+	ExpiresAt     time.Time  `gorm:"not null"`
+	LastUsedAt    time.Time  `gorm:"not null"`
+	RevokedAt     *time.Time `gorm:"default:null"`
+	RevokedReason string     `gorm:"type:varchar(50)"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// RefreshToken is a single issued refresh token, once rotated it must never be presented again
+type RefreshToken struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	SessionID uuid.UUID  `gorm:"type:char(36);index"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RotatedAt *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+
+	Session UserSession `gorm:"foreignKey:SessionID"`
 }
 
 // TODO : Future improvements add categories and tags for events, so event can be classfied and filtered based on these attributes
@@ -39,6 +140,7 @@ type Event struct {
 	Title       string    `gorm:"type:varchar(150);unique;not null"`
 	Description string    `gorm:"type:text"`
 	Location    string    `gorm:"type:varchar(100)"`
+	Category    string    `gorm:"type:varchar(50);index;default:''"`
 	Date        time.Time `gorm:"not null" json:"date"`
 	StartTime   int       `gorm:"not null" json:"startTime"`
 	EndTime     int       `gorm:"not null" json:"endTime"`
@@ -46,7 +148,10 @@ type Event struct {
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
 
-	// Category category `gorm:"foreignKey:CategoryID"` // TODO : Future improvements, add category for event
+	// events without an organizer are run by the platform and keep the whole sale
+	OrganizerID        *uuid.UUID `gorm:"type:char(36);index"`
+	PlatformFeePercent float64    `gorm:"type:decimal(5,2);not null;default:0"`
+
 	Tickets []Ticket `gorm:"foreignKey:EventID"`
 }
 
@@ -67,17 +172,18 @@ type Ticket struct {
 }
 
 type Order struct {
-	ID         uuid.UUID `gorm:"type:char(36);primaryKey"`
-	UserID     uuid.UUID `gorm:"type:char(36);index"`
-	EventID    uuid.UUID `gorm:"type:char(36);index"`
-	Fullname   string    `gorm:"type:varchar(100);not null"`
-	Email      string    `gorm:"type:varchar(100);not null"`
-	Phone      string    `gorm:"type:varchar(20);not null"`
-	TotalPrice float64   `gorm:"type:decimal(12,2);not null"`
-	PaymentURL string    `gorm:"type:text"`
-	Status     string    `gorm:"type:enum('pending','paid','failed','cancelled','refunded');default:'pending'"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID       uuid.UUID `gorm:"type:char(36);index"`
+	EventID      uuid.UUID `gorm:"type:char(36);index"`
+	Fullname     string    `gorm:"type:varchar(100);not null"`
+	Email        string    `gorm:"type:varchar(100);not null"`
+	Phone        string    `gorm:"type:varchar(20);not null"`
+	TotalPrice   float64   `gorm:"type:decimal(12,2);not null"`
+	WalletAmount float64   `gorm:"type:decimal(12,2);default:0"`
+	PaymentURL   string    `gorm:"type:text"`
+	Status       string    `gorm:"type:enum('pending','paid','failed','cancelled','refunded');default:'pending'"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
 
 	// TODO : Optional features. if sufficient time, implement these
 	IsRefunded   bool       `gorm:"default:false"`
@@ -116,11 +222,12 @@ type Payment struct {
 }
 
 type UserTicket struct {
-	ID        uuid.UUID `gorm:"type:char(36);primaryKey"`
-	UserID    uuid.UUID `gorm:"type:char(36);index"`
-	EventID   uuid.UUID `gorm:"type:char(36);index"`
-	TicketID  uuid.UUID `gorm:"type:char(36);index"`
-	IsUsed    bool      `gorm:"default:false"`
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:char(36);index"`
+	EventID   uuid.UUID  `gorm:"type:char(36);index"`
+	TicketID  uuid.UUID  `gorm:"type:char(36);index"`
+	OrderID   *uuid.UUID `gorm:"type:char(36);index"` // nil for tickets issued before they were linked to their order
+	IsUsed    bool       `gorm:"default:false"`
 	UsedAt    *time.Time
 	QRCode    string    `gorm:"type:varchar(255)"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
@@ -130,29 +237,191 @@ type UserTicket struct {
 }
 
 type WithdrawalRequest struct {
-	ID         uuid.UUID `gorm:"type:char(36);primaryKey"`
-	UserID     uuid.UUID `gorm:"type:char(36);index"`
-	Amount     float64   `gorm:"type:decimal(12,2);not null"`
-	Status     string    `gorm:"type:enum('pending','approved','rejected');default:'pending'"`
-	Reason     string    `gorm:"type:text"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
-	ApprovedAt *time.Time
+	ID              uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID          uuid.UUID  `gorm:"type:char(36);index"`
+	PayoutAccountID *uuid.UUID `gorm:"type:char(36);index"`
+	PayoutBatchID   *uuid.UUID `gorm:"type:char(36);index"`
+	Amount          float64    `gorm:"type:decimal(12,2);not null"`
+	Fee             float64    `gorm:"type:decimal(12,2);default:0"`
+	Status          string     `gorm:"type:enum('pending','approved','rejected','cancelled','paid','failed');default:'pending'"`
+	Reason          string     `gorm:"type:text"`
+	ReviewedBy      string     `gorm:"type:char(36);default:null"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+	ApprovedAt      *time.Time
+	PaidAt          *time.Time
+
+	User          User                      `gorm:"foreignKey:UserID"`
+	PayoutAccount PayoutAccount             `gorm:"foreignKey:PayoutAccountID"`
+	History       []WithdrawalStatusHistory `gorm:"foreignKey:WithdrawalID"`
+}
+
+// IdentityVerification is the KYC record required before large payouts leave the platform
+type IdentityVerification struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID         uuid.UUID  `gorm:"type:char(36);uniqueIndex"`
+	FullName       string     `gorm:"type:varchar(100);not null"`
+	DocumentType   string     `gorm:"type:enum('ktp','passport','sim');not null"`
+	DocumentNumber string     `gorm:"type:varchar(50);not null"`
+	DocumentID     string     `gorm:"column:document_image;type:varchar(255)"` // public ID of the private upload
+	Status         string     `gorm:"type:enum('pending','verified','rejected');default:'pending'"`
+	ReviewedBy     string     `gorm:"type:char(36);default:null"`
+	ReviewedAt     *time.Time `gorm:"default:null"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// OrganizerEarning splits a paid order of an organizer's event into the platform fee and the organizer's share,
+// the share is credited to the organizer's balance once the event is over
+type OrganizerEarning struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	OrganizerID    uuid.UUID  `gorm:"type:char(36);index"`
+	EventID        uuid.UUID  `gorm:"type:char(36);index"`
+	OrderID        uuid.UUID  `gorm:"type:char(36);uniqueIndex"`
+	GrossAmount    float64    `gorm:"type:decimal(12,2);not null"`
+	RefundedAmount float64    `gorm:"type:decimal(12,2);default:0"`
+	FeePercent     float64    `gorm:"type:decimal(5,2);not null"`
+	PlatformFee    float64    `gorm:"type:decimal(12,2);not null"`
+	NetAmount      float64    `gorm:"type:decimal(12,2);not null"`
+	Status         string     `gorm:"type:enum('pending','settled');default:'pending';index"`
+	SettledAt      *time.Time `gorm:"default:null"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+
+	Event     Event `gorm:"foreignKey:EventID"`
+	Organizer User  `gorm:"foreignKey:OrganizerID"`
+}
+
+// LedgerEntry records platform charges against a user, e.g. withdrawal fees and their reversals
+type LedgerEntry struct {
+	ID          uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID      uuid.UUID `gorm:"type:char(36);index"`
+	Type        string    `gorm:"type:varchar(30);not null;index"`
+	ReferenceID string    `gorm:"type:char(36);index"`
+	Amount      float64   `gorm:"type:decimal(12,2);not null"`
+	Description string    `gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// WithdrawalPolicyViolation keeps every rejected withdrawal attempt for the admin reports
+type WithdrawalPolicyViolation struct {
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID    uuid.UUID `gorm:"type:char(36);index"`
+	Rule      string    `gorm:"type:varchar(30);not null;index"`
+	Amount    float64   `gorm:"type:decimal(12,2);not null"`
+	Message   string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
 
 	User User `gorm:"foreignKey:UserID"`
 }
 
+// PayoutBatch groups approved withdrawals into one bank bulk-transfer file,
+// the bank's result file is imported back to mark each payout paid or failed
+type PayoutBatch struct {
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	Format       string     `gorm:"type:enum('csv','fixed');default:'csv'"`
+	Status       string     `gorm:"type:enum('exported','reconciled');default:'exported'"`
+	ItemCount    int        `gorm:"not null"`
+	TotalAmount  float64    `gorm:"type:decimal(14,2);not null"`
+	CreatedBy    string     `gorm:"type:char(36)"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	ReconciledAt *time.Time `gorm:"default:null"`
+
+	Withdrawals []WithdrawalRequest `gorm:"foreignKey:PayoutBatchID"`
+}
+
+// PayoutAccount is a bank account a user can withdraw their balance to
+type PayoutAccount struct {
+	ID            uuid.UUID      `gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID      `gorm:"type:char(36);index"`
+	BankCode      string         `gorm:"type:varchar(20);not null"`
+	AccountNumber string         `gorm:"type:varchar(34);not null"`
+	HolderName    string         `gorm:"type:varchar(100);not null"`
+	Status        string         `gorm:"type:enum('pending','verified','rejected');default:'pending'"`
+	VerifiedAt    *time.Time     `gorm:"default:null"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+type WithdrawalStatusHistory struct {
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey"`
+	WithdrawalID uuid.UUID `gorm:"type:char(36);index"`
+	Status       string    `gorm:"type:varchar(20);not null"`
+	Note         string    `gorm:"type:text"`
+	ActorID      string    `gorm:"type:char(36)"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// BalanceHold is taken out of User.Balance up front, then captured or released (returned) once the order/withdrawal settles
+type BalanceHold struct {
+	ID          uuid.UUID `gorm:"type:char(36);primaryKey"`
+	UserID      uuid.UUID `gorm:"type:char(36);index"`
+	Source      string    `gorm:"type:enum('order','withdrawal');not null;index:idx_hold_reference"`
+	ReferenceID uuid.UUID `gorm:"type:char(36);not null;index:idx_hold_reference"`
+	Amount      float64   `gorm:"type:decimal(12,2);not null"`
+	Status      string    `gorm:"type:enum('held','captured','released');default:'held'"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// AuditLog is written by the audit middleware for every mutating request, before/after are JSON snapshots
+// with sensitive fields redacted and changes holds the fields that differ between them
 type AuditLog struct {
-	ID          string         `gorm:"primaryKey;type:char(36)" json:"id"`
-	UserID      string         `gorm:"type:char(36);index" json:"user_id"`
-	Action      string         `gorm:"type:varchar(50);not null" json:"action"`
-	Resource    string         `gorm:"type:varchar(100);not null" json:"resource"`
-	Description string         `gorm:"type:text" json:"description"`
-	IP          string         `gorm:"type:varchar(45)" json:"ip"`
-	UserAgent   string         `gorm:"type:varchar(255)" json:"user_agent"`
-	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             string         `gorm:"primaryKey;type:char(36)" json:"id"`
+	UserID         string         `gorm:"type:char(36);index" json:"user_id"`
+	ImpersonatorID string         `gorm:"type:char(36);index" json:"impersonator_id"` // admin acting as UserID
+	Action         string         `gorm:"type:varchar(50);not null;index" json:"action"`
+	Resource       string         `gorm:"type:varchar(100);not null;index" json:"resource"`
+	ResourceID     string         `gorm:"type:varchar(36);index" json:"resource_id"`
+	Description    string         `gorm:"type:text" json:"description"`
+	Method         string         `gorm:"type:varchar(10)" json:"method"`
+	Path           string         `gorm:"type:varchar(255)" json:"path"`
+	StatusCode     int            `json:"status_code"`
+	Before         string         `gorm:"type:longtext" json:"before"`
+	After          string         `gorm:"type:longtext" json:"after"`
+	Changes        string         `gorm:"type:longtext" json:"changes"`
+	IP             string         `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent      string         `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// ExportJob produces a report file in the background, the file is kept in the export storage under
+// FileKey until ExpiresAt. Filters holds the report query parameters as a JSON object
+type ExportJob struct {
+	ID            uuid.UUID  `gorm:"type:char(36);primaryKey"`
+	UserID        uuid.UUID  `gorm:"type:char(36);index"`
+	Report        string     `gorm:"type:varchar(50);not null"`
+	Format        string     `gorm:"type:varchar(10);not null"`
+	Filters       string     `gorm:"type:text"`
+	Status        string     `gorm:"type:enum('pending','processing','completed','failed','expired');default:'pending';index"`
+	TotalRows     int        `gorm:"default:0"`
+	ProcessedRows int        `gorm:"default:0"`
+	Attempts      int        `gorm:"default:0"` // times a worker claimed the job
+	FileKey       string     `gorm:"type:varchar(255)"`
+	FileName      string     `gorm:"type:varchar(255)"`
+	FileSize      int64      `gorm:"default:0"`
+	Error         string     `gorm:"type:text"`
+	StartedAt     *time.Time `gorm:"default:null"`
+	CompletedAt   *time.Time `gorm:"default:null"`
+	ExpiresAt     *time.Time `gorm:"default:null;index"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// Export job statuses
+const (
+	ExportJobPending    = "pending"
+	ExportJobProcessing = "processing"
+	ExportJobCompleted  = "completed"
+	ExportJobFailed     = "failed"
+	ExportJobExpired    = "expired"
+)
+
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
@@ -208,3 +477,108 @@ func (wr *WithdrawalRequest) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+func (bh *BalanceHold) BeforeCreate(tx *gorm.DB) (err error) {
+	if bh.ID == uuid.Nil {
+		bh.ID = uuid.New()
+	}
+	return
+}
+
+func (pa *PayoutAccount) BeforeCreate(tx *gorm.DB) (err error) {
+	if pa.ID == uuid.Nil {
+		pa.ID = uuid.New()
+	}
+	return
+}
+
+func (wh *WithdrawalStatusHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	if wh.ID == uuid.Nil {
+		wh.ID = uuid.New()
+	}
+	return
+}
+
+func (pb *PayoutBatch) BeforeCreate(tx *gorm.DB) (err error) {
+	if pb.ID == uuid.Nil {
+		pb.ID = uuid.New()
+	}
+	return
+}
+
+func (iv *IdentityVerification) BeforeCreate(tx *gorm.DB) (err error) {
+	if iv.ID == uuid.Nil {
+		iv.ID = uuid.New()
+	}
+	return
+}
+
+func (le *LedgerEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if le.ID == uuid.Nil {
+		le.ID = uuid.New()
+	}
+	return
+}
+
+func (wv *WithdrawalPolicyViolation) BeforeCreate(tx *gorm.DB) (err error) {
+	if wv.ID == uuid.Nil {
+		wv.ID = uuid.New()
+	}
+	return
+}
+
+func (us *UserSession) BeforeCreate(tx *gorm.DB) (err error) {
+	if us.ID == uuid.Nil {
+		us.ID = uuid.New()
+	}
+	return
+}
+
+func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if rt.ID == uuid.Nil {
+		rt.ID = uuid.New()
+	}
+	return
+}
+
+func (rc *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+	return
+}
+
+func (li *LinkedIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	if li.ID == uuid.Nil {
+		li.ID = uuid.New()
+	}
+	return
+}
+
+func (ac *APIClient) BeforeCreate(tx *gorm.DB) (err error) {
+	if ac.ID == uuid.Nil {
+		ac.ID = uuid.New()
+	}
+	return
+}
+
+func (ra *RoleAssignment) BeforeCreate(tx *gorm.DB) (err error) {
+	if ra.ID == uuid.Nil {
+		ra.ID = uuid.New()
+	}
+	return
+}
+
+func (oe *OrganizerEarning) BeforeCreate(tx *gorm.DB) (err error) {
+	if oe.ID == uuid.Nil {
+		oe.ID = uuid.New()
+	}
+	return
+}
+
+func (ej *ExportJob) BeforeCreate(tx *gorm.DB) (err error) {
+	if ej.ID == uuid.Nil {
+		ej.ID = uuid.New()
+	}
+	return
+}
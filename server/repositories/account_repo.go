@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserData is everything stored about one user, used for the personal data export
+type UserData struct {
+	User             models.User
+	Orders           []models.Order
+	OrderDetails     []models.OrderDetail
+	Payments         []models.Payment
+	Tickets          []models.UserTicket
+	Withdrawals      []models.WithdrawalRequest
+	PayoutAccounts   []models.PayoutAccount
+	Sessions         []models.UserSession
+	LinkedIdentities []models.LinkedIdentity
+	AuditLogs        []models.AuditLog
+}
+
+// AccountNotSettledError is returned by AnonymizeUser while the user still has a balance, balance held
+// by an order or withdrawal in progress, or withdrawals waiting to be paid out
+type AccountNotSettledError struct {
+	Balance            float64
+	HeldBalance        float64
+	PendingWithdrawals int64
+}
+
+func (e *AccountNotSettledError) Error() string {
+	return fmt.Sprintf("account not settled: balance %.2f, held %.2f, %d pending withdrawals", e.Balance, e.HeldBalance, e.PendingWithdrawals)
+}
+
+type AccountRepository interface {
+	GetUserData(userID string) (*UserData, error)
+	AnonymizeUser(userID string) (string, error)
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db}
+}
+
+func (r *accountRepository) GetUserData(userID string) (*UserData, error) {
+	var data UserData
+	if err := r.db.First(&data.User, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	queries := []func() error{
+		func() error {
+			return r.db.Preload("Event").Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Orders).Error
+		},
+		func() error {
+			return r.db.Where("order_id IN (?)", r.db.Model(&models.Order{}).Select("id").Where("user_id = ?", userID)).
+				Find(&data.OrderDetails).Error
+		},
+		func() error {
+			return r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Payments).Error
+		},
+		func() error {
+			return r.db.Preload("Ticket").Preload("Event").Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Tickets).Error
+		},
+		func() error {
+			return r.db.Preload("PayoutAccount", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+				Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+				Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Withdrawals).Error
+		},
+		func() error {
+			return r.db.Unscoped().Where("user_id = ?", userID).Order("created_at ASC").Find(&data.PayoutAccounts).Error
+		},
+		func() error {
+			return r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Sessions).Error
+		},
+		func() error {
+			return r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.LinkedIdentities).Error
+		},
+		func() error {
+			return r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.AuditLogs).Error
+		},
+	}
+
+	for _, query := range queries {
+		if err := query(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &data, nil
+}
+
+// AnonymizeUser strips personal fields while keeping orders, payments and withdrawals for the books,
+// the user row itself is soft deleted so it can no longer sign in. It refuses with an
+// *AccountNotSettledError while money is still owed to the user, checked under the user row lock so
+// no order or withdrawal can slip in before the deletion commits. The public ID of the removed
+// identity document is returned so the caller can delete the upload once the transaction is done
+func (r *accountRepository) AnonymizeUser(userID string) (string, error) {
+	placeholderName := "Deleted User"
+	placeholderEmail := "deleted+" + userID + "@deleted.invalid"
+	var documentID string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		unsettled := AccountNotSettledError{Balance: user.Balance}
+		if err := tx.Model(&models.BalanceHold{}).
+			Where("user_id = ? AND status = ?", userID, "held").
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&unsettled.HeldBalance).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.WithdrawalRequest{}).
+			Where("user_id = ? AND status IN ?", userID, []string{"pending", "approved"}).
+			Count(&unsettled.PendingWithdrawals).Error; err != nil {
+			return err
+		}
+		if unsettled.Balance > 0 || unsettled.HeldBalance > 0 || unsettled.PendingWithdrawals > 0 {
+			return &unsettled
+		}
+
+		if err := tx.Model(&models.Order{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"fullname": placeholderName, "email": placeholderEmail, "phone": ""}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Payment{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"fullname": placeholderName, "email": placeholderEmail}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.PayoutAccount{}).Unscoped().Where("user_id = ?", userID).
+			Updates(map[string]any{"holder_name": placeholderName, "account_number": "", "deleted_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.UserSession{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"ip": "", "user_agent": "", "location": "", "device": ""}).Error; err != nil {
+			return err
+		}
+
+		// the trail keeps who did what, the snapshots of the user's data go, including those of staff
+		// acting on the account. The IP and user agent are only the user's on their own entries
+		if err := tx.Model(&models.AuditLog{}).Unscoped().Where("user_id = ?", userID).
+			Updates(map[string]any{"ip": "", "user_agent": ""}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AuditLog{}).Unscoped().Where("user_id = ? OR resource_id = ?", userID, userID).
+			Updates(map[string]any{"before": "", "after": "", "changes": "", "description": ""}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.IdentityVerification{}).Where("user_id = ?", userID).
+			Pluck("document_image", &documentID).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []any{&models.RecoveryCode{}, &models.LinkedIdentity{}, &models.IdentityVerification{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"fullname":           placeholderName,
+			"email":              placeholderEmail,
+			"password":           "",
+			"avatar":             "",
+			"two_factor_enabled": false,
+			"two_factor_secret":  "",
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", userID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return documentID, nil
+}
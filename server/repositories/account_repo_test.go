@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAnonymizeUserScrubsPersonalData(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Order{}, &models.Payment{}, &models.PayoutAccount{}, &models.AuditLog{},
+		&models.IdentityVerification{}, &models.RecoveryCode{}, &models.LinkedIdentity{}, &models.BalanceHold{},
+		&models.WithdrawalRequest{}, &models.UserSession{})
+	repo := NewAccountRepository(db)
+
+	user := models.User{ID: uuid.New(), Email: "jane@example.com", Fullname: "Jane", Password: "x"}
+	withdrawal := models.WithdrawalRequest{ID: uuid.New(), UserID: user.ID, Amount: 100, Status: "approved"}
+	for _, v := range []any{
+		&user, &withdrawal,
+		&models.PayoutAccount{ID: uuid.New(), UserID: user.ID, BankCode: "014", AccountNumber: "1234567890", HolderName: "Jane"},
+		&models.AuditLog{ID: uuid.NewString(), UserID: user.ID.String(), Action: "update", Resource: "profile", IP: "203.0.113.7", UserAgent: "Firefox",
+			Description: "Jane updated her profile", Before: `{"phone":"0800"}`, After: `{"phone":"0811"}`, Changes: `{"phone":["0800","0811"]}`},
+		&models.AuditLog{ID: uuid.NewString(), UserID: uuid.NewString(), Action: "update", Resource: "user", ResourceID: user.ID.String(),
+			IP: "198.51.100.1", UserAgent: "Chrome", After: `{"email":"jane@example.com"}`},
+		&models.UserSession{ID: uuid.New(), UserID: user.ID, Device: "iPhone", UserAgent: "Safari", IP: "203.0.113.7", Location: "Jakarta",
+			ExpiresAt: time.Now().Add(time.Hour), LastUsedAt: time.Now()},
+		&models.IdentityVerification{ID: uuid.New(), UserID: user.ID, FullName: "Jane", DocumentType: "ktp", DocumentNumber: "3171", DocumentID: "private/doc-1"},
+	} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a withdrawal still waiting for payout keeps the account
+	var unsettled *AccountNotSettledError
+	if _, err := repo.AnonymizeUser(user.ID.String()); !errors.As(err, &unsettled) || unsettled.PendingWithdrawals != 1 {
+		t.Fatalf("expected the pending withdrawal to block the deletion, got %v", err)
+	}
+	var identities int64
+	db.Model(&models.IdentityVerification{}).Where("user_id = ?", user.ID).Count(&identities)
+	if identities != 1 {
+		t.Fatal("expected nothing to be scrubbed while the account is not settled")
+	}
+
+	db.Model(&withdrawal).Update("status", "paid")
+	documentID, err := repo.AnonymizeUser(user.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if documentID != "private/doc-1" {
+		t.Fatalf("expected the identity document to be handed back for deletion, got %q", documentID)
+	}
+
+	var account models.PayoutAccount
+	db.Unscoped().First(&account, "user_id = ?", user.ID)
+	if account.AccountNumber != "" || account.HolderName == "Jane" {
+		t.Fatalf("expected the payout account to be scrubbed, got %+v", account)
+	}
+
+	var own, staff models.AuditLog
+	db.First(&own, "user_id = ?", user.ID.String())
+	db.First(&staff, "resource_id = ?", user.ID.String())
+	if own.IP != "" || own.UserAgent != "" || own.Description != "" || own.Before != "" || own.After != "" || own.Changes != "" {
+		t.Fatalf("expected the user's audit entry to be scrubbed, got %+v", own)
+	}
+	if staff.After != "" || staff.IP != "198.51.100.1" {
+		t.Fatalf("expected the staff entry to lose the user's data but keep the staff IP, got %+v", staff)
+	}
+
+	var session models.UserSession
+	db.First(&session, "user_id = ?", user.ID)
+	if session.IP != "" || session.UserAgent != "" || session.Location != "" || session.Device != "" {
+		t.Fatalf("expected the sessions to be scrubbed, got %+v", session)
+	}
+}
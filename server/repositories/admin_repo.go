@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
@@ -19,6 +20,32 @@ type AdminRepository interface {
 	GetPaymentReports(params dto.PaymentReportQueryParams) ([]models.Payment, int64, error)
 	GetRefundReports(params dto.RefundReportQueryParams) ([]models.Order, int64, error)
 	GetWithdrawalReports(params dto.WithdrawalReportQueryParams) ([]models.WithdrawalRequest, int64, error)
+	GetWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams) ([]models.WithdrawalPolicyViolation, int64, error)
+	GetOrganizerEarnings(params dto.OrganizerEarningQueryParams) ([]models.OrganizerEarning, int64, error)
+	GetOrganizerSummary(organizerID string) (*dto.OrganizerSummaryResponse, error)
+	GetDailyMetrics(from, to time.Time, offset string, params dto.AnalyticsQueryParams) ([]dto.AnalyticsPoint, error)
+	GetEventByID(id string) (*models.Event, error)
+	GetEventReport(eventID string, offset string) (*dto.EventReportResponse, error)
+	GetEventAttendees(eventID string, params dto.EventAttendeeQueryParams) ([]EventAttendee, int64, error)
+
+	StreamOrderReports(params dto.OrderReportQueryParams, fn func(dto.OrderReportResponse) error) error
+	StreamTicketSalesReports(params dto.TicketReportQueryParams, fn func(dto.TicketSalesReportResponse) error) error
+	StreamPaymentReports(params dto.PaymentReportQueryParams, fn func(dto.PaymentReportResponse) error) error
+	StreamRefundReports(params dto.RefundReportQueryParams, fn func(dto.RefundReportResponse) error) error
+	StreamWithdrawalReports(params dto.WithdrawalReportQueryParams, fn func(dto.WithdrawalReportResponse) error) error
+	StreamWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams, fn func(dto.WithdrawalViolationReportResponse) error) error
+	StreamOrganizerEarnings(params dto.OrganizerEarningQueryParams, fn func(dto.OrganizerEarningResponse) error) error
+	StreamEventAttendees(eventID string, params dto.EventAttendeeQueryParams, fn func(EventAttendee) error) error
+}
+
+// EventAttendee is one issued ticket of a paid order together with its holder
+type EventAttendee struct {
+	TicketID string
+	Name     string
+	Email    string
+	Phone    string
+	Tier     string
+	UsedAt   *time.Time
 }
 
 type adminRepository struct {
@@ -83,6 +110,17 @@ func (r *adminRepository) GetSummary() (*dto.SummaryReportResponse, error) {
 		return nil, err
 	}
 
+	// Get Organizers Summary
+	if err := r.db.Raw(`
+		SELECT 
+			COALESCE(SUM(platform_fee), 0) as platform_fees,
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_earnings,
+			COALESCE(SUM(CASE WHEN status = 'settled' THEN net_amount ELSE 0 END), 0) as settled_earnings
+		FROM organizer_earnings
+	`).Scan(&resp.Organizers).Error; err != nil {
+		return nil, err
+	}
+
 	// Get Withdrawals Summary
 	if err := r.db.Raw(`
 		SELECT 
@@ -125,6 +163,14 @@ func (r *adminRepository) GetAllEvents(params dto.EventQueryParams) ([]models.Ev
 		db = db.Where("date <= ?", params.EndDate)
 	}
 
+	if params.Category != "" && params.Category != "all" {
+		db = db.Where("category = ?", params.Category)
+	}
+
+	if params.OrganizerID != "" {
+		db = db.Where("organizer_id = ?", params.OrganizerID)
+	}
+
 	// Apply sorting
 	switch params.Sort {
 	case "date_asc":
@@ -179,6 +225,9 @@ func (r *adminRepository) GetAllUsers(params dto.UserQueryParams) ([]models.User
 	if params.Role != "" && params.Role != "all" {
 		db = db.Where("role = ?", params.Role)
 	}
+	if params.Status != "" {
+		db = db.Where("status = ?", params.Status)
+	}
 
 	switch params.Sort {
 	case "joined_asc":
@@ -212,50 +261,95 @@ func (r *adminRepository) GetOrderReports(params dto.OrderReportQueryParams) ([]
 	var orders []models.Order
 	var count int64
 
-	db := r.db.Model(&models.Order{}).Preload("Event")
+	db := r.orderReportsQuery(params)
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := db.Preload("Event").Limit(params.Limit).Offset(offset).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, count, nil
+}
+
+// StreamOrderReports feeds every order matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamOrderReports(params dto.OrderReportQueryParams, fn func(dto.OrderReportResponse) error) error {
+	db := r.orderReportsQuery(params).
+		Joins("LEFT JOIN events ON events.id = orders.event_id").
+		Select(`orders.id as order_id, orders.fullname, orders.email, COALESCE(events.title, '') as event_title,
+			orders.total_price, orders.status, orders.created_at`)
+
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) orderReportsQuery(params dto.OrderReportQueryParams) *gorm.DB {
+	db := r.db.Model(&models.Order{})
 
 	if params.Status != "" {
-		db = db.Where("status = ?", params.Status)
+		db = db.Where("orders.status = ?", params.Status)
 	}
 
 	if params.EventID != "" {
-		db = db.Where("event_id = ?", params.EventID)
+		db = db.Where("orders.event_id = ?", params.EventID)
+	}
+
+	if params.OrganizerID != "" {
+		db = db.Where("orders.event_id IN (?)", r.db.Model(&models.Event{}).Select("id").Where("organizer_id = ?", params.OrganizerID))
 	}
 
 	if params.DateFrom != "" {
 		if fromDate, err := time.Parse("2006-01-02", params.DateFrom); err == nil {
-			db = db.Where("created_at >= ?", fromDate)
+			db = db.Where("orders.created_at >= ?", fromDate)
 		}
 	}
 	if params.DateTo != "" {
 		if toDate, err := time.Parse("2006-01-02", params.DateTo); err == nil {
 			toDate = toDate.Add(24 * time.Hour)
-			db = db.Where("created_at < ?", toDate)
+			db = db.Where("orders.created_at < ?", toDate)
 		}
 	}
 
 	if params.Q != "" {
 		like := "%" + params.Q + "%"
-		db = db.Where("fullname LIKE ? OR email LIKE ?", like, like)
+		db = db.Where("orders.fullname LIKE ? OR orders.email LIKE ?", like, like)
 	}
 
-	db = db.Order("created_at DESC")
+	return db.Order("orders.created_at DESC")
+}
+
+func (r *adminRepository) GetPaymentReports(params dto.PaymentReportQueryParams) ([]models.Payment, int64, error) {
+	var payments []models.Payment
+	var count int64
+
+	db := r.paymentReportsQuery(params)
 
 	if err := db.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
-
 	offset := (params.Page - 1) * params.Limit
-	if err := db.Limit(params.Limit).Offset(offset).Find(&orders).Error; err != nil {
+	if err := db.Preload("Order").
+		Limit(params.Limit).
+		Offset(offset).
+		Find(&payments).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return orders, count, nil
+	return payments, count, nil
 }
 
-func (r *adminRepository) GetPaymentReports(params dto.PaymentReportQueryParams) ([]models.Payment, int64, error) {
-	var payments []models.Payment
-	var count int64
+// StreamPaymentReports feeds every payment matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamPaymentReports(params dto.PaymentReportQueryParams, fn func(dto.PaymentReportResponse) error) error {
+	db := r.paymentReportsQuery(params).
+		Select(`payments.id as payment_id, payments.order_id, payments.fullname, payments.email, payments.method,
+			payments.amount, payments.status, payments.paid_at`)
+
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) paymentReportsQuery(params dto.PaymentReportQueryParams) *gorm.DB {
 	db := r.db.Model(&models.Payment{}).Joins("JOIN orders ON payments.order_id = orders.id")
 
 	if params.Status != "" {
@@ -264,30 +358,49 @@ func (r *adminRepository) GetPaymentReports(params dto.PaymentReportQueryParams)
 	if params.Method != "" {
 		db = db.Where("payments.method = ?", params.Method)
 	}
+
+	if params.OrganizerID != "" {
+		db = db.Where("orders.event_id IN (?)", r.db.Model(&models.Event{}).Select("id").Where("organizer_id = ?", params.OrganizerID))
+	}
 	if params.Q != "" {
 		q := "%" + params.Q + "%"
 		db = db.Where("orders.fullname LIKE ? OR orders.email LIKE ?", q, q)
 	}
 
+	return db.Order("payments.created_at DESC")
+}
+
+func (r *adminRepository) GetTicketSalesReports(params dto.TicketReportQueryParams) ([]models.Ticket, int64, error) {
+	var tickets []models.Ticket
+	var count int64
+
+	db := r.ticketSalesReportsQuery(params)
+
 	if err := db.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
+
 	offset := (params.Page - 1) * params.Limit
-	if err := db.Preload("Order").
-		Order("payments.created_at DESC").
+	if err := db.Preload("Event").
 		Limit(params.Limit).
 		Offset(offset).
-		Find(&payments).Error; err != nil {
+		Find(&tickets).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return payments, count, nil
+	return tickets, count, nil
 }
 
-func (r *adminRepository) GetTicketSalesReports(params dto.TicketReportQueryParams) ([]models.Ticket, int64, error) {
-	var tickets []models.Ticket
-	var count int64
+// StreamTicketSalesReports feeds every ticket type matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamTicketSalesReports(params dto.TicketReportQueryParams, fn func(dto.TicketSalesReportResponse) error) error {
+	db := r.ticketSalesReportsQuery(params).
+		Select(`events.title as event_title, tickets.name as ticket_name, tickets.price as ticket_price,
+			tickets.quota, tickets.sold, tickets.quota - tickets.sold as remaining`)
+
+	return streamRows(db, fn)
+}
 
+func (r *adminRepository) ticketSalesReportsQuery(params dto.TicketReportQueryParams) *gorm.DB {
 	db := r.db.Model(&models.Ticket{}).Joins("JOIN events ON tickets.event_id = events.id")
 
 	if params.Q != "" {
@@ -295,68 +408,537 @@ func (r *adminRepository) GetTicketSalesReports(params dto.TicketReportQueryPara
 		db = db.Where("events.title LIKE ? OR tickets.name LIKE ?", like, like)
 	}
 
+	if params.OrganizerID != "" {
+		db = db.Where("events.organizer_id = ?", params.OrganizerID)
+	}
+
+	return db.Order("events.created_at DESC")
+}
+
+func (r *adminRepository) GetRefundReports(params dto.RefundReportQueryParams) ([]models.Order, int64, error) {
+	var orders []models.Order
+	var count int64
+
+	db := r.refundReportsQuery(params)
+
 	if err := db.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
-
 	offset := (params.Page - 1) * params.Limit
 	if err := db.Preload("Event").
-		Order("events.created_at DESC").
 		Limit(params.Limit).
 		Offset(offset).
-		Find(&tickets).Error; err != nil {
+		Find(&orders).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return tickets, count, nil
+	return orders, count, nil
 }
 
-func (r *adminRepository) GetRefundReports(params dto.RefundReportQueryParams) ([]models.Order, int64, error) {
-	var orders []models.Order
-	var count int64
-	db := r.db.Model(&models.Order{}).Preload("Event").Where("is_refunded = ?", true)
+// StreamRefundReports feeds every refunded order matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamRefundReports(params dto.RefundReportQueryParams, fn func(dto.RefundReportResponse) error) error {
+	db := r.refundReportsQuery(params).
+		Joins("LEFT JOIN events ON events.id = orders.event_id").
+		Select(`orders.id as order_id, orders.fullname, orders.email, COALESCE(events.title, '') as event_title,
+			orders.refund_amount, COALESCE(orders.refund_reason, '') as refund_reason, orders.refunded_at`)
+
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) refundReportsQuery(params dto.RefundReportQueryParams) *gorm.DB {
+	db := r.db.Model(&models.Order{}).Where("orders.is_refunded = ?", true)
 
 	if params.Q != "" {
 		q := "%" + params.Q + "%"
-		db = db.Where("fullname LIKE ? OR email LIKE ?", q, q)
+		db = db.Where("orders.fullname LIKE ? OR orders.email LIKE ?", q, q)
 	}
 
+	if params.OrganizerID != "" {
+		db = db.Where("orders.event_id IN (?)", r.db.Model(&models.Event{}).Select("id").Where("organizer_id = ?", params.OrganizerID))
+	}
+
+	return db.Order("orders.refunded_at DESC")
+}
+
+func (r *adminRepository) GetWithdrawalReports(params dto.WithdrawalReportQueryParams) ([]models.WithdrawalRequest, int64, error) {
+	var withdrawals []models.WithdrawalRequest
+	var count int64
+
+	db := r.withdrawalReportsQuery(params)
+
 	if err := db.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
+
 	offset := (params.Page - 1) * params.Limit
-	if err := db.Order("refunded_at DESC").
-		Limit(params.Limit).
-		Offset(offset).
-		Find(&orders).Error; err != nil {
+	if err := db.Preload("User").Limit(params.Limit).Offset(offset).Find(&withdrawals).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return orders, count, nil
+	return withdrawals, count, nil
 }
 
-func (r *adminRepository) GetWithdrawalReports(params dto.WithdrawalReportQueryParams) ([]models.WithdrawalRequest, int64, error) {
-	var withdrawals []models.WithdrawalRequest
-	var count int64
+// StreamWithdrawalReports feeds every withdrawal matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamWithdrawalReports(params dto.WithdrawalReportQueryParams, fn func(dto.WithdrawalReportResponse) error) error {
+	db := r.withdrawalReportsQuery(params).
+		Select(`withdrawal_requests.id as withdrawal_id, withdrawal_requests.user_id, users.fullname, users.email,
+			withdrawal_requests.amount, withdrawal_requests.fee, withdrawal_requests.status, withdrawal_requests.reason,
+			withdrawal_requests.created_at, withdrawal_requests.approved_at`)
 
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) withdrawalReportsQuery(params dto.WithdrawalReportQueryParams) *gorm.DB {
 	db := r.db.Model(&models.WithdrawalRequest{}).
-		Joins("JOIN users ON withdrawal_requests.user_id = users.id").
-		Preload("User").
-		Order("created_at DESC")
+		Joins("JOIN users ON withdrawal_requests.user_id = users.id")
 
 	if params.Q != "" {
 		q := "%" + params.Q + "%"
 		db = db.Where("users.fullname LIKE ? OR users.email LIKE ?", q, q)
 	}
 
+	return db.Order("withdrawal_requests.created_at DESC")
+}
+
+func (r *adminRepository) GetWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams) ([]models.WithdrawalPolicyViolation, int64, error) {
+	var violations []models.WithdrawalPolicyViolation
+	var count int64
+
+	db := r.withdrawalViolationReportsQuery(params)
+
 	if err := db.Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (params.Page - 1) * params.Limit
-	if err := db.Limit(params.Limit).Offset(offset).Find(&withdrawals).Error; err != nil {
+	if err := db.Preload("User").Limit(params.Limit).Offset(offset).Find(&violations).Error; err != nil {
 		return nil, 0, err
 	}
 
-	return withdrawals, count, nil
+	return violations, count, nil
+}
+
+// StreamWithdrawalViolationReports feeds every violation matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams, fn func(dto.WithdrawalViolationReportResponse) error) error {
+	db := r.withdrawalViolationReportsQuery(params).
+		Select(`withdrawal_policy_violations.id as violation_id, withdrawal_policy_violations.user_id, users.fullname, users.email,
+			withdrawal_policy_violations.rule, withdrawal_policy_violations.amount, withdrawal_policy_violations.message,
+			withdrawal_policy_violations.created_at`)
+
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) withdrawalViolationReportsQuery(params dto.WithdrawalViolationQueryParams) *gorm.DB {
+	db := r.db.Model(&models.WithdrawalPolicyViolation{}).
+		Joins("JOIN users ON withdrawal_policy_violations.user_id = users.id")
+
+	if params.Q != "" {
+		q := "%" + params.Q + "%"
+		db = db.Where("users.fullname LIKE ? OR users.email LIKE ?", q, q)
+	}
+
+	if params.Rule != "" {
+		db = db.Where("withdrawal_policy_violations.rule = ?", params.Rule)
+	}
+
+	return db.Order("withdrawal_policy_violations.created_at DESC")
+}
+
+func (r *adminRepository) GetOrganizerEarnings(params dto.OrganizerEarningQueryParams) ([]models.OrganizerEarning, int64, error) {
+	var earnings []models.OrganizerEarning
+	var count int64
+
+	db := r.organizerEarningsQuery(params)
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := db.Preload("Event").Preload("Organizer").Limit(params.Limit).Offset(offset).Find(&earnings).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return earnings, count, nil
+}
+
+// StreamOrganizerEarnings feeds every earning matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamOrganizerEarnings(params dto.OrganizerEarningQueryParams, fn func(dto.OrganizerEarningResponse) error) error {
+	db := r.organizerEarningsQuery(params).
+		Joins("LEFT JOIN users ON users.id = organizer_earnings.organizer_id").
+		Select(`organizer_earnings.id as earning_id, organizer_earnings.organizer_id, COALESCE(users.fullname, '') as organizer_name,
+			events.title as event_title, organizer_earnings.order_id, organizer_earnings.gross_amount, organizer_earnings.refunded_amount,
+			organizer_earnings.fee_percent, organizer_earnings.platform_fee, organizer_earnings.net_amount, organizer_earnings.status,
+			organizer_earnings.settled_at, organizer_earnings.created_at`)
+
+	return streamRows(db, fn)
+}
+
+func (r *adminRepository) organizerEarningsQuery(params dto.OrganizerEarningQueryParams) *gorm.DB {
+	db := r.db.Model(&models.OrganizerEarning{}).
+		Joins("JOIN events ON organizer_earnings.event_id = events.id")
+
+	if params.Q != "" {
+		db = db.Where("events.title LIKE ?", "%"+params.Q+"%")
+	}
+
+	if params.Status != "" {
+		db = db.Where("organizer_earnings.status = ?", params.Status)
+	}
+
+	if params.EventID != "" {
+		db = db.Where("organizer_earnings.event_id = ?", params.EventID)
+	}
+
+	if params.OrganizerID != "" {
+		db = db.Where("organizer_earnings.organizer_id = ?", params.OrganizerID)
+	}
+
+	return db.Order("organizer_earnings.created_at DESC")
+}
+
+func (r *adminRepository) GetOrganizerSummary(organizerID string) (*dto.OrganizerSummaryResponse, error) {
+	var resp dto.OrganizerSummaryResponse
+
+	if err := r.db.Raw(`
+		SELECT 
+			COUNT(*) as paid_orders,
+			COALESCE(SUM(gross_amount), 0) as gross_sales,
+			COALESCE(SUM(refunded_amount), 0) as refunded_amount,
+			COALESCE(SUM(platform_fee), 0) as platform_fees,
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_earnings,
+			COALESCE(SUM(CASE WHEN status = 'settled' THEN net_amount ELSE 0 END), 0) as settled_earnings
+		FROM organizer_earnings
+		WHERE organizer_id = ?
+	`, organizerID).Scan(&resp).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.Event{}).
+		Select("COUNT(*)").
+		Where("organizer_id = ?", organizerID).
+		Scan(&resp.Events).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Model(&models.User{}).
+		Select("balance").
+		Where("id = ?", organizerID).
+		Scan(&resp.Balance).Error; err != nil {
+		return nil, err
+	}
+
+	resp.OrganizerID = organizerID
+	return &resp, nil
+}
+
+// GetDailyMetrics buckets sales, refunds and sign-ups of [from, to) by local date, offset is the fixed UTC
+// offset of the whole range. Orders count on the day they were placed, refunds on the day they were refunded
+func (r *adminRepository) GetDailyMetrics(from, to time.Time, offset string, params dto.AnalyticsQueryParams) ([]dto.AnalyticsPoint, error) {
+	days := map[string]*dto.AnalyticsPoint{}
+	day := func(period string) *dto.AnalyticsPoint {
+		if days[period] == nil {
+			days[period] = &dto.AnalyticsPoint{Period: period}
+		}
+		return days[period]
+	}
+
+	// orders of the filtered events, the column decides which timestamp is bucketed
+	orders := func(column string) *gorm.DB {
+		db := r.db.Table("orders").
+			Joins("JOIN events ON events.id = orders.event_id").
+			Where("orders."+column+" >= ? AND orders."+column+" < ?", from.UTC(), to.UTC())
+
+		if params.EventID != "" {
+			db = db.Where("orders.event_id = ?", params.EventID)
+		}
+		if params.Category != "" && params.Category != "all" {
+			db = db.Where("events.category = ?", params.Category)
+		}
+		if params.OrganizerID != "" {
+			db = db.Where("events.organizer_id = ?", params.OrganizerID)
+		}
+		return db
+	}
+	localDate := func(column string) string {
+		return "DATE_FORMAT(CONVERT_TZ(" + column + ", '+00:00', '" + offset + "'), '%Y-%m-%d')"
+	}
+
+	var sales []struct {
+		Period  string
+		Orders  int
+		Revenue float64
+	}
+	if err := orders("created_at").
+		Select(localDate("orders.created_at")+" AS period, COUNT(*) AS orders, COALESCE(SUM(orders.total_price), 0) AS revenue").
+		Where("orders.status IN ?", []string{"paid", "refunded"}).
+		Group("period").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range sales {
+		day(row.Period).Orders = row.Orders
+		day(row.Period).Revenue = row.Revenue
+	}
+
+	var tickets []struct {
+		Period      string
+		TicketsSold int
+	}
+	if err := orders("created_at").
+		Joins("JOIN order_details ON order_details.order_id = orders.id").
+		Select(localDate("orders.created_at")+" AS period, COALESCE(SUM(order_details.quantity), 0) AS tickets_sold").
+		Where("orders.status IN ?", []string{"paid", "refunded"}).
+		Group("period").
+		Scan(&tickets).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range tickets {
+		day(row.Period).TicketsSold = row.TicketsSold
+	}
+
+	var refunds []struct {
+		Period       string
+		Refunds      int
+		RefundAmount float64
+	}
+	if err := orders("refunded_at").
+		Select(localDate("orders.refunded_at") + " AS period, COUNT(*) AS refunds, COALESCE(SUM(orders.refund_amount), 0) AS refund_amount").
+		Group("period").
+		Scan(&refunds).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range refunds {
+		day(row.Period).Refunds = row.Refunds
+		day(row.Period).RefundAmount = row.RefundAmount
+	}
+
+	// sign-ups belong to the platform, they are left out of organizer scoped series
+	if params.OrganizerID == "" {
+		var users []struct {
+			Period   string
+			NewUsers int
+		}
+		if err := r.db.Table("users").
+			Select(localDate("created_at")+" AS period, COUNT(*) AS new_users").
+			Where("created_at >= ? AND created_at < ?", from.UTC(), to.UTC()).
+			Group("period").
+			Scan(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range users {
+			day(row.Period).NewUsers = row.NewUsers
+		}
+	}
+
+	result := make([]dto.AnalyticsPoint, 0, len(days))
+	for _, point := range days {
+		result = append(result, *point)
+	}
+	return result, nil
+}
+
+func (r *adminRepository) GetEventByID(id string) (*models.Event, error) {
+	var event models.Event
+	if err := r.db.Preload("Tickets").First(&event, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// attendeeTickets are the issued tickets of the event whose own order is still paid. Tickets issued before
+// they were linked to their order fall back to the holder having a paid order for the same event
+func (r *adminRepository) attendeeTickets(eventID string) *gorm.DB {
+	return r.db.Table("user_tickets").
+		Where("user_tickets.event_id = ?", eventID).
+		Where(`(user_tickets.order_id IS NOT NULL AND EXISTS (SELECT 1 FROM orders WHERE orders.id = user_tickets.order_id AND orders.status = 'paid'))
+			OR (user_tickets.order_id IS NULL AND EXISTS (SELECT 1 FROM orders WHERE orders.user_id = user_tickets.user_id AND orders.event_id = user_tickets.event_id AND orders.status = 'paid'))`)
+}
+
+// GetEventReport loads the sales, refunds and check-ins of one event, offset is the fixed UTC offset the
+// dates and hours are bucketed in. Derived figures such as the check-in rate are left to the caller
+func (r *adminRepository) GetEventReport(eventID string, offset string) (*dto.EventReportResponse, error) {
+	var resp dto.EventReportResponse
+	localTime := func(column, layout string) string {
+		return "DATE_FORMAT(CONVERT_TZ(" + column + ", '+00:00', '" + offset + "'), '" + layout + "')"
+	}
+
+	if err := r.db.Raw(`
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'paid' THEN 1 ELSE 0 END), 0) as paid_orders,
+			COALESCE(SUM(CASE WHEN status = 'refunded' THEN 1 ELSE 0 END), 0) as refunded_orders,
+			COALESCE(SUM(total_price), 0) as gross_revenue,
+			COALESCE(SUM(refund_amount), 0) as refunded_amount
+		FROM orders
+		WHERE event_id = ? AND status IN ('paid', 'refunded')
+	`, eventID).Scan(&resp.Summary).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.attendeeTickets(eventID).
+		Select("COUNT(*) as tickets_sold, COALESCE(SUM(CASE WHEN user_tickets.is_used THEN 1 ELSE 0 END), 0) as checked_in").
+		Scan(&resp.Summary).Error; err != nil {
+		return nil, err
+	}
+
+	// a refund is spread over the tiers of its order by their share of the order total
+	if err := r.db.Raw(`
+		SELECT
+			tickets.id as ticket_id,
+			tickets.name,
+			tickets.price,
+			tickets.quota,
+			COALESCE(SUM(CASE WHEN orders.status = 'paid' THEN order_details.quantity ELSE 0 END), 0) as sold,
+			COALESCE(SUM(order_details.quantity * order_details.price), 0) as revenue,
+			COALESCE(SUM(CASE WHEN orders.total_price > 0
+				THEN orders.refund_amount * order_details.quantity * order_details.price / orders.total_price
+				ELSE 0 END), 0) as refunded_amount
+		FROM tickets
+		LEFT JOIN (order_details
+			JOIN orders ON orders.id = order_details.order_id AND orders.status IN ('paid', 'refunded')
+		) ON order_details.ticket_id = tickets.id
+		WHERE tickets.event_id = ?
+		GROUP BY tickets.id, tickets.name, tickets.price, tickets.quota
+		ORDER BY tickets.price ASC
+	`, eventID).Scan(&resp.RevenueByTier).Error; err != nil {
+		return nil, err
+	}
+
+	var checkedIn []struct {
+		TicketID  string
+		CheckedIn int
+	}
+	if err := r.attendeeTickets(eventID).
+		Select("user_tickets.ticket_id, COUNT(*) as checked_in").
+		Where("user_tickets.is_used = ?", true).
+		Group("user_tickets.ticket_id").
+		Scan(&checkedIn).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range checkedIn {
+		for i := range resp.RevenueByTier {
+			if resp.RevenueByTier[i].TicketID == row.TicketID {
+				resp.RevenueByTier[i].CheckedIn = row.CheckedIn
+			}
+		}
+	}
+
+	// orders count on the day they were placed, refunds on the day they were refunded
+	days := map[string]*dto.EventOrdersPoint{}
+	day := func(date string) *dto.EventOrdersPoint {
+		if days[date] == nil {
+			days[date] = &dto.EventOrdersPoint{Date: date}
+		}
+		return days[date]
+	}
+
+	var sales []struct {
+		Date    string
+		Orders  int
+		Revenue float64
+	}
+	if err := r.db.Table("orders").
+		Select(localTime("created_at", "%Y-%m-%d")+" as date, COUNT(*) as orders, COALESCE(SUM(total_price), 0) as revenue").
+		Where("event_id = ? AND status IN ?", eventID, []string{"paid", "refunded"}).
+		Group("date").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range sales {
+		day(row.Date).Orders = row.Orders
+		day(row.Date).Revenue = row.Revenue
+	}
+
+	var refunds []struct {
+		Date         string
+		Refunds      int
+		RefundAmount float64
+	}
+	if err := r.db.Table("orders").
+		Select(localTime("refunded_at", "%Y-%m-%d")+" as date, COUNT(*) as refunds, COALESCE(SUM(refund_amount), 0) as refund_amount").
+		Where("event_id = ? AND refunded_at IS NOT NULL", eventID).
+		Group("date").
+		Scan(&refunds).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range refunds {
+		day(row.Date).Refunds = row.Refunds
+		day(row.Date).RefundAmount = row.RefundAmount
+	}
+
+	resp.OrdersOverTime = make([]dto.EventOrdersPoint, 0, len(days))
+	for _, point := range days {
+		resp.OrdersOverTime = append(resp.OrdersOverTime, *point)
+	}
+
+	if err := r.attendeeTickets(eventID).
+		Select(localTime("user_tickets.used_at", "%Y-%m-%d %H:00") + " as hour, COUNT(*) as check_ins").
+		Where("user_tickets.used_at IS NOT NULL").
+		Group("hour").
+		Order("hour ASC").
+		Scan(&resp.CheckInsByHour).Error; err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (r *adminRepository) GetEventAttendees(eventID string, params dto.EventAttendeeQueryParams) ([]EventAttendee, int64, error) {
+	var attendees []EventAttendee
+	var count int64
+
+	db := r.attendeesQuery(eventID, params)
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := r.selectAttendees(db).Limit(params.Limit).Offset(offset).Scan(&attendees).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return attendees, count, nil
+}
+
+// StreamEventAttendees feeds the whole attendee manifest matching the filters to fn, ignoring the pagination
+func (r *adminRepository) StreamEventAttendees(eventID string, params dto.EventAttendeeQueryParams, fn func(EventAttendee) error) error {
+	return streamRows(r.selectAttendees(r.attendeesQuery(eventID, params)), fn)
+}
+
+func (r *adminRepository) attendeesQuery(eventID string, params dto.EventAttendeeQueryParams) *gorm.DB {
+	db := r.attendeeTickets(eventID).
+		Joins("JOIN users ON users.id = user_tickets.user_id").
+		Joins("JOIN tickets ON tickets.id = user_tickets.ticket_id")
+
+	if params.Q != "" {
+		q := "%" + params.Q + "%"
+		db = db.Where("users.fullname LIKE ? OR users.email LIKE ? OR user_tickets.id LIKE ?", q, q, q)
+	}
+	if params.TicketID != "" {
+		db = db.Where("user_tickets.ticket_id = ?", params.TicketID)
+	}
+	if params.CheckedIn != "" {
+		db = db.Where("user_tickets.is_used = ?", params.CheckedIn == "true")
+	}
+
+	return db
+}
+
+// selectAttendees picks the manifest columns, the phone number is only kept on the order so the latest
+// paid one wins
+func (r *adminRepository) selectAttendees(db *gorm.DB) *gorm.DB {
+	return db.Select(`
+		user_tickets.id as ticket_id,
+		users.fullname as name,
+		users.email,
+		COALESCE((SELECT orders.phone FROM orders
+			WHERE orders.user_id = user_tickets.user_id AND orders.event_id = user_tickets.event_id AND orders.status = 'paid'
+			ORDER BY orders.created_at DESC LIMIT 1), '') as phone,
+		tickets.name as tier,
+		user_tickets.used_at
+	`).Order("users.fullname ASC, user_tickets.id ASC")
 }
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAttendeeTicketsFollowTheirOwnOrder(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	if err := NewPaymentRepository(db).FulfillOrder(f.order.ID.String(), f.card.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second order of the same buyer for the same event, refunded after its ticket was issued
+	refunded := models.Order{ID: uuid.New(), UserID: f.buyer.ID, EventID: f.order.EventID, Fullname: "Buyer", Email: "buyer@example.com",
+		Phone: "0800", TotalPrice: 50, Status: "refunded"}
+	for _, v := range []any{&refunded, &models.UserTicket{ID: uuid.New(), UserID: f.buyer.ID, EventID: f.order.EventID,
+		TicketID: f.ticket.ID, OrderID: &refunded.ID, QRCode: "TICKET-refunded"}} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var attendees int64
+	repo := &adminRepository{db: db}
+	if err := repo.attendeeTickets(f.order.EventID.String()).Count(&attendees).Error; err != nil {
+		t.Fatal(err)
+	}
+	if attendees != 2 {
+		t.Fatalf("expected only the two tickets of the paid order, got %d", attendees)
+	}
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+)
+
+type APIClientRepository interface {
+	CreateClient(data *models.APIClient) error
+	UpdateClient(data *models.APIClient) error
+	GetClientByID(id string) (*models.APIClient, error)
+	GetClientByKeyHash(hash string) (*models.APIClient, error)
+	GetAllClients(includeRevoked bool) ([]models.APIClient, error)
+	TouchClient(id string, ip string) error
+}
+
+type apiClientRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIClientRepository(db *gorm.DB) APIClientRepository {
+	return &apiClientRepository{db}
+}
+
+func (r *apiClientRepository) CreateClient(data *models.APIClient) error {
+	return r.db.Create(data).Error
+}
+
+func (r *apiClientRepository) UpdateClient(data *models.APIClient) error {
+	return r.db.Save(data).Error
+}
+
+func (r *apiClientRepository) GetClientByID(id string) (*models.APIClient, error) {
+	var client models.APIClient
+	err := r.db.First(&client, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &client, err
+}
+
+func (r *apiClientRepository) GetClientByKeyHash(hash string) (*models.APIClient, error) {
+	var client models.APIClient
+	err := r.db.First(&client, "key_hash = ?", hash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &client, err
+}
+
+func (r *apiClientRepository) GetAllClients(includeRevoked bool) ([]models.APIClient, error) {
+	var clients []models.APIClient
+	query := r.db.Order("created_at DESC")
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+	err := query.Find(&clients).Error
+	return clients, err
+}
+
+// TouchClient records the last use, it skips the write when the key was already seen in the last minute
+func (r *apiClientRepository) TouchClient(id string, ip string) error {
+	now := time.Now()
+	return r.db.Model(&models.APIClient{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-time.Minute)).
+		Updates(map[string]any{"last_used_at": now, "last_used_ip": ip}).Error
+}
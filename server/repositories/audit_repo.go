@@ -2,14 +2,20 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"gorm.io/gorm"
 )
 
 type AuditLogRepository interface {
 	Create(ctx context.Context, log *models.AuditLog) error
+	GetAuditLogs(params dto.AuditLogQueryParams) ([]dto.AuditLogResponse, int64, error)
+	StreamAuditLogs(params dto.AuditLogQueryParams, fn func(dto.AuditLogResponse) error) error
+	GetAuditLogByID(id string) (*models.AuditLog, error)
+	DeleteOlderThan(before time.Time) (int64, error)
 }
 
 type auditLogRepository struct {
@@ -24,3 +30,87 @@ func (r *auditLogRepository) Create(ctx context.Context, log *models.AuditLog) e
 	log.CreatedAt = time.Now()
 	return r.db.WithContext(ctx).Create(log).Error
 }
+
+func (r *auditLogRepository) GetAuditLogs(params dto.AuditLogQueryParams) ([]dto.AuditLogResponse, int64, error) {
+	var logs []dto.AuditLogResponse
+	var count int64
+
+	db := r.auditLogsQuery(params)
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := selectAuditLogs(db).Limit(params.Limit).Offset(offset).Scan(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, count, nil
+}
+
+// StreamAuditLogs feeds every entry matching the filters to fn, ignoring the pagination
+func (r *auditLogRepository) StreamAuditLogs(params dto.AuditLogQueryParams, fn func(dto.AuditLogResponse) error) error {
+	return streamRows(selectAuditLogs(r.auditLogsQuery(params)), fn)
+}
+
+func (r *auditLogRepository) auditLogsQuery(params dto.AuditLogQueryParams) *gorm.DB {
+	db := r.db.Model(&models.AuditLog{}).
+		Joins("LEFT JOIN users ON users.id = audit_logs.user_id")
+
+	if params.ActorID != "" {
+		db = db.Where("audit_logs.user_id = ?", params.ActorID)
+	}
+
+	if params.ImpersonatorID != "" {
+		db = db.Where("audit_logs.impersonator_id = ?", params.ImpersonatorID)
+	}
+
+	if params.Resource != "" {
+		db = db.Where("audit_logs.resource = ?", params.Resource)
+	}
+
+	if params.ResourceID != "" {
+		db = db.Where("audit_logs.resource_id = ?", params.ResourceID)
+	}
+
+	if params.Action != "" {
+		db = db.Where("audit_logs.action = ?", params.Action)
+	}
+
+	if params.DateFrom != "" {
+		if fromDate, err := time.Parse("2006-01-02", params.DateFrom); err == nil {
+			db = db.Where("audit_logs.created_at >= ?", fromDate)
+		}
+	}
+	if params.DateTo != "" {
+		if toDate, err := time.Parse("2006-01-02", params.DateTo); err == nil {
+			toDate = toDate.Add(24 * time.Hour)
+			db = db.Where("audit_logs.created_at < ?", toDate)
+		}
+	}
+
+	return db
+}
+
+func selectAuditLogs(db *gorm.DB) *gorm.DB {
+	return db.Select(`audit_logs.id, audit_logs.user_id AS actor_id, COALESCE(users.email, '') AS actor_email,
+		audit_logs.impersonator_id, audit_logs.action, audit_logs.resource, audit_logs.resource_id, audit_logs.method, audit_logs.path,
+		audit_logs.status_code, audit_logs.description, audit_logs.ip, audit_logs.created_at`).
+		Order("audit_logs.created_at DESC")
+}
+
+func (r *auditLogRepository) GetAuditLogByID(id string) (*models.AuditLog, error) {
+	var log models.AuditLog
+	err := r.db.First(&log, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &log, err
+}
+
+// DeleteOlderThan permanently removes entries past the retention period
+func (r *auditLogRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("created_at < ?", before).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// placeHold debits the user's balance and records the hold, the conditional
+// update keeps concurrent holds from driving the balance below zero
+func placeHold(tx *gorm.DB, hold *models.BalanceHold) error {
+	res := tx.Model(&models.User{}).
+		Where("id = ? AND balance >= ?", hold.UserID, hold.Amount).
+		Update("balance", gorm.Expr("balance - ?", hold.Amount))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+
+	hold.Status = "held"
+	return tx.Create(hold).Error
+}
+
+// captureHold finalizes held funds, the balance was already debited when the hold was placed
+func captureHold(tx *gorm.DB, source string, referenceID string) (int64, error) {
+	res := tx.Model(&models.BalanceHold{}).
+		Where("source = ? AND reference_id = ? AND status = ?", source, referenceID, "held").
+		Update("status", "captured")
+	return res.RowsAffected, res.Error
+}
+
+// releaseHold returns held funds to the user, holds that are already settled are left untouched
+func releaseHold(tx *gorm.DB, source string, referenceID string) error {
+	var holds []models.BalanceHold
+	if err := tx.Where("source = ? AND reference_id = ? AND status = ?", source, referenceID, "held").
+		Find(&holds).Error; err != nil {
+		return err
+	}
+
+	for _, h := range holds {
+		res := tx.Model(&models.BalanceHold{}).
+			Where("id = ? AND status = ?", h.ID, "held").
+			Update("status", "released")
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", h.UserID).
+			Update("balance", gorm.Expr("balance + ?", h.Amount)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -64,6 +64,10 @@ func (r *eventRepository) GetAllEvents(params dto.EventQueryParams) ([]models.Ev
 		db = db.Where("events.status = ?", params.Status)
 	}
 
+	if params.Category != "" && params.Category != "all" {
+		db = db.Where("events.category = ?", params.Category)
+	}
+
 	if params.StartDate != "" {
 		db = db.Where("events.date >= ?", params.StartDate)
 	}
@@ -72,6 +76,10 @@ func (r *eventRepository) GetAllEvents(params dto.EventQueryParams) ([]models.Ev
 		db = db.Where("events.date <= ?", params.EndDate)
 	}
 
+	if params.OrganizerID != "" {
+		db = db.Where("events.organizer_id = ?", params.OrganizerID)
+	}
+
 	switch params.Sort {
 	case "date_asc":
 		db = db.Order("events.date ASC")
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+)
+
+type ExportJobRepository interface {
+	Create(job *models.ExportJob) error
+	GetByID(id string) (*models.ExportJob, error)
+	ClaimNextPending() (*models.ExportJob, error)
+	UpdateProgress(id string, processed, total int) error
+	Update(job *models.ExportJob) error
+	RequeueStale(quietSince time.Time, maxAttempts int) (requeued int64, failed int64, err error)
+	GetExpired(now time.Time) ([]models.ExportJob, error)
+}
+
+type exportJobRepository struct {
+	db *gorm.DB
+}
+
+func NewExportJobRepository(db *gorm.DB) ExportJobRepository {
+	return &exportJobRepository{db}
+}
+
+func (r *exportJobRepository) Create(job *models.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *exportJobRepository) GetByID(id string) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := r.db.Preload("User").First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNextPending moves the oldest pending job to processing and returns it, nil when there is none.
+// The status check in the update keeps two workers from claiming the same job, every claim counts as an attempt
+func (r *exportJobRepository) ClaimNextPending() (*models.ExportJob, error) {
+	for {
+		var job models.ExportJob
+		err := r.db.Preload("User").
+			Where("status = ?", models.ExportJobPending).
+			Order("created_at ASC").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		res := r.db.Model(&models.ExportJob{}).
+			Where("id = ? AND status = ?", job.ID, models.ExportJobPending).
+			Updates(map[string]any{
+				"status":     models.ExportJobProcessing,
+				"started_at": now,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 1 {
+			job.Status = models.ExportJobProcessing
+			job.StartedAt = &now
+			job.Attempts++
+			job.UpdatedAt = now
+			return &job, nil
+		}
+	}
+}
+
+// UpdateProgress also serves as the heartbeat of a running job, RequeueStale only takes jobs that went quiet
+func (r *exportJobRepository) UpdateProgress(id string, processed, total int) error {
+	return r.db.Model(&models.ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"processed_rows": processed, "total_rows": total, "updated_at": time.Now()}).Error
+}
+
+func (r *exportJobRepository) Update(job *models.ExportJob) error {
+	return r.db.Omit("User").Save(job).Error
+}
+
+// RequeueStale puts jobs back in the queue whose worker stopped before finishing them, e.g. on a restart.
+// Only jobs without a heartbeat since quietSince are taken, the ones that used up their attempts fail
+// instead so a job that keeps killing its worker is not retried forever
+func (r *exportJobRepository) RequeueStale(quietSince time.Time, maxAttempts int) (int64, int64, error) {
+	now := time.Now()
+	failed := r.db.Model(&models.ExportJob{}).
+		Where("status = ? AND updated_at < ? AND attempts >= ?", models.ExportJobProcessing, quietSince, maxAttempts).
+		Updates(map[string]any{
+			"status":       models.ExportJobFailed,
+			"error":        "The export could not be generated, please try again",
+			"completed_at": now,
+			"updated_at":   now,
+		})
+	if failed.Error != nil {
+		return 0, 0, failed.Error
+	}
+
+	requeued := r.db.Model(&models.ExportJob{}).
+		Where("status = ? AND updated_at < ? AND attempts < ?", models.ExportJobProcessing, quietSince, maxAttempts).
+		Updates(map[string]any{
+			"status":         models.ExportJobPending,
+			"processed_rows": 0,
+			"started_at":     nil,
+			"updated_at":     now,
+		})
+	return requeued.RowsAffected, failed.RowsAffected, requeued.Error
+}
+
+// GetExpired returns the completed jobs whose file has passed its expiry
+func (r *exportJobRepository) GetExpired(now time.Time) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	err := r.db.Where("status = ? AND expires_at <= ?", models.ExportJobCompleted, now).Find(&jobs).Error
+	return jobs, err
+}
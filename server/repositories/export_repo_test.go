@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+)
+
+func TestClaimNextPendingTakesEachJobOnce(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.ExportJob{})
+	repo := NewExportJobRepository(db)
+
+	user := models.User{ID: uuid.New(), Email: "admin@example.com", Fullname: "Admin", Password: "x"}
+	db.Create(&user)
+	older := models.ExportJob{UserID: user.ID, Report: "orders", Format: "csv", Status: models.ExportJobPending, CreatedAt: time.Now().Add(-time.Minute)}
+	newer := models.ExportJob{UserID: user.ID, Report: "payments", Format: "csv", Status: models.ExportJobPending}
+	db.Create(&older)
+	db.Create(&newer)
+
+	for _, want := range []uuid.UUID{older.ID, newer.ID} {
+		job, err := repo.ClaimNextPending()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job == nil || job.ID != want || job.Status != models.ExportJobProcessing || job.Attempts != 1 || job.User.Email != user.Email {
+			t.Fatalf("expected to claim %s once, got %+v", want, job)
+		}
+	}
+
+	job, err := repo.ClaimNextPending()
+	if err != nil || job != nil {
+		t.Fatalf("expected an empty queue, got %v %v", job, err)
+	}
+}
+
+func TestRequeueStaleSkipsRunningJobs(t *testing.T) {
+	db := newTestDB(t, &models.ExportJob{})
+	repo := NewExportJobRepository(db)
+
+	now := time.Now()
+	quiet := now.Add(-time.Hour)
+	lost := models.ExportJob{Report: "orders", Format: "csv", Status: models.ExportJobProcessing, Attempts: 1, ProcessedRows: 500}
+	running := models.ExportJob{Report: "orders", Format: "csv", Status: models.ExportJobProcessing, Attempts: 1}
+	exhausted := models.ExportJob{Report: "orders", Format: "csv", Status: models.ExportJobProcessing, Attempts: 3}
+	for _, job := range []*models.ExportJob{&lost, &running, &exhausted} {
+		if err := db.Create(job).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Model(&models.ExportJob{}).Where("id IN ?", []uuid.UUID{lost.ID, running.ID, exhausted.ID}).UpdateColumn("updated_at", quiet)
+
+	// the running job reports progress, the others stay quiet
+	if err := repo.UpdateProgress(running.ID.String(), 1000, 5000); err != nil {
+		t.Fatal(err)
+	}
+
+	requeued, failed, err := repo.RequeueStale(now.Add(-30*time.Minute), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued != 1 || failed != 1 {
+		t.Fatalf("expected one job requeued and one failed, got %d and %d", requeued, failed)
+	}
+
+	statuses := map[uuid.UUID]string{lost.ID: models.ExportJobPending, running.ID: models.ExportJobProcessing, exhausted.ID: models.ExportJobFailed}
+	for id, want := range statuses {
+		var job models.ExportJob
+		db.First(&job, "id = ?", id)
+		if job.Status != want {
+			t.Fatalf("job %s is %s, expected %s", id, job.Status, want)
+		}
+		if id == lost.ID && job.ProcessedRows != 0 {
+			t.Fatalf("expected the requeued job to start over, processed %d", job.ProcessedRows)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrderNotPending    = errors.New("order is no longer pending")
+	ErrOrderNotRefundable = errors.New("order is not refundable")
+)
+
+// fulfillOrder settles a pending order: the card payment and the wallet hold are marked paid, the ticket
+// sales counted, the user tickets issued and the organizer's share recorded. Callers run it in one
+// transaction so a failure leaves nothing half done. An order that was already paid is left alone,
+// which makes webhook retries safe
+func fulfillOrder(tx *gorm.DB, orderID string, cardPaymentID string) error {
+	res := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", orderID, "pending").
+		Update("status", "paid")
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var order models.Order
+		if err := tx.Select("status").First(&order, "id = ?", orderID).Error; err != nil {
+			return err
+		}
+		if order.Status == "paid" {
+			return nil
+		}
+		return fmt.Errorf("%w: order %s is %s", ErrOrderNotPending, orderID, order.Status)
+	}
+
+	now := time.Now().UTC()
+	if cardPaymentID != "" {
+		if err := tx.Model(&models.Payment{}).
+			Where("id = ?", cardPaymentID).
+			Updates(map[string]any{"method": "card", "status": "paid", "paid_at": now}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := captureOrderWallet(tx, orderID); err != nil {
+		return err
+	}
+
+	var order models.Order
+	if err := tx.First(&order, "id = ?", orderID).Error; err != nil {
+		return err
+	}
+
+	var details []models.OrderDetail
+	if err := tx.Where("order_id = ?", orderID).Find(&details).Error; err != nil {
+		return err
+	}
+	if len(details) == 0 {
+		return fmt.Errorf("order %s has no details", orderID)
+	}
+
+	for _, detail := range details {
+		if err := tx.Model(&models.Ticket{}).
+			Where("id = ?", detail.TicketID).
+			Update("sold", gorm.Expr("sold + ?", detail.Quantity)).Error; err != nil {
+			return err
+		}
+
+		var issued int64
+		if err := tx.Model(&models.UserTicket{}).Where("ticket_id = ?", detail.TicketID).Count(&issued).Error; err != nil {
+			return err
+		}
+
+		for i := range detail.Quantity {
+			userTicket := &models.UserTicket{
+				ID:       uuid.New(),
+				UserID:   order.UserID,
+				EventID:  order.EventID,
+				TicketID: detail.TicketID,
+				OrderID:  &order.ID,
+				QRCode:   fmt.Sprintf("TICKET-%s-%d", detail.TicketID.String(), issued+int64(i)+1),
+			}
+			if err := tx.Create(userTicket).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return recordSale(tx, orderID)
+}
+
+// failOrder fails a pending order with its pending payments and returns the wallet amount held for it.
+// It reports false and changes nothing when the order is no longer pending, e.g. paid in the meantime
+func failOrder(tx *gorm.DB, orderID string) (bool, error) {
+	res := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", orderID, "pending").
+		Update("status", "failed")
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+
+	if err := tx.Model(&models.Payment{}).
+		Where("order_id = ? AND status = ?", orderID, "pending").
+		Update("status", "failed").Error; err != nil {
+		return false, err
+	}
+
+	return true, releaseHold(tx, "order", orderID)
+}
+
+// reviveOrder fulfills a failed order after all, see PaymentRepository.FulfillExpiredOrder
+func reviveOrder(tx *gorm.DB, orderID string, cardPaymentID string) error {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+		return err
+	}
+	if order.Status != "failed" {
+		return fmt.Errorf("%w: order %s is %s", ErrOrderNotPending, orderID, order.Status)
+	}
+
+	if order.WalletAmount > 0 {
+		hold := &models.BalanceHold{UserID: order.UserID, Source: "order", ReferenceID: order.ID, Amount: order.WalletAmount}
+		if err := placeHold(tx, hold); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Payment{}).
+			Where("order_id = ? AND method = ? AND status = ?", orderID, "wallet", "failed").
+			Update("status", "pending").Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&order).Update("status", "pending").Error; err != nil {
+		return err
+	}
+
+	return fulfillOrder(tx, orderID, cardPaymentID)
+}
+
+// captureOrderWallet settles the wallet part of a paid order
+func captureOrderWallet(tx *gorm.DB, orderID string) error {
+	if _, err := captureHold(tx, "order", orderID); err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Payment{}).
+		Where("order_id = ? AND method = ? AND status = ?", orderID, "wallet", "pending").
+		Updates(map[string]any{
+			"status":  "paid",
+			"paid_at": time.Now().UTC(),
+		}).Error
+}
+
+// recordSale splits a paid order of an organizer's event, platform events and orders already recorded are skipped
+func recordSale(tx *gorm.DB, orderID string) error {
+	var order models.Order
+	if err := tx.Preload("Event").First(&order, "id = ?", orderID).Error; err != nil {
+		return err
+	}
+	if order.Event.OrganizerID == nil {
+		return nil
+	}
+
+	fee, net := splitSale(order.TotalPrice, order.Event.PlatformFeePercent)
+	earning := models.OrganizerEarning{
+		OrganizerID: *order.Event.OrganizerID,
+		EventID:     order.EventID,
+		OrderID:     order.ID,
+		GrossAmount: order.TotalPrice,
+		FeePercent:  order.Event.PlatformFeePercent,
+		PlatformFee: fee,
+		NetAmount:   net,
+		Status:      "pending",
+	}
+	return tx.Where(models.OrganizerEarning{OrderID: order.ID}).FirstOrCreate(&earning).Error
+}
+
+// refundOrder marks a paid order refunded, credits the buyer and shrinks the organizer's share. The
+// status check in the update keeps a refund from being paid twice
+func refundOrder(tx *gorm.DB, orderID string, amount float64, reason string, refundedAt time.Time) error {
+	var order models.Order
+	if err := tx.Select("id", "user_id").First(&order, "id = ?", orderID).Error; err != nil {
+		return err
+	}
+
+	res := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ? AND is_refunded = ?", orderID, "paid", false).
+		Updates(map[string]any{
+			"status":        "refunded",
+			"is_refunded":   true,
+			"refunded_at":   refundedAt,
+			"refund_reason": reason,
+			"refund_amount": amount,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrOrderNotRefundable
+	}
+
+	if err := tx.Model(&models.Payment{}).Where("order_id = ?", orderID).Update("status", "refunded").Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", order.UserID).
+		Update("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
+		return err
+	}
+
+	return adjustForRefund(tx, orderID, amount)
+}
+
+// adjustForRefund splits what is left of the order after the refund again. A share that was already
+// settled is taken back from the organizer's balance as far as it goes, the rest is owed and deducted
+// from the organizer's next settlements so the balance never goes below zero
+func adjustForRefund(tx *gorm.DB, orderID string, refundAmount float64) error {
+	var earning models.OrganizerEarning
+	err := tx.First(&earning, "order_id = ?", orderID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previousNet := earning.NetAmount
+	earning.RefundedAmount = math.Min(earning.GrossAmount, earning.RefundedAmount+refundAmount)
+	earning.PlatformFee, earning.NetAmount = splitSale(earning.GrossAmount-earning.RefundedAmount, earning.FeePercent)
+	if err := tx.Save(&earning).Error; err != nil {
+		return err
+	}
+
+	clawback := math.Round((previousNet-earning.NetAmount)*100) / 100
+	if earning.Status != "settled" || clawback <= 0 {
+		return nil
+	}
+
+	var organizer models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "balance").
+		First(&organizer, "id = ?", earning.OrganizerID).Error; err != nil {
+		return err
+	}
+
+	taken := math.Min(clawback, math.Max(organizer.Balance, 0))
+	owed := math.Round((clawback-taken)*100) / 100
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", earning.OrganizerID).
+		Updates(map[string]any{
+			"balance":      gorm.Expr("balance - ?", taken),
+			"clawback_due": gorm.Expr("clawback_due + ?", owed),
+		}).Error; err != nil {
+		return err
+	}
+
+	if taken > 0 {
+		if err := tx.Create(&models.LedgerEntry{
+			UserID:      earning.OrganizerID,
+			Type:        "organizer_refund_clawback",
+			ReferenceID: orderID,
+			Amount:      taken,
+			Description: "refund of a settled order",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if owed > 0 {
+		return tx.Create(&models.LedgerEntry{
+			UserID:      earning.OrganizerID,
+			Type:        "organizer_clawback_owed",
+			ReferenceID: orderID,
+			Amount:      owed,
+			Description: "refund of a settled order, deducted from the next settlements",
+		}).Error
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type saleFixture struct {
+	organizer models.User
+	buyer     models.User
+	ticket    models.Ticket
+	order     models.Order
+	card      models.Payment
+}
+
+// newSale creates a pending order of two 50.00 tickets on an organizer's event with a 10% platform fee,
+// 40.00 of it paid from the buyer's wallet and the rest by card
+func newSale(t *testing.T, db *gorm.DB) saleFixture {
+	t.Helper()
+	f := saleFixture{
+		organizer: models.User{ID: uuid.New(), Email: "organizer@example.com", Fullname: "Organizer", Password: "x"},
+		buyer:     models.User{ID: uuid.New(), Email: "buyer@example.com", Fullname: "Buyer", Password: "x"},
+	}
+	event := models.Event{ID: uuid.New(), Title: "Concert", Date: time.Now().Add(48 * time.Hour), StartTime: 19, EndTime: 22,
+		Status: "active", OrganizerID: &f.organizer.ID, PlatformFeePercent: 10}
+	f.ticket = models.Ticket{ID: uuid.New(), EventID: event.ID, Name: "Regular", Price: 50, Limit: 5, Quota: 100}
+	f.order = models.Order{ID: uuid.New(), UserID: f.buyer.ID, EventID: event.ID, Fullname: "Buyer", Email: "buyer@example.com",
+		Phone: "0800", TotalPrice: 100, WalletAmount: 40, Status: "pending"}
+	f.card = models.Payment{ID: uuid.New(), OrderID: f.order.ID, UserID: f.buyer.ID, Method: "card", Amount: 60, Status: "pending"}
+
+	for _, v := range []any{
+		&f.organizer, &f.buyer, &event, &f.ticket, &f.order, &f.card,
+		&models.OrderDetail{OrderID: f.order.ID, TicketID: f.ticket.ID, TicketName: "Regular", Quantity: 2, Price: 50},
+		&models.Payment{OrderID: f.order.ID, UserID: f.buyer.ID, Method: "wallet", Amount: 40, Status: "pending"},
+		&models.BalanceHold{UserID: f.buyer.ID, Source: "order", ReferenceID: f.order.ID, Amount: 40},
+	} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func newSaleDB(t *testing.T) *gorm.DB {
+	return newTestDB(t, &models.User{}, &models.Event{}, &models.Ticket{}, &models.Order{}, &models.OrderDetail{},
+		&models.Payment{}, &models.UserTicket{}, &models.BalanceHold{}, &models.OrganizerEarning{}, &models.LedgerEntry{})
+}
+
+func TestFulfillOrderRecordsSaleOnce(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	repo := NewPaymentRepository(db)
+
+	// a webhook retry must not issue tickets or record the sale twice
+	for range 2 {
+		if err := repo.FulfillOrder(f.order.ID.String(), f.card.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var order models.Order
+	db.First(&order, "id = ?", f.order.ID)
+	if order.Status != "paid" {
+		t.Fatalf("expected the order to be paid, got %s", order.Status)
+	}
+
+	var unpaid, held, issued, sold int64
+	db.Model(&models.Payment{}).Where("order_id = ? AND status <> ?", f.order.ID, "paid").Count(&unpaid)
+	db.Model(&models.BalanceHold{}).Where("reference_id = ? AND status = ?", f.order.ID, "held").Count(&held)
+	db.Model(&models.UserTicket{}).Where("ticket_id = ?", f.ticket.ID).Count(&issued)
+	db.Model(&models.Ticket{}).Select("sold").Where("id = ?", f.ticket.ID).Scan(&sold)
+	if unpaid != 0 || held != 0 || issued != 2 || sold != 2 {
+		t.Fatalf("unpaid=%d held=%d issued=%d sold=%d, expected 0 0 2 2", unpaid, held, issued, sold)
+	}
+
+	var earnings []models.OrganizerEarning
+	db.Find(&earnings, "order_id = ?", f.order.ID)
+	if len(earnings) != 1 || earnings[0].PlatformFee != 10 || earnings[0].NetAmount != 90 {
+		t.Fatalf("expected one 90.00 earning after a 10.00 fee, got %+v", earnings)
+	}
+}
+
+func TestFulfillOrderRollsBackOnFailure(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	db.Where("order_id = ?", f.order.ID).Delete(&models.OrderDetail{})
+
+	if err := NewPaymentRepository(db).FulfillOrder(f.order.ID.String(), f.card.ID.String()); err == nil {
+		t.Fatal("expected an order without details to fail")
+	}
+
+	var order models.Order
+	var card models.Payment
+	var earnings int64
+	db.First(&order, "id = ?", f.order.ID)
+	db.First(&card, "id = ?", f.card.ID)
+	db.Model(&models.OrganizerEarning{}).Count(&earnings)
+	if order.Status != "pending" || card.Status != "pending" || earnings != 0 {
+		t.Fatalf("expected nothing to change, order=%s card=%s earnings=%d", order.Status, card.Status, earnings)
+	}
+}
+
+func TestRefundOrderClawsBackSettledEarning(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	if err := NewPaymentRepository(db).FulfillOrder(f.order.ID.String(), f.card.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	organizers := NewOrganizerRepository(db)
+	var earning models.OrganizerEarning
+	db.First(&earning, "order_id = ?", f.order.ID)
+	if ok, err := organizers.SettleEarning(earning.ID.String()); !ok || err != nil {
+		t.Fatalf("settle: %v %v", ok, err)
+	}
+
+	// the organizer spent most of the payout before the refund
+	db.Model(&models.User{}).Where("id = ?", f.organizer.ID).Update("balance", 30)
+
+	orders := NewOrderRepository(db)
+	if err := orders.RefundOrder(f.order.ID.String(), 100, "cannot attend", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.RefundOrder(f.order.ID.String(), 100, "cannot attend", time.Now()); !errors.Is(err, ErrOrderNotRefundable) {
+		t.Fatalf("expected a second refund to be rejected, got %v", err)
+	}
+
+	var buyer, organizer models.User
+	db.First(&buyer, "id = ?", f.buyer.ID)
+	db.First(&organizer, "id = ?", f.organizer.ID)
+	if buyer.Balance != 100 {
+		t.Fatalf("expected the buyer to be refunded once, balance is %.2f", buyer.Balance)
+	}
+	if organizer.Balance != 0 || organizer.ClawbackDue != 60 {
+		t.Fatalf("expected 30.00 taken and 60.00 owed, balance %.2f owed %.2f", organizer.Balance, organizer.ClawbackDue)
+	}
+
+	// the next settlement pays the owed clawback first
+	next := models.OrganizerEarning{OrganizerID: f.organizer.ID, EventID: f.order.EventID, OrderID: uuid.New(),
+		GrossAmount: 50, FeePercent: 10, PlatformFee: 5, NetAmount: 45, Status: "pending"}
+	if err := db.Create(&next).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := organizers.SettleEarning(next.ID.String()); !ok || err != nil {
+		t.Fatalf("settle: %v %v", ok, err)
+	}
+
+	db.First(&organizer, "id = ?", f.organizer.ID)
+	if organizer.Balance != 0 || organizer.ClawbackDue != 15 {
+		t.Fatalf("expected 45.00 recovered, balance %.2f owed %.2f", organizer.Balance, organizer.ClawbackDue)
+	}
+
+	var recovered float64
+	db.Model(&models.LedgerEntry{}).Select("SUM(amount)").
+		Where("user_id = ? AND type = ?", f.organizer.ID, "organizer_clawback_recovered").Scan(&recovered)
+	if recovered != 45 {
+		t.Fatalf("expected the recovery in the ledger, got %.2f", recovered)
+	}
+}
+
+func TestExpireOldPendingPaymentsSkipsPaidOrders(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	repo := NewPaymentRepository(db)
+
+	// the second order was paid by a webhook that arrived after its payment went stale
+	paid := models.Order{ID: uuid.New(), UserID: f.buyer.ID, EventID: f.order.EventID, Fullname: "Buyer", Email: "buyer@example.com",
+		Phone: "0800", TotalPrice: 50, Status: "paid"}
+	for _, v := range []any{&paid, &models.Payment{OrderID: paid.ID, UserID: f.buyer.ID, Method: "card", Amount: 50, Status: "pending"}} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Model(&models.Payment{}).Where("1 = 1").UpdateColumn("created_at", time.Now().Add(-time.Hour))
+
+	expired, err := repo.ExpireOldPendingPayments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Fatalf("expected only the pending order to expire, got %d", expired)
+	}
+
+	var kept, failed models.Order
+	db.First(&kept, "id = ?", paid.ID)
+	db.First(&failed, "id = ?", f.order.ID)
+	if kept.Status != "paid" || failed.Status != "failed" {
+		t.Fatalf("expected the paid order to stay paid and the stale one to fail, got %s and %s", kept.Status, failed.Status)
+	}
+
+	var held int64
+	db.Model(&models.BalanceHold{}).Where("reference_id = ? AND status = ?", f.order.ID, "held").Count(&held)
+	if held != 0 {
+		t.Fatal("expected the expired order's hold to be released")
+	}
+}
+
+func TestFulfillExpiredOrderIssuesTicketsForLatePayment(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	repo := NewPaymentRepository(db)
+
+	// the order expires, its 40.00 wallet hold goes back to the buyer's balance
+	db.Model(&models.Payment{}).Where("order_id = ?", f.order.ID).UpdateColumn("created_at", time.Now().Add(-time.Hour))
+	if expired, err := repo.ExpireOldPendingPayments(); err != nil || expired != 1 {
+		t.Fatalf("expected the order to expire, got %d %v", expired, err)
+	}
+
+	// the card payment completes afterwards
+	if err := repo.FulfillOrder(f.order.ID.String(), f.card.ID.String()); !errors.Is(err, ErrOrderNotPending) {
+		t.Fatalf("expected the expired order to refuse a plain fulfillment, got %v", err)
+	}
+	if err := repo.FulfillExpiredOrder(f.order.ID.String(), f.card.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	var order models.Order
+	db.First(&order, "id = ?", f.order.ID)
+	var buyer models.User
+	db.First(&buyer, "id = ?", f.buyer.ID)
+	var unpaid, issued int64
+	db.Model(&models.Payment{}).Where("order_id = ? AND status <> ?", f.order.ID, "paid").Count(&unpaid)
+	db.Model(&models.UserTicket{}).Where("order_id = ?", f.order.ID).Count(&issued)
+	if order.Status != "paid" || buyer.Balance != 0 || unpaid != 0 || issued != 2 {
+		t.Fatalf("status=%s balance=%.2f unpaid=%d issued=%d, expected paid 0 0 2", order.Status, buyer.Balance, unpaid, issued)
+	}
+}
+
+func TestFulfillExpiredOrderNeedsTheWalletAmount(t *testing.T) {
+	db := newSaleDB(t)
+	f := newSale(t, db)
+	repo := NewPaymentRepository(db)
+
+	db.Model(&models.Payment{}).Where("order_id = ?", f.order.ID).UpdateColumn("created_at", time.Now().Add(-time.Hour))
+	if _, err := repo.ExpireOldPendingPayments(); err != nil {
+		t.Fatal(err)
+	}
+	// the returned wallet amount was spent before the card payment came in
+	db.Model(&f.buyer).Update("balance", 10)
+
+	if err := repo.FulfillExpiredOrder(f.order.ID.String(), f.card.ID.String()); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected the revival to need the wallet amount, got %v", err)
+	}
+
+	var order models.Order
+	db.First(&order, "id = ?", f.order.ID)
+	var issued int64
+	db.Model(&models.UserTicket{}).Where("order_id = ?", f.order.ID).Count(&issued)
+	if order.Status != "failed" || issued != 0 {
+		t.Fatalf("expected the order to stay failed without tickets, got %s with %d", order.Status, issued)
+	}
+}
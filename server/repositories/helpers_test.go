@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// sqliteDialector lets the MySQL models migrate on SQLite, enum columns become text and row locks are
+// dropped by the driver. It is good enough for the bookkeeping, not for MySQL specific SQL
+type sqliteDialector struct {
+	gorm.Dialector
+}
+
+var enumType = regexp.MustCompile(`(?i)^enum\(`)
+
+func (d sqliteDialector) DataTypeOf(field *schema.Field) string {
+	if enumType.MatchString(string(field.DataType)) {
+		return "text"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	m := d.Dialector.Migrator(db).(sqlite.Migrator)
+	m.Dialector = d
+	return m
+}
+
+// newTestDB opens a fresh in-memory database with the given models migrated
+func newTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqliteDialector{sqlite.Open("file::memory:")}, &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a single connection keeps every query on the same in-memory database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
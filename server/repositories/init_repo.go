@@ -15,6 +15,12 @@ type Repositories struct {
 	PaymentRepository    PaymentRepository
 	AdminRepository      AdminRepository
 	AuditRepository      AuditLogRepository
+	SessionRepository    SessionRepository
+	AccountRepository    AccountRepository
+	APIClientRepository  APIClientRepository
+	RoleRepository       RoleRepository
+	OrganizerRepository  OrganizerRepository
+	ExportJobRepository  ExportJobRepository
 }
 
 func InitRepositories(db *gorm.DB) *Repositories {
@@ -29,5 +35,11 @@ func InitRepositories(db *gorm.DB) *Repositories {
 		PaymentRepository:    NewPaymentRepository(db),
 		AdminRepository:      NewAdminRepository(db),
 		AuditRepository:      NewAuditLogRepository(db),
+		SessionRepository:    NewSessionRepository(db),
+		AccountRepository:    NewAccountRepository(db),
+		APIClientRepository:  NewAPIClientRepository(db),
+		RoleRepository:       NewRoleRepository(db),
+		OrganizerRepository:  NewOrganizerRepository(db),
+		ExportJobRepository:  NewExportJobRepository(db),
 	}
 }
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
@@ -18,6 +20,9 @@ type OrderRepository interface {
 	HasUsedTicket(orderID string) (bool, error)
 	UpdatePaymentStatus(orderID string, status string) error
 	IncreaseUserBalance(userID string, amount float64) error
+	HoldUserBalance(tx *gorm.DB, hold *models.BalanceHold) error
+	RefundOrder(orderID string, amount float64, reason string, refundedAt time.Time) error
+	FulfillOrder(tx *gorm.DB, orderID string) error
 }
 
 type orderRepository struct {
@@ -121,6 +126,22 @@ func (r *orderRepository) IncreaseUserBalance(userID string, amount float64) err
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("balance", gorm.Expr("balance + ?", amount)).Error
 }
 
+func (r *orderRepository) HoldUserBalance(tx *gorm.DB, hold *models.BalanceHold) error {
+	return placeHold(tx, hold)
+}
+
+// FulfillOrder settles a wallet-only order within the transaction that created it
+func (r *orderRepository) FulfillOrder(tx *gorm.DB, orderID string) error {
+	return fulfillOrder(tx, orderID, "")
+}
+
+// RefundOrder refunds the order, credits the buyer and adjusts the organizer's earning in one transaction
+func (r *orderRepository) RefundOrder(orderID string, amount float64, reason string, refundedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return refundOrder(tx, orderID, amount, reason, refundedAt)
+	})
+}
+
 func (r *orderRepository) HasUsedTicket(orderID string) (bool, error) {
 	var count int64
 	err := r.db.Table("user_tickets").
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"math"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizerRepository settles organizer earnings, they are recorded when an order is fulfilled and
+// adjusted when it is refunded, see fulfillment.go
+type OrganizerRepository interface {
+	GetDueEarnings(endedBefore time.Time) ([]models.OrganizerEarning, error)
+	SettleEarning(id string) (bool, error)
+}
+
+type organizerRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizerRepository(db *gorm.DB) OrganizerRepository {
+	return &organizerRepository{db}
+}
+
+// splitSale rounds the platform fee to cents, the organizer gets the rest
+func splitSale(amount, feePercent float64) (float64, float64) {
+	fee := math.Round(amount*feePercent) / 100
+	return fee, math.Round((amount-fee)*100) / 100
+}
+
+// GetDueEarnings returns pending earnings of events that ended before the given time, cancelled events are left
+// for the refunds to run first
+func (r *organizerRepository) GetDueEarnings(endedBefore time.Time) ([]models.OrganizerEarning, error) {
+	var earnings []models.OrganizerEarning
+	err := r.db.Joins("JOIN events ON events.id = organizer_earnings.event_id").
+		Where("organizer_earnings.status = ?", "pending").
+		Where("events.status <> ?", "cancelled").
+		Where("TIMESTAMPADD(HOUR, events.end_time, events.date) <= ?", endedBefore).
+		Find(&earnings).Error
+	return earnings, err
+}
+
+// SettleEarning credits the organizer's share and records the platform fee, clawbacks the organizer still
+// owes from refunds are deducted first. It reports false when the earning was already settled by another run
+func (r *organizerRepository) SettleEarning(id string) (bool, error) {
+	settled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var earning models.OrganizerEarning
+		if err := tx.First(&earning, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.OrganizerEarning{}).
+			Where("id = ? AND status = ?", id, "pending").
+			Updates(map[string]any{"status": "settled", "settled_at": time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		var organizer models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "clawback_due").
+			First(&organizer, "id = ?", earning.OrganizerID).Error; err != nil {
+			return err
+		}
+
+		recovered := math.Min(earning.NetAmount, organizer.ClawbackDue)
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", earning.OrganizerID).
+			Updates(map[string]any{
+				"balance":      gorm.Expr("balance + ?", earning.NetAmount-recovered),
+				"clawback_due": gorm.Expr("clawback_due - ?", recovered),
+			}).Error; err != nil {
+			return err
+		}
+
+		if recovered > 0 {
+			if err := tx.Create(&models.LedgerEntry{
+				UserID:      earning.OrganizerID,
+				Type:        "organizer_clawback_recovered",
+				ReferenceID: earning.OrderID.String(),
+				Amount:      recovered,
+				Description: "owed refund clawback deducted from settlement",
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if earning.PlatformFee > 0 {
+			if err := tx.Create(&models.LedgerEntry{
+				UserID:      earning.OrganizerID,
+				Type:        "platform_fee",
+				ReferenceID: earning.OrderID.String(),
+				Amount:      earning.PlatformFee,
+				Description: "platform fee on organizer sale",
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		settled = true
+		return nil
+	})
+	return settled, err
+}
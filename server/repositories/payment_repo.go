@@ -8,10 +8,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// CheckoutTTL is how long an order waits for its card payment, the Stripe checkout session closes at the
+// same time. Stripe wants at least 30 minutes, the extra minute covers the time the session takes to create
+const CheckoutTTL = 31 * time.Minute
+
 type PaymentRepository interface {
 	ExpireOldPendingPayments() (int64, error)
 	UpdatePayment(payment *models.Payment) error
 	GetPaymentByID(paymentID string) (*models.Payment, error)
+	FulfillOrder(orderID string, cardPaymentID string) error
+	FulfillExpiredOrder(orderID string, cardPaymentID string) error
+	FailOrderPayments(orderID string) error
 }
 
 type paymentRepository struct {
@@ -42,36 +49,60 @@ func (r *paymentRepository) UpdatePayment(payment *models.Payment) error {
 		}).Error
 }
 
+// ExpireOldPendingPayments fails the orders whose payment has been pending for CheckoutTTL, orders that
+// were paid in the meantime are left alone
 func (r *paymentRepository) ExpireOldPendingPayments() (int64, error) {
-	threshold := time.Now().Add(-15 * time.Minute)
+	threshold := time.Now().Add(-CheckoutTTL)
 
-	var payments []models.Payment
+	var orderIDs []string
+	if err := r.db.Model(&models.Payment{}).
+		Where("status = ? AND created_at <= ?", "pending", threshold).
+		Distinct().
+		Pluck("order_id", &orderIDs).Error; err != nil {
+		return 0, err
+	}
 
+	var expired int64
 	err := r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("status = ? AND created_at <= ?", "pending", threshold).Find(&payments).Error; err != nil {
-			return err
-		}
-
-		if err := tx.Model(&models.Payment{}).
-			Where("status = ? AND created_at <= ?", "pending", threshold).
-			Update("status", "failed").Error; err != nil {
-			return err
-		}
-
-		for _, p := range payments {
-			if err := tx.Model(&models.Order{}).
-				Where("id = ?", p.OrderID).
-				Update("status", "failed").Error; err != nil {
+		for _, orderID := range orderIDs {
+			failed, err := failOrder(tx, orderID)
+			if err != nil {
 				return err
 			}
+			if failed {
+				expired++
+			}
 		}
-
 		return nil
 	})
-
 	if err != nil {
 		return 0, err
 	}
 
-	return int64(len(payments)), nil
+	return expired, nil
+}
+
+// FulfillOrder settles the order in one transaction, cardPaymentID is the card payment that paid it
+// and is empty for wallet-only orders
+func (r *paymentRepository) FulfillOrder(orderID string, cardPaymentID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fulfillOrder(tx, orderID, cardPaymentID)
+	})
+}
+
+// FulfillExpiredOrder settles an order that expired before its card payment came in. The tickets stay
+// reserved on a failed order, only the wallet amount has to be held again; ErrInsufficientBalance is
+// returned when the user spent it in the meantime
+func (r *paymentRepository) FulfillExpiredOrder(orderID string, cardPaymentID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return reviveOrder(tx, orderID, cardPaymentID)
+	})
+}
+
+// FailOrderPayments marks a pending order as failed and returns its wallet amount to the user
+func (r *paymentRepository) FailOrderPayments(orderID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		_, err := failOrder(tx, orderID)
+		return err
+	})
 }
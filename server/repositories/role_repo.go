@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	CreateAssignment(data *models.RoleAssignment) error
+	DeleteAssignment(id string) error
+	GetAssignmentByID(id string) (*models.RoleAssignment, error)
+	GetAssignment(userID, role, eventID string) (*models.RoleAssignment, error)
+	GetAssignmentsByUserID(userID string) ([]models.RoleAssignment, error)
+	GetScopedEventIDs(userID string, roles []string) ([]string, []string, error)
+	GetOwnedEventIDs(organizerID string) ([]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) CreateAssignment(data *models.RoleAssignment) error {
+	return r.db.Create(data).Error
+}
+
+func (r *roleRepository) DeleteAssignment(id string) error {
+	return r.db.Delete(&models.RoleAssignment{}, "id = ?", id).Error
+}
+
+func (r *roleRepository) GetAssignmentByID(id string) (*models.RoleAssignment, error) {
+	var assignment models.RoleAssignment
+	err := r.db.Preload("Event").First(&assignment, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &assignment, err
+}
+
+func (r *roleRepository) GetAssignment(userID, role, eventID string) (*models.RoleAssignment, error) {
+	var assignment models.RoleAssignment
+	err := r.db.First(&assignment, "user_id = ? AND role = ? AND event_id = ?", userID, role, eventID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &assignment, err
+}
+
+func (r *roleRepository) GetAssignmentsByUserID(userID string) ([]models.RoleAssignment, error) {
+	var assignments []models.RoleAssignment
+	err := r.db.Preload("Event").Where("user_id = ?", userID).Order("created_at DESC").Find(&assignments).Error
+	return assignments, err
+}
+
+// GetScopedEventIDs returns the events on which the user holds one of the roles, together with the roles matched
+func (r *roleRepository) GetScopedEventIDs(userID string, roles []string) ([]string, []string, error) {
+	var assignments []models.RoleAssignment
+	if len(roles) == 0 {
+		return nil, nil, nil
+	}
+
+	err := r.db.Select("role", "event_id").Where("user_id = ? AND role IN ?", userID, roles).Find(&assignments).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eventIDs := make([]string, 0, len(assignments))
+	matched := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		eventIDs = append(eventIDs, a.EventID.String())
+		matched = append(matched, a.Role)
+	}
+	return eventIDs, matched, nil
+}
+
+// GetOwnedEventIDs returns the events run by the organizer
+func (r *roleRepository) GetOwnedEventIDs(organizerID string) ([]string, error) {
+	var eventIDs []string
+	err := r.db.Model(&models.Event{}).Where("organizer_id = ?", organizerID).Pluck("id", &eventIDs).Error
+	return eventIDs, err
+}
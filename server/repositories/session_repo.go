@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrRefreshTokenReused = errors.New("refresh token already used")
+
+type SessionRepository interface {
+	CreateSession(session *models.UserSession, token *models.RefreshToken) error
+	GetRefreshToken(id string) (*models.RefreshToken, error)
+	RotateRefreshToken(oldID string, next *models.RefreshToken, ip string) error
+	RevokeSession(id string, reason string) error
+	RevokeUserSessions(userID string, reason string) ([]string, error)
+	RevokeOtherSessions(userID string, keepSessionID string, reason string) ([]string, error)
+	GetActiveSessionsByUserID(userID string) ([]models.UserSession, error)
+	GetSessionByID(id string) (*models.UserSession, error)
+	MarkTwoFactorVerified(id string) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) CreateSession(session *models.UserSession, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+func (r *sessionRepository) GetRefreshToken(id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Preload("Session").First(&token, "id = ?", id).Error
+	return &token, err
+}
+
+// RotateRefreshToken retires the presented token and issues the next one for the same session,
+// the conditional update makes a second use of the old token fail even under concurrent requests
+func (r *sessionRepository) RotateRefreshToken(oldID string, next *models.RefreshToken, ip string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL", oldID).
+			Update("rotated_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.UserSession{}).
+			Where("id = ?", next.SessionID).
+			Updates(map[string]any{"last_used_at": now, "expires_at": next.ExpiresAt, "ip": ip}).Error
+	})
+}
+
+func (r *sessionRepository) RevokeSession(id string, reason string) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// RevokeUserSessions revokes every active session of the user and returns their IDs
+func (r *sessionRepository) RevokeUserSessions(userID string, reason string) ([]string, error) {
+	return r.revokeSessions(userID, "", reason)
+}
+
+// RevokeOtherSessions is RevokeUserSessions that leaves the caller's own session signed in
+func (r *sessionRepository) RevokeOtherSessions(userID string, keepSessionID string, reason string) ([]string, error) {
+	return r.revokeSessions(userID, keepSessionID, reason)
+}
+
+func (r *sessionRepository) revokeSessions(userID string, keepSessionID string, reason string) ([]string, error) {
+	var ids []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if keepSessionID != "" {
+			query = query.Where("id <> ?", keepSessionID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.UserSession{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return result, nil
+}
+
+func (r *sessionRepository) GetActiveSessionsByUserID(userID string) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// MarkTwoFactorVerified records that the user proved a second factor within the session
+func (r *sessionRepository) MarkTwoFactorVerified(id string) error {
+	return r.db.Model(&models.UserSession{}).Where("id = ?", id).Update("two_factor", true).Error
+}
+
+func (r *sessionRepository) GetSessionByID(id string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.First(&session, "id = ?", id).Error
+	return &session, err
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// streamRows runs the query on a database cursor and hands every row to fn as it is read, only one row
+// is held in memory at a time. The query must select flat columns, preloads don't run on a cursor
+func streamRows[T any](db *gorm.DB, fn func(T) error) error {
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
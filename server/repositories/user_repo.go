@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +15,20 @@ type UserRepository interface {
 	UpdateUser(data *models.User) error
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
+
+	// two-factor authentication
+	EnableTwoFactor(userID string, encryptedSecret string, codeHashes []string) error
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	GetUnusedRecoveryCodes(userID string) ([]models.RecoveryCode, error)
+	UseRecoveryCode(id string) (bool, error)
+	DisableTwoFactor(userID string) error
+
+	// linked sign-in identities
+	GetLinkedIdentity(provider, subject string) (*models.LinkedIdentity, error)
+	GetLinkedIdentitiesByUserID(userID string) ([]models.LinkedIdentity, error)
+	CreateLinkedIdentity(data *models.LinkedIdentity) error
+	TouchLinkedIdentity(id string) error
+	DeleteLinkedIdentity(userID, provider string) error
 }
 
 type userRepository struct {
@@ -45,3 +61,90 @@ func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
 	}
 	return &user, err
 }
+
+// EnableTwoFactor stores the secret and the first set of recovery codes together
+func (r *userRepository) EnableTwoFactor(userID string, encryptedSecret string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{"two_factor_enabled": true, "two_factor_secret": encryptedSecret}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *userRepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *userRepository) GetUnusedRecoveryCodes(userID string) ([]models.RecoveryCode, error) {
+	var codes []models.RecoveryCode
+	err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	return codes, err
+}
+
+// UseRecoveryCode burns the code, false means it was used concurrently
+func (r *userRepository) UseRecoveryCode(id string) (bool, error) {
+	res := r.db.Model(&models.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *userRepository) DisableTwoFactor(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]any{"two_factor_enabled": false, "two_factor_secret": ""}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]models.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, models.RecoveryCode{UserID: uid, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+func (r *userRepository) GetLinkedIdentity(provider, subject string) (*models.LinkedIdentity, error) {
+	var identity models.LinkedIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &identity, err
+}
+
+func (r *userRepository) GetLinkedIdentitiesByUserID(userID string) ([]models.LinkedIdentity, error) {
+	var identities []models.LinkedIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *userRepository) CreateLinkedIdentity(data *models.LinkedIdentity) error {
+	return r.db.Create(data).Error
+}
+
+func (r *userRepository) TouchLinkedIdentity(id string) error {
+	return r.db.Model(&models.LinkedIdentity{}).Where("id = ?", id).Update("last_login_at", time.Now()).Error
+}
+
+func (r *userRepository) DeleteLinkedIdentity(userID, provider string) error {
+	return r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.LinkedIdentity{}).Error
+}
@@ -1,18 +1,81 @@
 package repositories
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithdrawalCaps limits how much a user can withdraw per day and per month, a zero cap is off
+type WithdrawalCaps struct {
+	Daily      float64
+	Monthly    float64
+	DayStart   time.Time
+	MonthStart time.Time
+}
+
+// WithdrawalCapError is returned by CreateWithdrawal when the request would go over a cap,
+// Used is what the user already withdrew in the period
+type WithdrawalCapError struct {
+	Rule string
+	Cap  float64
+	Used float64
+}
+
+func (e *WithdrawalCapError) Error() string {
+	return fmt.Sprintf("%s of %.2f exceeded", e.Rule, e.Cap)
+}
+
+var (
+	ErrWithdrawalNotPending = errors.New("withdrawal already reviewed")
+	ErrNoPayableWithdrawals = errors.New("no approved withdrawals waiting for payout")
+	ErrPayoutAlreadySettled = errors.New("payout already settled")
 )
 
 type WithdrawalRepository interface {
-	CreateWithdrawal(w *models.WithdrawalRequest) error
+	CreateWithdrawal(w *models.WithdrawalRequest, caps WithdrawalCaps) error
 	GetAllWithdrawals() ([]models.WithdrawalRequest, error)
 	GetWithdrawalByID(id string) (*models.WithdrawalRequest, error)
 	UpdateWithdrawal(w *models.WithdrawalRequest) error
 	GetUserByID(userID string) (*models.User, error)
 	DecreaseUserBalance(userID string, amount float64) error
+	ReviewWithdrawal(id string, reviewerID string, status string, note string) (*models.WithdrawalRequest, error)
+	CancelWithdrawal(id string, userID string) (*models.WithdrawalRequest, error)
+	GetUserWithdrawals(userID string, params dto.WithdrawalQueryParams) ([]models.WithdrawalRequest, int64, error)
+	GetUserWithdrawalByID(id string, userID string) (*models.WithdrawalRequest, error)
+
+	// payout accounts
+	CreatePayoutAccount(account *models.PayoutAccount) error
+	UpdatePayoutAccount(account *models.PayoutAccount) error
+	DeletePayoutAccount(id string) error
+	GetPayoutAccountByID(id string) (*models.PayoutAccount, error)
+	GetPayoutAccountsByUserID(userID string) ([]models.PayoutAccount, error)
+	HasPendingWithdrawalsForAccount(accountID string) (bool, error)
+
+	// payout batches
+	CreatePayoutBatch(batch *models.PayoutBatch) error
+	GetPayoutBatches() ([]models.PayoutBatch, error)
+	GetPayoutBatchByID(id string) (*models.PayoutBatch, error)
+	SettlePayout(batchID string, withdrawalID string, status string, actorID string, note string) error
+	CompletePayoutBatch(batchID string) error
+
+	// withdrawal policy
+	SumUserWithdrawalsSince(userID string, since time.Time) (float64, error)
+	GetLastRefundAt(userID string) (*time.Time, error)
+	CreatePolicyViolation(v *models.WithdrawalPolicyViolation) error
+
+	// identity verification
+	SaveIdentity(identity *models.IdentityVerification) error
+	GetIdentityByID(id string) (*models.IdentityVerification, error)
+	GetIdentityByUserID(userID string) (*models.IdentityVerification, error)
+	GetIdentities(status string) ([]models.IdentityVerification, error)
 }
 
 type withdrawalRepository struct {
@@ -23,19 +86,107 @@ func NewWithdrawalRepository(db *gorm.DB) WithdrawalRepository {
 	return &withdrawalRepository{db}
 }
 
-func (r *withdrawalRepository) CreateWithdrawal(w *models.WithdrawalRequest) error {
-	return r.db.Create(w).Error
+// CreateWithdrawal stores the request and holds its amount from the user's balance. The user row is
+// locked while the caps are checked, concurrent requests of the same user wait for each other
+func (r *withdrawalRepository) CreateWithdrawal(w *models.WithdrawalRequest, caps WithdrawalCaps) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&user, "id = ?", w.UserID).Error; err != nil {
+			return err
+		}
+
+		for _, limit := range []struct {
+			rule  string
+			cap   float64
+			since time.Time
+		}{
+			{"daily_cap", caps.Daily, caps.DayStart},
+			{"monthly_cap", caps.Monthly, caps.MonthStart},
+		} {
+			if limit.cap <= 0 {
+				continue
+			}
+			used, err := sumUserWithdrawalsSince(tx, w.UserID.String(), limit.since)
+			if err != nil {
+				return err
+			}
+			if used+w.Amount > limit.cap {
+				return &WithdrawalCapError{Rule: limit.rule, Cap: limit.cap, Used: used}
+			}
+		}
+
+		if err := tx.Create(w).Error; err != nil {
+			return err
+		}
+
+		if err := addWithdrawalHistory(tx, w.ID, "pending", w.UserID.String(), w.Reason); err != nil {
+			return err
+		}
+
+		return placeHold(tx, &models.BalanceHold{
+			UserID:      w.UserID,
+			Source:      "withdrawal",
+			ReferenceID: w.ID,
+			Amount:      w.Amount,
+		})
+	})
 }
 
 func (r *withdrawalRepository) GetAllWithdrawals() ([]models.WithdrawalRequest, error) {
 	var list []models.WithdrawalRequest
-	err := r.db.Find(&list).Error
+	err := r.db.Preload("PayoutAccount", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Order("created_at DESC").Find(&list).Error
 	return list, err
 }
 
 func (r *withdrawalRepository) GetWithdrawalByID(id string) (*models.WithdrawalRequest, error) {
 	var w models.WithdrawalRequest
-	err := r.db.First(&w, "id = ?", id).Error
+	err := r.db.Preload("PayoutAccount", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).First(&w, "id = ?", id).Error
+	return &w, err
+}
+
+func (r *withdrawalRepository) GetUserWithdrawals(userID string, params dto.WithdrawalQueryParams) ([]models.WithdrawalRequest, int64, error) {
+	var list []models.WithdrawalRequest
+	var count int64
+
+	db := r.db.Model(&models.WithdrawalRequest{}).Where("user_id = ?", userID)
+
+	if params.Status != "" && params.Status != "all" {
+		db = db.Where("status = ?", params.Status)
+	}
+
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := db.Preload("PayoutAccount", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).
+		Order("created_at DESC").
+		Limit(params.Limit).
+		Offset(offset).
+		Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return list, count, nil
+}
+
+func (r *withdrawalRepository) GetUserWithdrawalByID(id string, userID string) (*models.WithdrawalRequest, error) {
+	var w models.WithdrawalRequest
+	err := r.db.Preload("PayoutAccount", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).
+		Preload("History", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&w, "id = ? AND user_id = ?", id, userID).Error
 	return &w, err
 }
 
@@ -49,6 +200,350 @@ func (r *withdrawalRepository) GetUserByID(userID string) (*models.User, error)
 	return &user, err
 }
 
+// DecreaseUserBalance never lets the balance go below zero
 func (r *withdrawalRepository) DecreaseUserBalance(userID string, amount float64) error {
-	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("balance", gorm.Expr("balance - ?", amount)).Error
+	res := r.db.Model(&models.User{}).
+		Where("id = ? AND balance >= ?", userID, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// ReviewWithdrawal settles a pending request in one transaction: approval captures the held funds,
+// rejection returns them to the user
+func (r *withdrawalRepository) ReviewWithdrawal(id string, reviewerID string, status string, note string) (*models.WithdrawalRequest, error) {
+	var w models.WithdrawalRequest
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND status = ?", id, "pending").
+			Updates(map[string]any{
+				"status":      status,
+				"reviewed_by": reviewerID,
+				"approved_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWithdrawalNotPending
+		}
+
+		if err := tx.First(&w, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := addWithdrawalHistory(tx, w.ID, status, reviewerID, note); err != nil {
+			return err
+		}
+
+		if status != "approved" {
+			return releaseHold(tx, "withdrawal", id)
+		}
+
+		captured, err := captureHold(tx, "withdrawal", id)
+		if err != nil {
+			return err
+		}
+		if captured == 0 {
+			// requests filed before holds existed still need to be debited here
+			debit := tx.Model(&models.User{}).
+				Where("id = ? AND balance >= ?", w.UserID, w.Amount).
+				Update("balance", gorm.Expr("balance - ?", w.Amount))
+			if debit.Error != nil {
+				return debit.Error
+			}
+			if debit.RowsAffected == 0 {
+				return ErrInsufficientBalance
+			}
+		}
+
+		if w.Fee <= 0 {
+			return nil
+		}
+		return tx.Create(&models.LedgerEntry{
+			UserID:      w.UserID,
+			Type:        "withdrawal_fee",
+			ReferenceID: id,
+			Amount:      w.Fee,
+			Description: "withdrawal fee",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// CancelWithdrawal lets the owner withdraw a pending request, the held funds go back to their balance
+func (r *withdrawalRepository) CancelWithdrawal(id string, userID string) (*models.WithdrawalRequest, error) {
+	var w models.WithdrawalRequest
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND user_id = ? AND status = ?", id, userID, "pending").
+			Update("status", "cancelled")
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWithdrawalNotPending
+		}
+
+		if err := tx.First(&w, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if err := addWithdrawalHistory(tx, w.ID, "cancelled", userID, "cancelled by user"); err != nil {
+			return err
+		}
+
+		return releaseHold(tx, "withdrawal", id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (r *withdrawalRepository) CreatePayoutAccount(account *models.PayoutAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *withdrawalRepository) UpdatePayoutAccount(account *models.PayoutAccount) error {
+	return r.db.Save(account).Error
+}
+
+func (r *withdrawalRepository) DeletePayoutAccount(id string) error {
+	return r.db.Delete(&models.PayoutAccount{}, "id = ?", id).Error
+}
+
+func (r *withdrawalRepository) GetPayoutAccountByID(id string) (*models.PayoutAccount, error) {
+	var account models.PayoutAccount
+	err := r.db.First(&account, "id = ?", id).Error
+	return &account, err
+}
+
+func (r *withdrawalRepository) GetPayoutAccountsByUserID(userID string) ([]models.PayoutAccount, error) {
+	var accounts []models.PayoutAccount
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *withdrawalRepository) HasPendingWithdrawalsForAccount(accountID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.WithdrawalRequest{}).
+		Where("payout_account_id = ? AND status = ?", accountID, "pending").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreatePayoutBatch claims every approved withdrawal that is not in a batch yet,
+// the row locks keep two concurrent runs from exporting the same payout twice. Withdrawals
+// requested before payout accounts existed have no bank details to transfer to and are left out
+func (r *withdrawalRepository) CreatePayoutBatch(batch *models.PayoutBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var list []models.WithdrawalRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND payout_batch_id IS NULL AND payout_account_id IS NOT NULL", "approved").
+			Order("approved_at ASC").
+			Find(&list).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return ErrNoPayableWithdrawals
+		}
+
+		var ids []uuid.UUID
+		batch.ItemCount = len(list)
+		batch.TotalAmount = 0
+		for _, w := range list {
+			ids = append(ids, w.ID)
+			batch.TotalAmount += w.Amount - w.Fee
+		}
+
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.WithdrawalRequest{}).
+			Where("id IN ?", ids).
+			Update("payout_batch_id", batch.ID).Error; err != nil {
+			return err
+		}
+
+		// the withdrawal stays approved until the bank reports back, the timeline records the export as an event
+		for _, id := range ids {
+			if err := addWithdrawalHistory(tx, id, "approved", batch.CreatedBy, "exported in payout batch "+batch.ID.String()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *withdrawalRepository) GetPayoutBatches() ([]models.PayoutBatch, error) {
+	var batches []models.PayoutBatch
+	err := r.db.Order("created_at DESC").Find(&batches).Error
+	return batches, err
+}
+
+func (r *withdrawalRepository) GetPayoutBatchByID(id string) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	err := r.db.Preload("Withdrawals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("approved_at ASC")
+	}).
+		Preload("Withdrawals.PayoutAccount", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		First(&batch, "id = ?", id).Error
+	return &batch, err
+}
+
+// SettlePayout records the bank's outcome for one payout, a failed transfer puts the amount back on the user's balance
+func (r *withdrawalRepository) SettlePayout(batchID string, withdrawalID string, status string, actorID string, note string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{"status": status}
+		if status == "paid" {
+			updates["paid_at"] = time.Now()
+		}
+
+		res := tx.Model(&models.WithdrawalRequest{}).
+			Where("id = ? AND payout_batch_id = ? AND status = ?", withdrawalID, batchID, "approved").
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPayoutAlreadySettled
+		}
+
+		var w models.WithdrawalRequest
+		if err := tx.First(&w, "id = ?", withdrawalID).Error; err != nil {
+			return err
+		}
+
+		if err := addWithdrawalHistory(tx, w.ID, status, actorID, note); err != nil {
+			return err
+		}
+
+		if status != "failed" {
+			return nil
+		}
+
+		if err := tx.Model(&models.BalanceHold{}).
+			Where("source = ? AND reference_id = ? AND status = ?", "withdrawal", withdrawalID, "captured").
+			Update("status", "released").Error; err != nil {
+			return err
+		}
+
+		if w.Fee > 0 {
+			if err := tx.Create(&models.LedgerEntry{
+				UserID:      w.UserID,
+				Type:        "withdrawal_fee_reversal",
+				ReferenceID: withdrawalID,
+				Amount:      -w.Fee,
+				Description: "withdrawal fee returned after failed payout",
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", w.UserID).
+			Update("balance", gorm.Expr("balance + ?", w.Amount)).Error
+	})
+}
+
+// CompletePayoutBatch marks the batch reconciled once none of its payouts are still waiting on the bank
+func (r *withdrawalRepository) CompletePayoutBatch(batchID string) error {
+	var open int64
+	if err := r.db.Model(&models.WithdrawalRequest{}).
+		Where("payout_batch_id = ? AND status = ?", batchID, "approved").
+		Count(&open).Error; err != nil {
+		return err
+	}
+	if open > 0 {
+		return nil
+	}
+
+	return r.db.Model(&models.PayoutBatch{}).
+		Where("id = ? AND status = ?", batchID, "exported").
+		Updates(map[string]any{"status": "reconciled", "reconciled_at": time.Now()}).Error
+}
+
+// SumUserWithdrawalsSince counts every request still on its way out or already paid towards the caps
+func (r *withdrawalRepository) SumUserWithdrawalsSince(userID string, since time.Time) (float64, error) {
+	return sumUserWithdrawalsSince(r.db, userID, since)
+}
+
+func sumUserWithdrawalsSince(db *gorm.DB, userID string, since time.Time) (float64, error) {
+	var total float64
+	err := db.Model(&models.WithdrawalRequest{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND status IN ? AND created_at >= ?", userID, []string{"pending", "approved", "paid"}, since).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *withdrawalRepository) GetLastRefundAt(userID string) (*time.Time, error) {
+	var order models.Order
+	err := r.db.Where("user_id = ? AND refunded_at IS NOT NULL", userID).
+		Order("refunded_at DESC").
+		First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order.RefundedAt, nil
+}
+
+func (r *withdrawalRepository) CreatePolicyViolation(v *models.WithdrawalPolicyViolation) error {
+	return r.db.Create(v).Error
+}
+
+func (r *withdrawalRepository) SaveIdentity(identity *models.IdentityVerification) error {
+	return r.db.Save(identity).Error
+}
+
+func (r *withdrawalRepository) GetIdentityByID(id string) (*models.IdentityVerification, error) {
+	var identity models.IdentityVerification
+	err := r.db.First(&identity, "id = ?", id).Error
+	return &identity, err
+}
+
+func (r *withdrawalRepository) GetIdentityByUserID(userID string) (*models.IdentityVerification, error) {
+	var identity models.IdentityVerification
+	err := r.db.First(&identity, "user_id = ?", userID).Error
+	return &identity, err
+}
+
+func (r *withdrawalRepository) GetIdentities(status string) ([]models.IdentityVerification, error) {
+	var list []models.IdentityVerification
+	db := r.db.Order("created_at ASC")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Find(&list).Error
+	return list, err
+}
+
+func addWithdrawalHistory(tx *gorm.DB, withdrawalID uuid.UUID, status string, actorID string, note string) error {
+	return tx.Create(&models.WithdrawalStatusHistory{
+		WithdrawalID: withdrawalID,
+		Status:       status,
+		ActorID:      actorID,
+		Note:         note,
+	}).Error
 }
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateWithdrawalEnforcesCaps(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.WithdrawalRequest{}, &models.WithdrawalStatusHistory{}, &models.BalanceHold{})
+	repo := NewWithdrawalRepository(db)
+
+	user := models.User{ID: uuid.New(), Email: "payee@example.com", Fullname: "Payee", Password: "x", Balance: 1000}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	caps := WithdrawalCaps{Daily: 300, Monthly: 500, DayStart: now.Add(-time.Hour), MonthStart: now.Add(-24 * time.Hour)}
+	newRequest := func(amount float64) *models.WithdrawalRequest {
+		return &models.WithdrawalRequest{ID: uuid.New(), UserID: user.ID, Amount: amount, Status: "pending", CreatedAt: now}
+	}
+
+	if err := repo.CreateWithdrawal(newRequest(200), caps); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	var capErr *WithdrawalCapError
+	err := repo.CreateWithdrawal(newRequest(150), caps)
+	if !errors.As(err, &capErr) || capErr.Rule != "daily_cap" || capErr.Used != 200 {
+		t.Fatalf("expected the daily cap to reject the second request, got %v", err)
+	}
+
+	// a rejected request holds nothing from the balance
+	var balance float64
+	db.Model(&models.User{}).Select("balance").Where("id = ?", user.ID).Scan(&balance)
+	if balance != 800 {
+		t.Fatalf("expected only the first request to be held, balance is %.2f", balance)
+	}
+
+	// last month's requests only count towards the monthly cap
+	old := newRequest(250)
+	old.CreatedAt = now.Add(-2 * time.Hour)
+	if err := db.Create(old).Error; err != nil {
+		t.Fatal(err)
+	}
+	err = repo.CreateWithdrawal(newRequest(60), caps)
+	if !errors.As(err, &capErr) || capErr.Rule != "monthly_cap" {
+		t.Fatalf("expected the monthly cap to reject the request, got %v", err)
+	}
+}
+
+func TestCreatePayoutBatchLeavesOutWithdrawalsWithoutAccount(t *testing.T) {
+	db := newTestDB(t, &models.WithdrawalRequest{}, &models.WithdrawalStatusHistory{}, &models.PayoutAccount{}, &models.PayoutBatch{})
+	repo := NewWithdrawalRepository(db)
+
+	userID := uuid.New()
+	account := models.PayoutAccount{ID: uuid.New(), UserID: userID, BankCode: "014", AccountNumber: "1234567890", HolderName: "Payee"}
+	withAccount := models.WithdrawalRequest{ID: uuid.New(), UserID: userID, PayoutAccountID: &account.ID, Amount: 100, Status: "approved"}
+	legacy := models.WithdrawalRequest{ID: uuid.New(), UserID: userID, Amount: 50, Status: "approved"}
+	for _, v := range []any{&account, &withAccount, &legacy} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch := &models.PayoutBatch{ID: uuid.New(), Format: "csv", Status: "exported"}
+	if err := repo.CreatePayoutBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.ItemCount != 1 || batch.TotalAmount != 100 {
+		t.Fatalf("expected only the withdrawal with an account in the batch, got %d items of %.2f", batch.ItemCount, batch.TotalAmount)
+	}
+
+	var left models.WithdrawalRequest
+	db.First(&left, "id = ?", legacy.ID)
+	if left.PayoutBatchID != nil {
+		t.Fatal("expected the legacy withdrawal to stay out of the batch")
+	}
+
+	// the timeline only uses statuses a withdrawal can have
+	var history models.WithdrawalStatusHistory
+	db.Last(&history, "withdrawal_id = ?", withAccount.ID)
+	if history.Status != "approved" || history.Note != "exported in payout batch "+batch.ID.String() {
+		t.Fatalf("expected the export recorded as an approved event, got %+v", history)
+	}
+}
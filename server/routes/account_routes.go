@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AccountRoutes(r *gin.RouterGroup, h *handlers.AccountHandler) {
+	account := r.Group("/user/me", middleware.AuthRequired(), middleware.BlockImpersonation())
+	account.GET("/export", h.ExportMyData)
+	account.DELETE("", h.DeleteMyAccount)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
 
@@ -9,14 +10,20 @@ import (
 )
 
 func AdminRoutes(r *gin.RouterGroup, h *handlers.AdminHandler) {
-	admin := r.Group("/admin", middleware.AuthRequired(), middleware.RoleOnly("admin"))
+	admin := r.Group("/admin", middleware.AuthRequired(), middleware.RequirePermission(utils.PermReportsView))
 
 	admin.GET("/summary", h.GetSummary)
+	admin.GET("/analytics", h.GetSalesAnalytics)
 	admin.GET("/users", h.GetAllUsers)
 	admin.GET("/events", h.GetAllEvents)
+	admin.GET("/events/:id/report", h.GetEventReport)
+	admin.GET("/events/:id/attendees", h.GetEventAttendees)
 	admin.GET("/orders", h.GetOrderReports)
 	admin.GET("/ticket-sales", h.GetTicketSalesReports)
 	admin.GET("/payments", h.GetPaymentReports)
 	admin.GET("/refunds", h.GetRefundReports)
 	admin.GET("/withdrawals", h.GetWithdrawalReports)
+	admin.GET("/withdrawal-violations", h.GetWithdrawalViolationReports)
+	admin.GET("/organizer-earnings", h.GetOrganizerEarnings)
+	admin.GET("/organizers/:id/summary", h.GetOrganizerSummary)
 }
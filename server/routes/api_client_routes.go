@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func APIClientRoutes(r *gin.RouterGroup, h *handlers.APIClientHandler) {
+	admin := r.Group("/admin/api-clients", middleware.AuthRequired(), middleware.RequirePermission(utils.PermAPIClientsManage))
+	admin.GET("", h.GetClients)
+	admin.POST("", h.IssueClient)
+	admin.GET("/scopes", h.GetScopes)
+	admin.GET("/:id", h.GetClientByID)
+	admin.POST("/:id/rotate", h.RotateClientKey)
+	admin.DELETE("/:id", h.RevokeClient)
+}
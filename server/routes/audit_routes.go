@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AuditRoutes(r *gin.RouterGroup, h *handlers.AuditHandler) {
+	admin := r.Group("/admin", middleware.AuthRequired(), middleware.RequirePermission(utils.PermAuditView))
+	admin.GET("/audit-logs", h.GetAuditLogs)
+	admin.GET("/audit-logs/:id", h.GetAuditLogByID)
+}
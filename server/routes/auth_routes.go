@@ -3,6 +3,8 @@ package routes
 
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,13 +19,52 @@ func AuthRoutes(r *gin.RouterGroup, h *handlers.AuthHandler) {
 	auth.POST("/verify-otp", h.VerifyOTP)
 	auth.POST("/refresh-token", h.RefreshToken)
 
+	// native clients (mobile app, scanner devices), tokens in the body instead of cookies
+	auth.POST("/token/refresh", h.NativeRefreshToken)
+	auth.POST("/token/revoke", h.NativeRevokeToken)
+	auth.POST("/logout-all", middleware.AuthRequired(), middleware.BlockImpersonation(), h.LogoutAll)
+
+	// passwordless login
+	auth.POST("/magic-link", h.RequestMagicLink)
+	auth.POST("/magic-link/verify", h.VerifyMagicLink)
+
 	// Password reset flow
 	auth.POST("/forgot-password", h.ForgotPassword)
 	auth.GET("/validate-reset-token", h.ValidateResetToken)
 	auth.POST("/reset-password", h.ResetPassword)
 
-	// oAuth endpoints
-	auth.GET("/google", h.GoogleOAuthRedirect)
-	auth.GET("/google/callback", h.GoogleOAuthCallback)
+	// two-factor authentication
+	auth.POST("/2fa/verify", h.VerifyTwoFactor)
+	twoFactor := auth.Group("/2fa", middleware.AuthRequired(), middleware.BlockImpersonation())
+	twoFactor.POST("/setup", h.SetupTwoFactor)
+	twoFactor.POST("/enable", h.EnableTwoFactor)
+	twoFactor.POST("/disable", h.DisableTwoFactor)
+	twoFactor.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+
+	// admin user management, reset for a lost authenticator, suspend/ban, lockout and forced password reset
+	admin := r.Group("/admin/users", middleware.AuthRequired(), middleware.RequirePermission(utils.PermUsersManage))
+	admin.GET("/:id", h.GetUserAccount)
+	admin.POST("/:id/2fa/reset", h.ResetTwoFactor)
+	admin.PUT("/:id/status", h.UpdateUserStatus)
+	admin.POST("/:id/unlock", h.UnlockUser)
+	admin.POST("/:id/password-reset", h.ForcePasswordReset)
+
+	// support impersonation, the token is ended with the impersonation token itself
+	admin.POST("/:id/impersonate", middleware.RequirePermission(utils.PermUsersImpersonate), h.StartImpersonation)
+	auth.POST("/impersonation/stop", middleware.AuthRequired(), h.StopImpersonation)
+
+	// oAuth endpoints, /google is kept for existing clients
+	auth.GET("/oauth/providers", h.GetOAuthProviders)
+	auth.GET("/oauth/:provider", h.OAuthRedirect)
+	auth.GET("/oauth/:provider/callback", h.OAuthCallback)
+	auth.GET("/google", h.OAuthRedirect)
+	auth.GET("/google/callback", h.OAuthCallback)
+
+	// linked sign-in identities
+	identities := r.Group("/user/identities", middleware.AuthRequired(), middleware.BlockImpersonation())
+	identities.GET("", h.GetLinkedIdentities)
+	identities.POST("/link/confirm", h.ConfirmIdentityLink)
+	identities.POST("/:provider/link", h.LinkIdentity)
+	identities.DELETE("/:provider", h.UnlinkIdentity)
 
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,9 +15,10 @@ func EventRoutes(r *gin.RouterGroup, h *handlers.EventHandler) {
 	event.GET("/:id", h.GetEventByID)                // TODO : Query to event detail can be optimized by separating tickets and event details
 	event.GET("/:id/tickets", h.GetTicketsByEventID) // TODO : This endpoint to support optimizing event detail query, use Later after refactoring event detail query
 
-	admin := event.Use(middleware.AuthRequired(), middleware.RoleOnly("admin"))
-	admin.POST("", h.CreateEvent)
-	admin.PUT("/:id", h.UpdateEventByID)
-	admin.DELETE("/:id", h.DeleteEventByID)
+	// event managers granted a single event can edit it but not create new ones, organizers manage the events they own
+	admin := event.Group("", middleware.AuthRequired())
+	admin.POST("", middleware.RequirePermission(utils.PermEventsCreate), h.CreateEvent)
+	admin.PUT("/:id", middleware.RequireEventPermission(utils.PermEventsManage), h.UpdateEventByID)
+	admin.DELETE("/:id", middleware.RequireEventPermission(utils.PermEventsManage), h.DeleteEventByID)
 
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportRoutes queue report exports for the background worker, every admin only sees their own jobs
+func ExportRoutes(r *gin.RouterGroup, h *handlers.ExportHandler) {
+	admin := r.Group("/admin/exports", middleware.AuthRequired(), middleware.RequirePermission(utils.PermReportsView))
+
+	admin.POST("", h.CreateExportJob)
+	admin.GET("/:id", h.GetExportJob)
+	admin.GET("/:id/download", h.DownloadExport)
+}
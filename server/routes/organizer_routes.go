@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizerRoutes serves the admin reports to organizers, every report is limited to the caller's events
+func OrganizerRoutes(r *gin.RouterGroup, h *handlers.AdminHandler) {
+	organizer := r.Group("/organizer", middleware.AuthRequired(), middleware.RequirePermission(utils.PermOrganizerReports), middleware.ScopeToOrganizer())
+
+	organizer.GET("/summary", h.GetOrganizerSummary)
+	organizer.GET("/analytics", h.GetSalesAnalytics)
+	organizer.GET("/events", h.GetAllEvents)
+	organizer.GET("/events/:id/report", h.GetEventReport)
+	organizer.GET("/events/:id/attendees", h.GetEventAttendees)
+	organizer.GET("/orders", h.GetOrderReports)
+	organizer.GET("/ticket-sales", h.GetTicketSalesReports)
+	organizer.GET("/payments", h.GetPaymentReports)
+	organizer.GET("/refunds", h.GetRefundReports)
+	organizer.GET("/earnings", h.GetOrganizerEarnings)
+}
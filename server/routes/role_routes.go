@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RoleRoutes(r *gin.RouterGroup, h *handlers.RoleHandler) {
+	admin := r.Group("/admin", middleware.AuthRequired(), middleware.RequirePermission(utils.PermRolesManage))
+	admin.GET("/roles", h.GetRoles)
+	admin.GET("/users/:id/roles", h.GetUserRoles)
+	admin.PUT("/users/:id/role", h.UpdateUserRole)
+	admin.POST("/users/:id/roles", h.AssignEventRole)
+	admin.DELETE("/users/:id/roles/:assignmentId", h.RevokeEventRole)
+}
@@ -31,5 +31,12 @@ func InitRoutes(r *gin.Engine, h *handlers.Handlers) {
 	AdminRoutes(api, h.AdminHandler)
 	WithdrawalRoutes(api, h.WithdrawalHandler)
 	UserTicketRoutes(api, h.UserTicketHandler)
+	SessionRoutes(api, h.SessionHandler)
+	AccountRoutes(api, h.AccountHandler)
+	APIClientRoutes(api, h.APIClientHandler)
+	RoleRoutes(api, h.RoleHandler)
+	OrganizerRoutes(api, h.AdminHandler)
+	AuditRoutes(api, h.AuditHandler)
+	ExportRoutes(api, h.ExportHandler)
 
 }
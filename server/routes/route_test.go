@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEveryRouteNeedsAGrantableScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	InitRoutes(r, &handlers.Handlers{})
+
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/v1/") {
+			continue
+		}
+		scope := utils.RequiredScope(route.Method, route.Path)
+		if scope == "*" || !utils.ValidAPIScope(scope) {
+			t.Errorf("%s %s needs %q, which no scoped API client can be granted", route.Method, route.Path, scope)
+		}
+	}
+}
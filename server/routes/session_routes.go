@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SessionRoutes(r *gin.RouterGroup, h *handlers.SessionHandler) {
+	// user endpoints
+	user := r.Group("/user/sessions", middleware.AuthRequired())
+	user.GET("", h.GetMySessions)
+	user.DELETE("/:id", middleware.BlockImpersonation(), h.RevokeMySession)
+
+	// admin endpoints
+	admin := r.Group("/admin/users/:id/sessions", middleware.AuthRequired(), middleware.RequirePermission(utils.PermUsersManage))
+	admin.GET("", h.GetUserSessions)
+	admin.DELETE("", h.RevokeAllUserSessions)
+	admin.DELETE("/:sessionId", h.RevokeUserSession)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
 
@@ -12,7 +13,7 @@ func TicketRoutes(r *gin.RouterGroup, h *handlers.TicketHandler) {
 	ticket := r.Group("/tickets")
 	ticket.GET("/:id", h.GetTicketByID) // TODO : Remove this endpoint later since ticket detail is not really necessary
 
-	admin := ticket.Use(middleware.AuthRequired(), middleware.RoleOnly("admin"))
+	admin := ticket.Use(middleware.AuthRequired(), middleware.RequireEventPermission(utils.PermEventsManage))
 	admin.POST("", h.CreateTicket)
 	admin.PUT("/:id", h.UpdateTicket)
 	admin.DELETE("/:id", h.DeleteTicket)
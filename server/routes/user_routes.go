@@ -12,5 +12,9 @@ func UserRoutes(r *gin.RouterGroup, h *handlers.UserHandler) {
 	user := r.Group("/user", middleware.AuthRequired())
 	user.GET("/me", h.GetMyProfile)
 	user.PUT("/me", h.UpdateProfile)
-	user.PUT("/change-password", h.ChangePassword)
+	user.PUT("/change-password", middleware.BlockImpersonation(), h.ChangePassword)
+	user.POST("/change-email", middleware.BlockImpersonation(), h.RequestEmailChange)
+
+	// confirmation link from the new inbox, no session required
+	r.POST("/user/change-email/confirm", h.ConfirmEmailChange)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
 
@@ -10,12 +11,12 @@ import (
 
 func UserTicketRoutes(r *gin.RouterGroup, h *handlers.UserTicketHandler) {
 	// end-user: for getting user tickets and printing them
-	user := r.Group("/user-ticket", middleware.AuthRequired(), middleware.RoleOnly("user", "admin"))
+	user := r.Group("/user-ticket", middleware.AuthRequired(), middleware.RequirePermission(utils.PermTicketsView))
 	user.GET("/:id", h.GetTicketByID)
 	user.GET("/:id/print", h.PrintTicket)
 
-	// admin/staff: for validating and marking tickets as used
-	admin := r.Group("/user-ticket", middleware.AuthRequired(), middleware.RoleOnly("admin"))
+	// check-in staff: for validating and marking tickets as used, staff granted a single event only scan that event
+	admin := r.Group("/user-ticket", middleware.AuthRequired(), middleware.RequireEventPermission(utils.PermCheckinScan))
 	admin.POST("/validate", h.ValidateTicket)
 	admin.PATCH("/:id/use", h.UseTicket)
 }
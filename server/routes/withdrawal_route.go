@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/middleware"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/handlers"
 
@@ -9,10 +10,40 @@ import (
 )
 
 func WithdrawalRoutes(r *gin.RouterGroup, h *handlers.WithdrawalHandler) {
-	r.POST("/withdrawals", middleware.AuthRequired(), middleware.RoleOnly("user"), h.CreateWithdrawal)
+	// user endpoints, off limits while impersonating
+	user := r.Group("/withdrawals", middleware.AuthRequired(), middleware.BlockImpersonation(), middleware.RequirePermission(utils.PermWithdrawalsRequest))
+	user.POST("", h.CreateWithdrawal)
+	user.GET("/me", h.GetMyWithdrawals)
+	user.GET("/me/:id", h.GetMyWithdrawalDetail)
+	user.POST("/me/:id/cancel", h.CancelWithdrawal)
+
+	// withdrawal policy & identity verification
+	user.GET("/policy", h.GetWithdrawalPolicy)
+	user.GET("/identity", h.GetMyIdentity)
+	user.POST("/identity", h.SubmitIdentity)
+
+	// payout bank accounts
+	user.GET("/accounts", h.GetPayoutAccounts)
+	user.POST("/accounts", h.CreatePayoutAccount)
+	user.DELETE("/accounts/:id", h.DeletePayoutAccount)
 
 	// admin endpoints
-	admin := r.Group("/withdrawals", middleware.AuthRequired(), middleware.RoleOnly("admin"))
+	admin := r.Group("/withdrawals", middleware.AuthRequired(), middleware.RequirePermission(utils.PermWithdrawalsReview))
 	admin.GET("", h.GetAllWithdrawals)
 	admin.PATCH("/:id", h.ReviewWithdrawal)
+	admin.PATCH("/accounts/:id/verify", h.VerifyPayoutAccount)
+
+	// identity verification
+	identity := r.Group("/withdrawals/identities", middleware.AuthRequired(), middleware.RequirePermission(utils.PermIdentitiesReview))
+	identity.GET("", h.GetIdentities)
+	identity.GET("/:id/document", h.GetIdentityDocument)
+	identity.PATCH("/:id/verify", h.ReviewIdentity)
+
+	// payout batches
+	payout := r.Group("/withdrawals/payouts", middleware.AuthRequired(), middleware.RequirePermission(utils.PermPayoutsManage))
+	payout.GET("", h.GetPayoutBatches)
+	payout.POST("", h.CreatePayoutBatch)
+	payout.GET("/:id", h.GetPayoutBatchDetail)
+	payout.GET("/:id/export", h.ExportPayoutBatch)
+	payout.POST("/:id/reconcile", h.ReconcilePayoutBatch)
 }
@@ -0,0 +1,36 @@
+package seeders
+
+import (
+	"strings"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"gorm.io/gorm"
+)
+
+// SeedAPIClients turns the keys from API_KEY into full-scope first-party clients,
+// every other client is issued from the admin endpoints
+func SeedAPIClients(db *gorm.DB) {
+	for i, key := range strings.Split(config.AppConfig.ApiKeys, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		prefix := key
+		if len(prefix) > 6 {
+			prefix = prefix[:6]
+		}
+
+		client := models.APIClient{
+			Name:      "First-party client " + string(rune('A'+i)),
+			KeyPrefix: prefix,
+			KeyHash:   utils.HashAPIKey(key),
+			Scopes:    "*",
+			RateLimit: 0,
+		}
+		db.Where(models.APIClient{KeyHash: client.KeyHash}).FirstOrCreate(&client)
+	}
+}
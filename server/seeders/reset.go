@@ -20,6 +20,22 @@ func ResetDatabase(db *gorm.DB) {
 		&models.Payment{},
 		&models.UserTicket{},
 		&models.WithdrawalRequest{},
+		&models.BalanceHold{},
+		&models.PayoutAccount{},
+		&models.WithdrawalStatusHistory{},
+		&models.PayoutBatch{},
+		&models.IdentityVerification{},
+		&models.LedgerEntry{},
+		&models.WithdrawalPolicyViolation{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.RecoveryCode{},
+		&models.LinkedIdentity{},
+		&models.APIClient{},
+		&models.RoleAssignment{},
+		&models.OrganizerEarning{},
+		&models.AuditLog{},
+		&models.ExportJob{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to drop tables: %v", err)
@@ -38,6 +54,22 @@ func ResetDatabase(db *gorm.DB) {
 		&models.Payment{},
 		&models.UserTicket{},
 		&models.WithdrawalRequest{},
+		&models.BalanceHold{},
+		&models.PayoutAccount{},
+		&models.WithdrawalStatusHistory{},
+		&models.PayoutBatch{},
+		&models.IdentityVerification{},
+		&models.LedgerEntry{},
+		&models.WithdrawalPolicyViolation{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.RecoveryCode{},
+		&models.LinkedIdentity{},
+		&models.APIClient{},
+		&models.RoleAssignment{},
+		&models.OrganizerEarning{},
+		&models.AuditLog{},
+		&models.ExportJob{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate tables: %v", err)
@@ -47,5 +79,7 @@ func ResetDatabase(db *gorm.DB) {
 	log.Println("seeding dummy data...")
 	SeedAll(db)
 	SeedAdditionalEvents(db)
+	SeedAPIClients(db)
+	SeedStaffUsers(db)
 	log.Println("seeding completed successfully.")
 }
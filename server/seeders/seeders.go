@@ -19,7 +19,7 @@ func SeedAll(db *gorm.DB) {
 		Fullname: "Event Admin",
 		Email:    "admin@event.com",
 		Password: string(password),
-		Role:     "admin",
+		Role:     "super_admin",
 		Avatar:   "https://api.dicebear.com/6.x/initials/svg?seed=admin",
 	}
 	customer1 := models.User{
@@ -162,6 +162,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   customer1.ID,
 		EventID:  event1.ID,
 		TicketID: ticket1A.ID,
+		OrderID:  &order1.ID,
 		QRCode:   "QR-3896ee3e-d5e1-42f2-8661-b4ae64f429b4",
 	})
 
@@ -170,6 +171,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   customer1.ID,
 		EventID:  event1.ID,
 		TicketID: ticket1B.ID,
+		OrderID:  &order1.ID,
 		QRCode:   "QR-2f36f12a-8eeb-4cfc-b1c0-8d25e81e06a1",
 	})
 
@@ -286,6 +288,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   uuid.MustParse("bdb598a3-1c86-4e95-93d2-65cda21b4b33"),
 		EventID:  uuid.MustParse("ddaf8eb0-a68e-4316-8dc7-834d183faaf6"),
 		TicketID: uuid.MustParse("d39ff313-db85-4a3f-9f33-fa8fb0c68019"),
+		OrderID:  &newOrder.ID,
 		QRCode:   "QR-7e3d5fe9-6915-46d2-a117-7eb5d645e3f6",
 	})
 
@@ -295,6 +298,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   uuid.MustParse("bdb598a3-1c86-4e95-93d2-65cda21b4b33"),
 		EventID:  uuid.MustParse("ddaf8eb0-a68e-4316-8dc7-834d183faaf6"),
 		TicketID: uuid.MustParse("802a2b63-d941-4557-8dc7-0d0580d0580f"),
+		OrderID:  &newOrder.ID,
 		QRCode:   "QR-802a2b63-d941-4557-8dc7-0d0580d0580f",
 	})
 
@@ -439,6 +443,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   uuid.MustParse("bdb598a3-1c86-5e95-93d2-65cda21b4b33"),
 		EventID:  event4.ID,
 		TicketID: ticket4VIP.ID,
+		OrderID:  &event4Order.ID,
 		QRCode:   "QR-d4e5f6a7-8899-4ccc-aaaa-445566778800",
 	})
 	db.Create(&models.UserTicket{
@@ -446,6 +451,7 @@ func SeedAll(db *gorm.DB) {
 		UserID:   uuid.MustParse("bdb598a3-1c86-5e95-93d2-65cda21b4b33"),
 		EventID:  event4.ID,
 		TicketID: ticket4Reg.ID,
+		OrderID:  &event4Order.ID,
 		QRCode:   "QR-e5f6a788-99aa-4ddd-bbbb-556677889911",
 	})
 
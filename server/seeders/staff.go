@@ -0,0 +1,27 @@
+package seeders
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// SeedStaffUsers adds one account per staff role and an organizer so every permission set can be tried locally
+func SeedStaffUsers(db *gorm.DB) {
+	password, _ := bcrypt.GenerateFromPassword([]byte("123456"), 10)
+
+	staff := []models.User{
+		{Fullname: "Finance Staff", Email: "finance@event.com", Role: utils.RoleFinance},
+		{Fullname: "Event Manager", Email: "manager@event.com", Role: utils.RoleEventManager},
+		{Fullname: "Check-in Staff", Email: "checkin@event.com", Role: utils.RoleCheckinStaff},
+		{Fullname: "Event Organizer", Email: "organizer@event.com", Role: utils.RoleOrganizer},
+	}
+
+	for i := range staff {
+		staff[i].Password = string(password)
+		staff[i].Avatar = "https://api.dicebear.com/6.x/initials/svg?seed=" + staff[i].Role
+		db.Where(models.User{Email: staff[i].Email}).FirstOrCreate(&staff[i])
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"gorm.io/gorm"
+)
+
+type AccountService interface {
+	ExportUserData(userID string) (string, []byte, error)
+	DeleteAccount(userID string, req *dto.DeleteAccountRequest) error
+}
+
+type accountService struct {
+	repo    repositories.AccountRepository
+	session repositories.SessionRepository
+}
+
+func NewAccountService(repo repositories.AccountRepository, session repositories.SessionRepository) AccountService {
+	return &accountService{repo: repo, session: session}
+}
+
+// ExportUserData builds a zip archive with one JSON file per kind of record
+func (s *accountService) ExportUserData(userID string) (string, []byte, error) {
+	limitKey := "ticket:data_export:" + userID
+	if utils.KeyExists(limitKey) {
+		return "", nil, response.NewTooManyRequests("A data export was generated recently, please try again later")
+	}
+
+	data, err := s.repo.GetUserData(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, response.NewNotFound("User not found")
+	}
+	if err != nil {
+		return "", nil, response.NewInternalServerError("Failed to collect user data", err)
+	}
+
+	export := toUserDataExport(data, time.Now())
+
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"profile.json", export.Profile},
+		{"orders.json", export.Orders},
+		{"payments.json", export.Payments},
+		{"tickets.json", export.Tickets},
+		{"withdrawals.json", export.Withdrawals},
+		{"payout_accounts.json", export.PayoutAccounts},
+		{"sessions.json", export.Sessions},
+		{"linked_identities.json", export.LinkedIdentities},
+		{"audit_logs.json", export.AuditLogs},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return "", nil, response.NewInternalServerError("Failed to build export archive", err)
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return "", nil, response.NewInternalServerError("Failed to build export archive", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", nil, response.NewInternalServerError("Failed to build export archive", err)
+	}
+
+	utils.AddKeys(limitKey, export.ExportedAt.Unix(), 10*time.Minute)
+
+	filename := fmt.Sprintf("my-data-%s.zip", export.ExportedAt.Format("20060102-150405"))
+	return filename, buf.Bytes(), nil
+}
+
+// DeleteAccount closes the account once no money is left on it, orders and payments stay for the books
+// but lose their personal fields, and every session is signed out
+func (s *accountService) DeleteAccount(userID string, req *dto.DeleteAccountRequest) error {
+	data, err := s.repo.GetUserData(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.NewNotFound("User not found")
+	}
+	if err != nil {
+		return response.NewInternalServerError("Failed to get user", err)
+	}
+	user := data.User
+
+	hasPassword := user.Password != "" && user.Password != "-"
+	if hasPassword && !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		return response.NewBadRequest("Current password is incorrect")
+	}
+
+	if utils.IsStaffRole(user.Role) {
+		return response.NewForbidden("Staff accounts cannot be deleted from the profile")
+	}
+
+	documentID, err := s.repo.AnonymizeUser(userID)
+	if err != nil {
+		var unsettled *repositories.AccountNotSettledError
+		if !errors.As(err, &unsettled) {
+			return response.NewInternalServerError("Failed to delete account", err)
+		}
+		switch {
+		case unsettled.Balance > 0:
+			return response.NewConflict("Withdraw your remaining balance before deleting your account").
+				WithContext("balance", unsettled.Balance)
+		case unsettled.HeldBalance > 0:
+			return response.NewConflict("Your balance is held by an order or withdrawal in progress, try again once it settles").
+				WithContext("heldBalance", unsettled.HeldBalance)
+		default:
+			return response.NewConflict("You still have withdrawals in progress").
+				WithContext("pendingWithdrawals", unsettled.PendingWithdrawals)
+		}
+	}
+	utils.DeletePrivateDocument(documentID)
+
+	ids, err := s.session.RevokeUserSessions(userID, "account_deleted")
+	if err != nil {
+		return response.NewInternalServerError("Failed to revoke sessions", err)
+	}
+	for _, id := range ids {
+		utils.MarkSessionRevoked(id)
+	}
+
+	return nil
+}
+
+func toUserDataExport(data *repositories.UserData, exportedAt time.Time) *dto.UserDataExport {
+	user := data.User
+	export := &dto.UserDataExport{
+		ExportedAt: exportedAt,
+		Profile: dto.ProfileResponse{
+			ID:               user.ID.String(),
+			Email:            user.Email,
+			Fullname:         user.Fullname,
+			Avatar:           user.Avatar,
+			Role:             user.Role,
+			Balance:          user.Balance,
+			JoinedAt:         user.CreatedAt,
+			TwoFactorEnabled: user.TwoFactorEnabled,
+			HasPassword:      user.Password != "" && user.Password != "-",
+		},
+	}
+
+	items := map[string][]dto.OrderDetailResponse{}
+	for _, d := range data.OrderDetails {
+		orderID := d.OrderID.String()
+		items[orderID] = append(items[orderID], dto.OrderDetailResponse{
+			ID:         d.ID.String(),
+			TicketName: d.TicketName,
+			TicketID:   d.TicketID.String(),
+			Quantity:   d.Quantity,
+			Price:      d.Price,
+			CreatedAt:  d.CreatedAt,
+		})
+	}
+
+	for _, o := range data.Orders {
+		export.Orders = append(export.Orders, dto.ExportOrderResponse{
+			OrderResponse: dto.OrderResponse{
+				ID:           o.ID.String(),
+				EventID:      o.EventID.String(),
+				EventName:    o.Event.Title,
+				EventImage:   o.Event.Image,
+				Fullname:     o.Fullname,
+				Email:        o.Email,
+				Phone:        o.Phone,
+				TotalPrice:   o.TotalPrice,
+				WalletAmount: o.WalletAmount,
+				Status:       o.Status,
+				CreatedAt:    o.CreatedAt,
+			},
+			Items: items[o.ID.String()],
+		})
+	}
+
+	for _, p := range data.Payments {
+		export.Payments = append(export.Payments, dto.PaymentReportResponse{
+			PaymentID: p.ID.String(),
+			OrderID:   p.OrderID.String(),
+			Fullname:  p.Fullname,
+			Email:     p.Email,
+			Method:    p.Method,
+			Amount:    p.Amount,
+			Status:    p.Status,
+			PaidAt:    p.PaidAt,
+		})
+	}
+
+	for _, t := range data.Tickets {
+		export.Tickets = append(export.Tickets, dto.UserTicketResponse{
+			ID:         t.ID.String(),
+			EventID:    t.EventID.String(),
+			TicketID:   t.TicketID.String(),
+			TicketName: t.Ticket.Name,
+			EventName:  t.Event.Title,
+			QRCode:     t.QRCode,
+			IsUsed:     t.IsUsed,
+			UsedAt:     t.UsedAt,
+		})
+	}
+
+	for i := range data.Withdrawals {
+		export.Withdrawals = append(export.Withdrawals, *toWithdrawalDTO(&data.Withdrawals[i]))
+	}
+
+	for i := range data.PayoutAccounts {
+		export.PayoutAccounts = append(export.PayoutAccounts, *toPayoutAccountDTO(&data.PayoutAccounts[i]))
+	}
+
+	for i := range data.Sessions {
+		export.Sessions = append(export.Sessions, toSessionDTO(&data.Sessions[i], ""))
+	}
+
+	for _, identity := range data.LinkedIdentities {
+		export.LinkedIdentities = append(export.LinkedIdentities, dto.LinkedIdentityResponse{
+			ID:          identity.ID.String(),
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			LinkedAt:    identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+
+	for _, log := range data.AuditLogs {
+		export.AuditLogs = append(export.AuditLogs, dto.ExportAuditLogResponse{
+			Action:      log.Action,
+			Resource:    log.Resource,
+			Description: log.Description,
+			IP:          log.IP,
+			UserAgent:   log.UserAgent,
+			CreatedAt:   log.CreatedAt,
+		})
+	}
+
+	return export
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+)
+
+const (
+	analyticsDateLayout = "2006-01-02"
+
+	// longest range per granularity, in days
+	maxDailyAnalyticsRange = 366
+	maxAnalyticsRange      = 5 * 366
+)
+
+// GetSalesAnalytics returns the metrics of the range bucketed by local day, week (starting Monday) or month,
+// dates are calendar dates in params.Timezone and both ends are inclusive
+func (s *adminService) GetSalesAnalytics(params dto.AnalyticsQueryParams) (*dto.AnalyticsResponse, error) {
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return nil, response.NewBadRequest("Invalid timezone, use an IANA name such as Asia/Jakarta")
+	}
+	if params.Granularity == "" {
+		params.Granularity = "day"
+	}
+
+	from, to, err := analyticsRange(params, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.buildAnalyticsPeriod(from, to, loc, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.AnalyticsResponse{
+		Granularity:     params.Granularity,
+		Timezone:        loc.String(),
+		AnalyticsPeriod: *current,
+	}
+
+	if params.Compare {
+		prevFrom, prevTo := previousAnalyticsRange(from, to, params.Granularity)
+		previous, err := s.buildAnalyticsPeriod(prevFrom, prevTo, loc, params)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Previous = previous
+		result.Change = &dto.AnalyticsChange{
+			Revenue:      percentChange(current.Totals.Revenue, previous.Totals.Revenue),
+			Orders:       percentChange(float64(current.Totals.Orders), float64(previous.Totals.Orders)),
+			TicketsSold:  percentChange(float64(current.Totals.TicketsSold), float64(previous.Totals.TicketsSold)),
+			Refunds:      percentChange(float64(current.Totals.Refunds), float64(previous.Totals.Refunds)),
+			RefundAmount: percentChange(current.Totals.RefundAmount, previous.Totals.RefundAmount),
+			NewUsers:     percentChange(float64(current.Totals.NewUsers), float64(previous.Totals.NewUsers)),
+		}
+	}
+
+	return result, nil
+}
+
+// buildAnalyticsPeriod loads the daily metrics of the calendar dates [from, to] and folds them into buckets,
+// every bucket of the range is present even when it has no activity
+func (s *adminService) buildAnalyticsPeriod(from, to time.Time, loc *time.Location, params dto.AnalyticsQueryParams) (*dto.AnalyticsPeriod, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+
+	// a day can span two segments when the clocks change, its rows are added up
+	daily := map[string]dto.AnalyticsPoint{}
+	for _, segment := range utils.SplitByUTCOffset(start, end, loc) {
+		rows, err := s.repo.GetDailyMetrics(segment.From, segment.To, segment.Offset, params)
+		if err != nil {
+			return nil, response.NewInternalServerError("failed to retrieve analytics", err)
+		}
+		for _, row := range rows {
+			daily[row.Period] = addAnalyticsPoint(daily[row.Period], row)
+		}
+	}
+
+	period := &dto.AnalyticsPeriod{
+		DateFrom: from.Format(analyticsDateLayout),
+		DateTo:   to.Format(analyticsDateLayout),
+		Series:   []dto.AnalyticsPoint{},
+	}
+
+	index := map[string]int{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := analyticsBucket(day, params.Granularity).Format(analyticsDateLayout)
+		if _, ok := index[key]; !ok {
+			index[key] = len(period.Series)
+			period.Series = append(period.Series, dto.AnalyticsPoint{Period: key})
+		}
+
+		point := daily[day.Format(analyticsDateLayout)]
+		period.Series[index[key]] = addAnalyticsPoint(period.Series[index[key]], point)
+		period.Totals = addAnalyticsPoint(period.Totals, point)
+	}
+
+	for i := range period.Series {
+		period.Series[i].Revenue = roundMoney(period.Series[i].Revenue)
+		period.Series[i].RefundAmount = roundMoney(period.Series[i].RefundAmount)
+	}
+	period.Totals.Revenue = roundMoney(period.Totals.Revenue)
+	period.Totals.RefundAmount = roundMoney(period.Totals.RefundAmount)
+
+	return period, nil
+}
+
+// analyticsRange parses the requested calendar dates as UTC midnights so date arithmetic never meets a
+// daylight saving change, the default is the last 30 days, 12 weeks or 12 months up to today
+func analyticsRange(params dto.AnalyticsQueryParams, loc *time.Location) (time.Time, time.Time, error) {
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if params.DateTo != "" {
+		parsed, err := time.Parse(analyticsDateLayout, params.DateTo)
+		if err != nil {
+			return time.Time{}, time.Time{}, response.NewBadRequest("Invalid dateTo, use YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	var from time.Time
+	switch {
+	case params.DateFrom != "":
+		parsed, err := time.Parse(analyticsDateLayout, params.DateFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, response.NewBadRequest("Invalid dateFrom, use YYYY-MM-DD")
+		}
+		from = parsed
+	case params.Granularity == "week":
+		from = analyticsBucket(to, "week").AddDate(0, 0, -7*11)
+	case params.Granularity == "month":
+		from = analyticsBucket(to, "month").AddDate(0, -11, 0)
+	default:
+		from = to.AddDate(0, 0, -29)
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, response.NewBadRequest("dateFrom must be before dateTo")
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	if params.Granularity == "day" && days > maxDailyAnalyticsRange {
+		return time.Time{}, time.Time{}, response.NewBadRequest("Daily series are limited to 366 days, use weekly or monthly granularity")
+	}
+	if days > maxAnalyticsRange {
+		return time.Time{}, time.Time{}, response.NewBadRequest("Date range is limited to 5 years")
+	}
+
+	return from, to, nil
+}
+
+// previousAnalyticsRange is the period right before [from, to] with the same length, whole months are
+// compared with the same number of whole months
+func previousAnalyticsRange(from, to time.Time, granularity string) (time.Time, time.Time) {
+	if granularity == "month" && from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1 {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+		return from.AddDate(0, -months, 0), from.AddDate(0, 0, -1)
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1)
+}
+
+// analyticsBucket returns the first date of the bucket the date falls in
+func analyticsBucket(day time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+func addAnalyticsPoint(a, b dto.AnalyticsPoint) dto.AnalyticsPoint {
+	a.Revenue += b.Revenue
+	a.Orders += b.Orders
+	a.TicketsSold += b.TicketsSold
+	a.Refunds += b.Refunds
+	a.RefundAmount += b.RefundAmount
+	a.NewUsers += b.NewUsers
+	return a
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := math.Round((current-previous)/previous*10000) / 100
+	return &change
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+)
+
+const attendeeCheckInLayout = "2006-01-02 15:04:05"
+
+// GetEventReport returns the sales, refunds and check-ins of one event, dates and hours are local to
+// params.Timezone as it was on the event date
+func (s *adminService) GetEventReport(eventID string, params dto.EventReportQueryParams) (*dto.EventReportResponse, error) {
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return nil, response.NewBadRequest("Invalid timezone, use an IANA name such as Asia/Jakarta")
+	}
+
+	event, err := s.reportEvent(eventID, params.OrganizerID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetEventReport(eventID, utils.UTCOffsetAt(event.Date, loc))
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to retrieve event report", err)
+	}
+
+	report.EventID = event.ID.String()
+	report.Title = event.Title
+	report.Date = event.Date
+	report.Location = event.Location
+	report.Category = event.Category
+	report.Status = event.Status
+	report.Timezone = loc.String()
+
+	summary := &report.Summary
+	for _, ticket := range event.Tickets {
+		summary.Capacity += ticket.Quota
+	}
+	summary.GrossRevenue = roundMoney(summary.GrossRevenue)
+	summary.RefundedAmount = roundMoney(summary.RefundedAmount)
+	summary.NetRevenue = roundMoney(summary.GrossRevenue - summary.RefundedAmount)
+	summary.NotCheckedIn = summary.TicketsSold - summary.CheckedIn
+	if summary.TicketsSold > 0 {
+		summary.CheckInRate = roundMoney(float64(summary.CheckedIn) / float64(summary.TicketsSold) * 100)
+	}
+
+	// a ticket that was never scanned only counts as a no-show once the event is over
+	summary.EventEnded = eventEnded(event)
+	if summary.EventEnded {
+		summary.NoShows = summary.NotCheckedIn
+	}
+
+	for i := range report.RevenueByTier {
+		report.RevenueByTier[i].Revenue = roundMoney(report.RevenueByTier[i].Revenue)
+		report.RevenueByTier[i].RefundedAmount = roundMoney(report.RevenueByTier[i].RefundedAmount)
+	}
+	checkedIn := 0
+	for i := range report.CheckInsByHour {
+		point := &report.CheckInsByHour[i]
+		checkedIn += point.CheckIns
+		point.CheckedIn = checkedIn
+		if summary.TicketsSold > 0 {
+			point.CheckInRate = roundMoney(float64(checkedIn) / float64(summary.TicketsSold) * 100)
+		}
+	}
+	sort.Slice(report.OrdersOverTime, func(i, j int) bool {
+		return report.OrdersOverTime[i].Date < report.OrdersOverTime[j].Date
+	})
+
+	if report.RevenueByTier == nil {
+		report.RevenueByTier = []dto.TierRevenueResponse{}
+	}
+	if report.CheckInsByHour == nil {
+		report.CheckInsByHour = []dto.EventCheckInPoint{}
+	}
+
+	return report, nil
+}
+
+// GetEventAttendees returns the attendee manifest of the event, the check-in time is local to params.Timezone
+func (s *adminService) GetEventAttendees(eventID string, params dto.EventAttendeeQueryParams) ([]dto.AttendeeResponse, int, error) {
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return nil, 0, response.NewBadRequest("Invalid timezone, use an IANA name such as Asia/Jakarta")
+	}
+
+	if _, err := s.reportEvent(eventID, params.OrganizerID); err != nil {
+		return nil, 0, err
+	}
+
+	list, total, err := s.repo.GetEventAttendees(eventID, params)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError("failed to retrieve attendees", err)
+	}
+
+	var result []dto.AttendeeResponse
+	for _, a := range list {
+		result = append(result, toAttendeeResponse(a, loc))
+	}
+
+	return result, int(total), nil
+}
+
+func toAttendeeResponse(a repositories.EventAttendee, loc *time.Location) dto.AttendeeResponse {
+	checkInTime := ""
+	if a.UsedAt != nil {
+		checkInTime = a.UsedAt.In(loc).Format(attendeeCheckInLayout)
+	}
+
+	return dto.AttendeeResponse{
+		TicketID:    a.TicketID,
+		Name:        a.Name,
+		Email:       a.Email,
+		Phone:       a.Phone,
+		Tier:        a.Tier,
+		CheckInTime: checkInTime,
+	}
+}
+
+// reportEvent loads the event of a report, organizers get a not found for events they don't own
+func (s *adminService) reportEvent(eventID, organizerID string) (*models.Event, error) {
+	event, err := s.repo.GetEventByID(eventID)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to retrieve event", err)
+	}
+	if event == nil {
+		return nil, response.NewNotFound("Event not found")
+	}
+	if organizerID != "" && (event.OrganizerID == nil || event.OrganizerID.String() != organizerID) {
+		return nil, response.NewNotFound("Event not found")
+	}
+	return event, nil
+}
+
+// eventEnded is true once the event is done or its last hour has passed
+func eventEnded(event *models.Event) bool {
+	if event.Status == "done" {
+		return true
+	}
+	day := time.Date(event.Date.Year(), event.Date.Month(), event.Date.Day(), 0, 0, 0, 0, event.Date.Location())
+	return time.Now().After(day.Add(time.Duration(event.EndTime) * time.Hour))
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+)
+
+// The Stream methods feed the whole filtered report to fn row by row, straight from a database cursor.
+// They back the exports, which cover every matching row instead of the requested page
+
+func (s *adminService) StreamOrderReports(params dto.OrderReportQueryParams, fn func(dto.OrderReportResponse) error) error {
+	if err := s.repo.StreamOrderReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export order reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamTicketSalesReports(params dto.TicketReportQueryParams, fn func(dto.TicketSalesReportResponse) error) error {
+	if err := s.repo.StreamTicketSalesReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export ticket sales reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamPaymentReports(params dto.PaymentReportQueryParams, fn func(dto.PaymentReportResponse) error) error {
+	if err := s.repo.StreamPaymentReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export payment reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamRefundReports(params dto.RefundReportQueryParams, fn func(dto.RefundReportResponse) error) error {
+	if err := s.repo.StreamRefundReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export refund reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamWithdrawalReports(params dto.WithdrawalReportQueryParams, fn func(dto.WithdrawalReportResponse) error) error {
+	if err := s.repo.StreamWithdrawalReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export withdrawal reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams, fn func(dto.WithdrawalViolationReportResponse) error) error {
+	if err := s.repo.StreamWithdrawalViolationReports(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export withdrawal violation reports", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamOrganizerEarnings(params dto.OrganizerEarningQueryParams, fn func(dto.OrganizerEarningResponse) error) error {
+	if err := s.repo.StreamOrganizerEarnings(params, fn); err != nil {
+		return response.NewInternalServerError("failed to export organizer earnings", err)
+	}
+	return nil
+}
+
+func (s *adminService) StreamEventAttendees(eventID string, params dto.EventAttendeeQueryParams, fn func(dto.AttendeeResponse) error) error {
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return response.NewBadRequest("Invalid timezone, use an IANA name such as Asia/Jakarta")
+	}
+
+	if _, err := s.reportEvent(eventID, params.OrganizerID); err != nil {
+		return err
+	}
+
+	if err := s.repo.StreamEventAttendees(eventID, params, func(a repositories.EventAttendee) error {
+		return fn(toAttendeeResponse(a, loc))
+	}); err != nil {
+		return response.NewInternalServerError("failed to export attendees", err)
+	}
+	return nil
+}
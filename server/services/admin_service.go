@@ -17,6 +17,21 @@ type AdminService interface {
 	GetPaymentReports(params dto.PaymentReportQueryParams) ([]dto.PaymentReportResponse, int, error)
 	GetTicketSalesReports(params dto.TicketReportQueryParams) ([]dto.TicketSalesReportResponse, int, error)
 	GetWithdrawalReports(params dto.WithdrawalReportQueryParams) ([]dto.WithdrawalReportResponse, int, error)
+	GetWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams) ([]dto.WithdrawalViolationReportResponse, int, error)
+	GetOrganizerEarnings(params dto.OrganizerEarningQueryParams) ([]dto.OrganizerEarningResponse, int, error)
+	GetOrganizerSummary(organizerID string) (*dto.OrganizerSummaryResponse, error)
+	GetSalesAnalytics(params dto.AnalyticsQueryParams) (*dto.AnalyticsResponse, error)
+	GetEventReport(eventID string, params dto.EventReportQueryParams) (*dto.EventReportResponse, error)
+	GetEventAttendees(eventID string, params dto.EventAttendeeQueryParams) ([]dto.AttendeeResponse, int, error)
+
+	StreamOrderReports(params dto.OrderReportQueryParams, fn func(dto.OrderReportResponse) error) error
+	StreamTicketSalesReports(params dto.TicketReportQueryParams, fn func(dto.TicketSalesReportResponse) error) error
+	StreamPaymentReports(params dto.PaymentReportQueryParams, fn func(dto.PaymentReportResponse) error) error
+	StreamRefundReports(params dto.RefundReportQueryParams, fn func(dto.RefundReportResponse) error) error
+	StreamWithdrawalReports(params dto.WithdrawalReportQueryParams, fn func(dto.WithdrawalReportResponse) error) error
+	StreamWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams, fn func(dto.WithdrawalViolationReportResponse) error) error
+	StreamOrganizerEarnings(params dto.OrganizerEarningQueryParams, fn func(dto.OrganizerEarningResponse) error) error
+	StreamEventAttendees(eventID string, params dto.EventAttendeeQueryParams, fn func(dto.AttendeeResponse) error) error
 }
 
 type adminService struct {
@@ -81,6 +96,7 @@ func (s *adminService) GetAllEvents(params dto.EventQueryParams) ([]dto.AdminEve
 			Title:       item.Title,
 			Description: item.Description,
 			Location:    item.Location,
+			Category:    item.Category,
 			StartPrice:  startPrice,
 			IsAvailable: isAvailable,
 			StartTime:   item.StartTime,
@@ -91,6 +107,8 @@ func (s *adminService) GetAllEvents(params dto.EventQueryParams) ([]dto.AdminEve
 			UpdatedAt:   item.UpdatedAt,
 
 			// Admin-specific fields
+			OrganizerID:          organizerIDString(item.OrganizerID),
+			PlatformFeePercent:   item.PlatformFeePercent,
 			TicketCount:          ticketCount,
 			TotalQuota:           totalQuota,
 			TotalSold:            totalSold,
@@ -138,6 +156,7 @@ func (s *adminService) GetAllUsers(params dto.UserQueryParams) ([]dto.UserListRe
 			ID:       u.ID.String(),
 			Email:    u.Email,
 			Role:     u.Role,
+			Status:   u.Status,
 			Avatar:   u.Avatar,
 			Fullname: u.Fullname,
 			JoinedAt: u.CreatedAt,
@@ -251,6 +270,7 @@ func (s *adminService) GetWithdrawalReports(params dto.WithdrawalReportQueryPara
 			Fullname:     w.User.Fullname,
 			Email:        w.User.Email,
 			Amount:       w.Amount,
+			Fee:          w.Fee,
 			Status:       w.Status,
 			Reason:       w.Reason,
 			CreatedAt:    w.CreatedAt,
@@ -260,3 +280,62 @@ func (s *adminService) GetWithdrawalReports(params dto.WithdrawalReportQueryPara
 
 	return result, int(total), nil
 }
+
+func (s *adminService) GetWithdrawalViolationReports(params dto.WithdrawalViolationQueryParams) ([]dto.WithdrawalViolationReportResponse, int, error) {
+	list, total, err := s.repo.GetWithdrawalViolationReports(params)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError("failed to retrieve withdrawal violation reports", err)
+	}
+
+	var result []dto.WithdrawalViolationReportResponse
+	for _, v := range list {
+		result = append(result, dto.WithdrawalViolationReportResponse{
+			ViolationID: v.ID.String(),
+			UserID:      v.UserID.String(),
+			Fullname:    v.User.Fullname,
+			Email:       v.User.Email,
+			Rule:        v.Rule,
+			Amount:      v.Amount,
+			Message:     v.Message,
+			CreatedAt:   v.CreatedAt,
+		})
+	}
+
+	return result, int(total), nil
+}
+
+func (s *adminService) GetOrganizerEarnings(params dto.OrganizerEarningQueryParams) ([]dto.OrganizerEarningResponse, int, error) {
+	list, total, err := s.repo.GetOrganizerEarnings(params)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError("failed to retrieve organizer earnings", err)
+	}
+
+	var result []dto.OrganizerEarningResponse
+	for _, e := range list {
+		result = append(result, dto.OrganizerEarningResponse{
+			EarningID:      e.ID.String(),
+			OrganizerID:    e.OrganizerID.String(),
+			OrganizerName:  e.Organizer.Fullname,
+			EventTitle:     e.Event.Title,
+			OrderID:        e.OrderID.String(),
+			GrossAmount:    e.GrossAmount,
+			RefundedAmount: e.RefundedAmount,
+			FeePercent:     e.FeePercent,
+			PlatformFee:    e.PlatformFee,
+			NetAmount:      e.NetAmount,
+			Status:         e.Status,
+			SettledAt:      e.SettledAt,
+			CreatedAt:      e.CreatedAt,
+		})
+	}
+
+	return result, int(total), nil
+}
+
+func (s *adminService) GetOrganizerSummary(organizerID string) (*dto.OrganizerSummaryResponse, error) {
+	summary, err := s.repo.GetOrganizerSummary(organizerID)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to retrieve organizer summary", err)
+	}
+	return summary, nil
+}
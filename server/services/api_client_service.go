@@ -0,0 +1,153 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+)
+
+type APIClientService interface {
+	GetScopes() []string
+	GetClients(includeRevoked bool) ([]dto.APIClientResponse, error)
+	GetClientByID(id string) (*dto.APIClientResponse, error)
+	IssueClient(adminID string, req *dto.CreateAPIClientRequest) (*dto.APIClientKeyResponse, error)
+	RotateClientKey(id string) (*dto.APIClientKeyResponse, error)
+	RevokeClient(id string) error
+}
+
+type apiClientService struct {
+	repo repositories.APIClientRepository
+}
+
+func NewAPIClientService(repo repositories.APIClientRepository) APIClientService {
+	return &apiClientService{repo}
+}
+
+func (s *apiClientService) GetScopes() []string {
+	return utils.APIScopes
+}
+
+func (s *apiClientService) GetClients(includeRevoked bool) ([]dto.APIClientResponse, error) {
+	clients, err := s.repo.GetAllClients(includeRevoked)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to get API clients", err)
+	}
+
+	results := make([]dto.APIClientResponse, 0, len(clients))
+	for i := range clients {
+		results = append(results, toAPIClientDTO(&clients[i]))
+	}
+	return results, nil
+}
+
+func (s *apiClientService) GetClientByID(id string) (*dto.APIClientResponse, error) {
+	client, err := s.repo.GetClientByID(id)
+	if err != nil || client == nil {
+		return nil, response.NewNotFound("API client not found")
+	}
+
+	res := toAPIClientDTO(client)
+	return &res, nil
+}
+
+// IssueClient creates the client and returns its key, the key is not stored and cannot be shown again
+func (s *apiClientService) IssueClient(adminID string, req *dto.CreateAPIClientRequest) (*dto.APIClientKeyResponse, error) {
+	for _, scope := range req.Scopes {
+		if !utils.ValidAPIScope(scope) {
+			return nil, response.NewBadRequest("Unknown scope: " + scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return nil, response.NewBadRequest("Expiry must be in the future")
+	}
+
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate API key", err)
+	}
+
+	client := &models.APIClient{
+		Name:      req.Name,
+		KeyPrefix: prefix,
+		KeyHash:   utils.HashAPIKey(key),
+		Scopes:    strings.Join(req.Scopes, ","),
+		RateLimit: 60,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: adminID,
+	}
+	if req.RateLimit != nil {
+		client.RateLimit = *req.RateLimit
+	}
+
+	if err := s.repo.CreateClient(client); err != nil {
+		return nil, response.NewInternalServerError("Failed to create API client", err)
+	}
+
+	return &dto.APIClientKeyResponse{Client: toAPIClientDTO(client), APIKey: key}, nil
+}
+
+// RotateClientKey replaces the key, the previous key stops working immediately
+func (s *apiClientService) RotateClientKey(id string) (*dto.APIClientKeyResponse, error) {
+	client, err := s.repo.GetClientByID(id)
+	if err != nil || client == nil {
+		return nil, response.NewNotFound("API client not found")
+	}
+
+	if client.RevokedAt != nil {
+		return nil, response.NewBadRequest("API client has been revoked")
+	}
+
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate API key", err)
+	}
+
+	client.KeyPrefix = prefix
+	client.KeyHash = utils.HashAPIKey(key)
+	if err := s.repo.UpdateClient(client); err != nil {
+		return nil, response.NewInternalServerError("Failed to rotate API key", err)
+	}
+
+	return &dto.APIClientKeyResponse{Client: toAPIClientDTO(client), APIKey: key}, nil
+}
+
+func (s *apiClientService) RevokeClient(id string) error {
+	client, err := s.repo.GetClientByID(id)
+	if err != nil || client == nil {
+		return response.NewNotFound("API client not found")
+	}
+
+	if client.RevokedAt != nil {
+		return response.NewBadRequest("API client already revoked")
+	}
+
+	now := time.Now()
+	client.RevokedAt = &now
+	if err := s.repo.UpdateClient(client); err != nil {
+		return response.NewInternalServerError("Failed to revoke API client", err)
+	}
+	return nil
+}
+
+func toAPIClientDTO(c *models.APIClient) dto.APIClientResponse {
+	return dto.APIClientResponse{
+		ID:         c.ID.String(),
+		Name:       c.Name,
+		KeyPrefix:  c.KeyPrefix,
+		Scopes:     strings.Split(c.Scopes, ","),
+		RateLimit:  c.RateLimit,
+		ExpiresAt:  c.ExpiresAt,
+		LastUsedAt: c.LastUsedAt,
+		LastUsedIP: c.LastUsedIP,
+		RevokedAt:  c.RevokedAt,
+		CreatedBy:  c.CreatedBy,
+		CreatedAt:  c.CreatedAt,
+	}
+}
@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
@@ -13,7 +17,6 @@ import (
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 	"golang.org/x/oauth2"
-	"google.golang.org/api/idtoken"
 
 	"github.com/fiqrioemry/go-api-toolkit/response"
 	"github.com/gin-gonic/gin"
@@ -24,27 +27,55 @@ import (
 type AuthService interface {
 	ResendOTP(email string) error
 	Register(req *dto.RegisterRequest) error
-	Login(req *dto.LoginRequest) (*dto.AuthResponse, error)
-	VerifyOTP(email, otp string) (*dto.AuthResponse, error)
-	RefreshToken(c *gin.Context, refreshToken string) (*dto.ProfileResponse, string, error)
+	Login(req *dto.LoginRequest, client utils.ClientInfo) (*dto.AuthResponse, error)
+	RequestMagicLink(req *dto.MagicLinkRequest) error
+	VerifyMagicLink(req *dto.VerifyMagicLinkRequest, client utils.ClientInfo) (*dto.AuthResponse, error)
+	VerifyOTP(email, otp string, client utils.ClientInfo) (*dto.AuthResponse, error)
+	RefreshToken(refreshToken string, client utils.ClientInfo) (*dto.AuthResponse, error)
+
+	// session management
+	Logout(refreshToken string, sessionID string) error
+	LogoutAll(userID string) error
+
+	// two-factor authentication
+	SetupTwoFactor(userID string) (*dto.TwoFactorSetupResponse, error)
+	EnableTwoFactor(userID string, sessionID string, code string) (*dto.RecoveryCodesResponse, error)
+	DisableTwoFactor(userID string, code string) error
+	RegenerateRecoveryCodes(userID string, code string) (*dto.RecoveryCodesResponse, error)
+	VerifyTwoFactor(req *dto.VerifyTwoFactorRequest, client utils.ClientInfo) (*dto.AuthResponse, error)
+	ResetTwoFactor(adminID string, userID string) error
+
+	// admin user management
+	GetUserAccount(userID string) (*dto.AdminUserResponse, error)
+	UpdateUserStatus(adminID string, userID string, req *dto.UpdateUserStatusRequest) (*dto.AdminUserResponse, error)
+	UnlockUser(userID string) (*dto.AdminUserResponse, error)
+	ForcePasswordReset(adminID string, userID string) (*dto.AdminUserResponse, error)
+
+	// support impersonation
+	StartImpersonation(adminID string, userID string) (*dto.ImpersonationResponse, error)
+	StopImpersonation(sessionID string)
 
 	// password reset features
 	ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequest) error
 	ValidateToken(token string) (string, error)
 	ResetPassword(req *dto.ResetPasswordRequest) error
 
-	// Google OAuth features
-	GetGoogleOAuthURL() string
-	GoogleSignIn(tokenId string) (*dto.AuthResponse, error)
-	HandleGoogleOAuthCallback(code string) (*dto.AuthResponse, error)
+	// OAuth/OIDC sign-in and linked identities
+	GetOAuthProviders() []string
+	GetOAuthURL(provider, returnTo, linkUserID string) (string, string, error)
+	HandleOAuthCallback(provider, code, state string, client utils.ClientInfo) (*dto.OAuthCallbackResult, error)
+	GetLinkedIdentities(userID string) ([]dto.LinkedIdentityResponse, error)
+	ConfirmIdentityLink(userID string, linkToken string) error
+	UnlinkIdentity(userID string, provider string) error
 }
 
 type authService struct {
-	user repositories.UserRepository
+	user    repositories.UserRepository
+	session repositories.SessionRepository
 }
 
-func NewAuthService(user repositories.UserRepository) AuthService {
-	return &authService{user: user}
+func NewAuthService(user repositories.UserRepository, session repositories.SessionRepository) AuthService {
+	return &authService{user: user, session: session}
 }
 
 func (s *authService) Register(req *dto.RegisterRequest) error {
@@ -169,7 +200,7 @@ func (s *authService) ResendOTP(email string) error {
 	return nil
 }
 
-func (s *authService) VerifyOTP(email, otp string) (*dto.AuthResponse, error) {
+func (s *authService) VerifyOTP(email, otp string, client utils.ClientInfo) (*dto.AuthResponse, error) {
 	otpKey := "ticket:otp:" + email
 	otpDataKey := "ticket:otp_data:" + email
 
@@ -229,14 +260,9 @@ func (s *authService) VerifyOTP(email, otp string) (*dto.AuthResponse, error) {
 	}
 
 	// Generate tokens
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role)
+	accessToken, refreshToken, err := s.startSession(&user, client, false)
 	if err != nil {
-		return nil, response.NewInternalServerError("Failed to generate access token", err)
-	}
-
-	refreshToken, err := utils.GenerateRefreshToken(user.ID.String())
-	if err != nil {
-		return nil, response.NewInternalServerError("Failed to generate refresh token", err)
+		return nil, err
 	}
 
 	// Clean up Redis data after successful verification
@@ -260,10 +286,10 @@ func (s *authService) VerifyOTP(email, otp string) (*dto.AuthResponse, error) {
 	}, nil
 }
 
-func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
-	redisKey := fmt.Sprintf("login:attempt:%s", req.Email)
+func (s *authService) Login(req *dto.LoginRequest, client utils.ClientInfo) (*dto.AuthResponse, error) {
+	redisKey := loginAttemptsKey(req.Email)
 	attempts, _ := config.RedisClient.Get(config.Ctx, redisKey).Int()
-	if attempts >= 5 {
+	if attempts >= maxLoginAttempts {
 		return nil, response.NewTooManyRequests("Too many login attempts, please try again later")
 	}
 
@@ -276,14 +302,21 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 
 	config.RedisClient.Del(config.Ctx, redisKey)
 
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role)
-	if err != nil {
-		return nil, response.NewInternalServerError("Failed to generate access token", err)
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+	if user.PasswordResetRequired {
+		return nil, response.NewForbidden("A password reset is required, use the link sent to your email")
+	}
+
+	// second step, tokens are only issued once the TOTP/recovery code is verified
+	if user.TwoFactorEnabled {
+		return s.createTwoFactorChallenge(user)
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID.String())
+	accessToken, refreshToken, err := s.startSession(user, client, false)
 	if err != nil {
-		return nil, response.NewInternalServerError("Failed to generate refresh token", err)
+		return nil, err
 	}
 
 	userResponse := dto.ProfileResponse{
@@ -303,16 +336,215 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
 	}, nil
 }
 
-func (s *authService) RefreshToken(c *gin.Context, refreshToken string) (*dto.ProfileResponse, string, error) {
+// RequestMagicLink emails a single-use sign-in link and code. Sends have their own counter so that
+// requesting links for someone else's email cannot lock them out of password login, only failed
+// verifications count towards the login lockout. The result is the same whether the email has an
+// account or not, failures past the rate limit are only logged
+func (s *authService) RequestMagicLink(req *dto.MagicLinkRequest) error {
+	sendKey := magicLinkSendKey(req.Email)
+	if err := utils.CheckAttempts(sendKey, maxMagicLinkSends); err != nil {
+		return response.NewTooManyRequests("Too many sign-in link requests, please try again later")
+	}
+	utils.IncrementAttempts(sendKey)
 
-	userID, err := utils.DecodeRefreshToken(refreshToken)
+	user, err := s.user.GetUserByEmail(req.Email)
+	if err != nil || user == nil {
+		return nil // Don't reveal if email exists
+	}
+
+	if err := s.sendMagicLink(user); err != nil {
+		log.Printf("failed to send sign-in link to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *authService) sendMagicLink(user *models.User) error {
+	token, err := utils.NewMagicLinkToken()
 	if err != nil {
-		return nil, "", response.NewUnauthorized("Invalid refresh token")
+		return err
 	}
 
-	user, err := s.user.GetUserByID(userID)
+	code, err := utils.GenerateSecureCode(6)
+	if err != nil {
+		return err
+	}
+
+	// only the latest link of an email stays valid
+	tokenKey, _ := utils.ParseMagicLinkToken(token)
+	emailKey := "ticket:magic_link_email:" + user.Email
+	var previous string
+	if err := utils.GetKey(emailKey, &previous); err == nil {
+		utils.DeleteKeys(magicLinkKey(previous))
+	}
+
+	data := magicLinkData{UserID: user.ID.String(), Email: user.Email, Code: code}
+	if err := utils.AddKeys(magicLinkKey(tokenKey), data, magicLinkTTL); err != nil {
+		return err
+	}
+	if err := utils.AddKeys(emailKey, tokenKey, magicLinkTTL); err != nil {
+		utils.DeleteKeys(magicLinkKey(tokenKey))
+		return err
+	}
+
+	loginLink := fmt.Sprintf("%s/magic-login?token=%s", config.AppConfig.FrontendURL, token)
+	if err := utils.SendMagicLinkEmail(user.Email, user.Fullname, loginLink, code, magicLinkTTL); err != nil {
+		utils.DeleteKeys(magicLinkKey(tokenKey), emailKey)
+		return err
+	}
+
+	return nil
+}
+
+// VerifyMagicLink redeems either the signed link token or the email and 6-digit code,
+// whichever comes first consumes both
+func (s *authService) VerifyMagicLink(req *dto.VerifyMagicLinkRequest, client utils.ClientInfo) (*dto.AuthResponse, error) {
+	var tokenKey string
+	var data magicLinkData
+
+	if req.Token != "" {
+		var ok bool
+		tokenKey, ok = utils.ParseMagicLinkToken(req.Token)
+		if !ok || utils.GetKey(magicLinkKey(tokenKey), &data) != nil {
+			return nil, response.NewBadRequest("Sign-in link is invalid or has expired")
+		}
+		if err := utils.CheckAttempts(loginAttemptsKey(data.Email), maxLoginAttempts); err != nil {
+			return nil, response.NewTooManyRequests("Too many login attempts, please try again later")
+		}
+	} else {
+		attemptsKey := loginAttemptsKey(req.Email)
+		if err := utils.CheckAttempts(attemptsKey, maxLoginAttempts); err != nil {
+			return nil, response.NewTooManyRequests("Too many login attempts, please try again later")
+		}
+
+		if utils.GetKey("ticket:magic_link_email:"+req.Email, &tokenKey) != nil ||
+			utils.GetKey(magicLinkKey(tokenKey), &data) != nil ||
+			subtle.ConstantTimeCompare([]byte(data.Code), []byte(req.Code)) != 1 {
+			utils.IncrementAttempts(attemptsKey)
+			return nil, response.NewBadRequest("Invalid or expired sign-in code")
+		}
+	}
+
+	// deleting the key is the claim, a concurrent redeem of the same link gets 0
+	claimed, err := config.RedisClient.Del(config.Ctx, magicLinkKey(tokenKey)).Result()
+	if err != nil || claimed == 0 {
+		return nil, response.NewBadRequest("Sign-in link has already been used")
+	}
+	utils.DeleteKeys("ticket:magic_link_email:"+data.Email, loginAttemptsKey(data.Email))
+
+	user, err := s.user.GetUserByID(data.UserID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return s.createTwoFactorChallenge(user)
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User: dto.ProfileResponse{
+			ID:               user.ID.String(),
+			Email:            user.Email,
+			Fullname:         user.Fullname,
+			Balance:          user.Balance,
+			Avatar:           user.Avatar,
+			Role:             user.Role,
+			JoinedAt:         user.CreatedAt,
+			TwoFactorEnabled: user.TwoFactorEnabled,
+		},
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+type magicLinkData struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+	Code   string `json:"code"`
+}
+
+const (
+	maxLoginAttempts  = 5
+	maxMagicLinkSends = 5
+	magicLinkTTL      = 15 * time.Minute
+)
+
+func loginAttemptsKey(email string) string {
+	return "login:attempt:" + email
+}
+
+func magicLinkSendKey(email string) string {
+	return "login:magic_link_send:" + email
+}
+
+func magicLinkKey(token string) string {
+	return "ticket:magic_link:" + token
+}
+
+// RefreshToken rotates the presented refresh token, presenting a token that was already rotated
+// means it leaked, so the whole session (token family) is revoked
+func (s *authService) RefreshToken(refreshToken string, client utils.ClientInfo) (*dto.AuthResponse, error) {
+	claims, err := utils.DecodeRefreshToken(refreshToken)
+	if err != nil {
+		return nil, response.NewUnauthorized("Invalid refresh token")
+	}
+
+	current, err := s.session.GetRefreshToken(claims.ID)
+	if err != nil || current == nil {
+		return nil, response.NewUnauthorized("Invalid refresh token")
+	}
+
+	session := current.Session
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, response.NewUnauthorized("Session has expired, please login again")
+	}
+
+	if current.RotatedAt != nil {
+		s.revokeSession(session.ID.String(), "refresh_token_reuse")
+		return nil, response.NewUnauthorized("Refresh token has already been used, session revoked")
+	}
+
+	// a cookie session can't be refreshed through the native endpoint and the other way round
+	if session.Client != sessionClient(client) {
+		return nil, response.NewUnauthorized("Refresh token was issued for another client type")
+	}
+
+	user, err := s.user.GetUserByID(session.UserID.String())
 	if err != nil || user == nil {
-		return nil, "", response.NewNotFound("User not found").WithContext("userID", userID)
+		return nil, response.NewNotFound("User not found").WithContext("userID", session.UserID.String())
+	}
+
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	next := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: session.ID,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL),
+	}
+
+	newRefreshToken, err := utils.GenerateRefreshToken(user.ID.String(), session.ID.String(), next.ID.String())
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate refresh token", err)
+	}
+
+	if err := s.session.RotateRefreshToken(current.ID.String(), next, client.IP); err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenReused) {
+			s.revokeSession(session.ID.String(), "refresh_token_reuse")
+			return nil, response.NewUnauthorized("Refresh token has already been used, session revoked")
+		}
+		return nil, response.NewInternalServerError("Failed to rotate refresh token", err)
+	}
+
+	// a session keeps its second factor only as long as the user has two-factor enabled
+	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role, session.ID.String(), session.TwoFactor && user.TwoFactorEnabled)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate access token", err)
 	}
 
 	userResponse := dto.ProfileResponse{
@@ -325,12 +557,99 @@ func (s *authService) RefreshToken(c *gin.Context, refreshToken string) (*dto.Pr
 		Role:     user.Role,
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role)
+	return &dto.AuthResponse{
+		User:         userResponse,
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// Logout revokes the session behind the refresh token, falling back to the access token's session
+func (s *authService) Logout(refreshToken string, sessionID string) error {
+	if claims, err := utils.DecodeRefreshToken(refreshToken); err == nil {
+		sessionID = claims.SessionID
+	}
+
+	if sessionID == "" {
+		return nil
+	}
+
+	if err := s.session.RevokeSession(sessionID, "logout"); err != nil {
+		return response.NewInternalServerError("Failed to revoke session", err)
+	}
+	utils.MarkSessionRevoked(sessionID)
+
+	return nil
+}
+
+func (s *authService) LogoutAll(userID string) error {
+	ids, err := s.session.RevokeUserSessions(userID, "logout_all")
+	if err != nil {
+		return response.NewInternalServerError("Failed to revoke sessions", err)
+	}
+
+	for _, id := range ids {
+		utils.MarkSessionRevoked(id)
+	}
+
+	return nil
+}
+
+// startSession opens a server-side session for a fresh login and issues its first token pair, twoFactor
+// tells whether the login passed a second factor check and is carried by every token of the session
+func (s *authService) startSession(user *models.User, client utils.ClientInfo, twoFactor bool) (string, string, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	session := &models.UserSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		Device:     client.Device,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		Location:   client.Location,
+		Client:     sessionClient(client),
+		TwoFactor:  twoFactor,
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+		LastUsedAt: now,
+	}
+	token := &models.RefreshToken{
+		ID:        uuid.New(),
+		ExpiresAt: session.ExpiresAt,
+	}
+
+	if err := s.session.CreateSession(session, token); err != nil {
+		return "", "", response.NewInternalServerError("Failed to create session", err)
+	}
+
+	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role, session.ID.String(), twoFactor)
+	if err != nil {
+		return "", "", response.NewInternalServerError("Failed to generate access token", err)
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken(user.ID.String(), session.ID.String(), token.ID.String())
 	if err != nil {
-		return nil, "", response.NewInternalServerError("Failed to generate access token", err)
+		return "", "", response.NewInternalServerError("Failed to generate refresh token", err)
 	}
 
-	return &userResponse, accessToken, nil
+	return accessToken, refreshToken, nil
+}
+
+func sessionClient(client utils.ClientInfo) string {
+	if client.Native {
+		return "native"
+	}
+	return "web"
+}
+
+// revokeSession also flags the session in redis so access tokens already issued for it stop working
+func (s *authService) revokeSession(sessionID string, reason string) {
+	if err := s.session.RevokeSession(sessionID, reason); err != nil {
+		log.Printf("failed to revoke session %s: %v", sessionID, err)
+	}
+	utils.MarkSessionRevoked(sessionID)
 }
 
 func (s *authService) ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequest) error {
@@ -359,10 +678,31 @@ func (s *authService) ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequ
 		return nil // Don't reveal if email exists
 	}
 
+	resetLink, tokenKeys, err := createPasswordResetLink(user)
+	if err != nil {
+		return err
+	}
+
+	// Send reset password email
+	if err := utils.SendResetPasswordEmail(user.Email, user.Fullname, resetLink, 1*time.Hour); err != nil {
+		// Clean up tokens
+		utils.DeleteKeys(tokenKeys...)
+		return response.NewInternalServerError("Failed to send reset password email", err)
+	}
+
+	// Increment attempts
+	go utils.IncrementAttempts(attemptsKey)
+
+	return nil
+}
+
+// createPasswordResetLink stores a one-hour reset token for the user and returns its link together with
+// the keys to delete if the email can't be sent
+func createPasswordResetLink(user *models.User) (string, []string, error) {
 	// Generate reset token
 	resetToken, err := utils.GenerateResetToken()
 	if err != nil {
-		return response.NewInternalServerError("Failed to generate reset token", err)
+		return "", nil, response.NewInternalServerError("Failed to generate reset token", err)
 	}
 
 	// Prepare token data
@@ -376,7 +716,7 @@ func (s *authService) ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequ
 	// Store reset token data
 	resetTokenKey := "asset_app:password_reset:" + resetToken
 	if err := utils.AddKeys(resetTokenKey, tokenData, 1*time.Hour); err != nil {
-		return response.NewInternalServerError("Failed to store reset token", err)
+		return "", nil, response.NewInternalServerError("Failed to store reset token", err)
 	}
 
 	// Store email -
@@ -384,7 +724,7 @@ func (s *authService) ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequ
 	if err := utils.AddKeys(emailTokenKey, resetToken, 1*time.Hour); err != nil {
 		// Clean up reset token
 		utils.DeleteKeys(resetTokenKey)
-		return response.NewInternalServerError("Failed to store email token mapping", err)
+		return "", nil, response.NewInternalServerError("Failed to store email token mapping", err)
 	}
 
 	// Create reset link
@@ -392,17 +732,7 @@ func (s *authService) ForgotPassword(c *gin.Context, req *dto.ForgotPasswordRequ
 
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", frontendURL, resetToken)
 
-	// Send reset password email
-	if err := utils.SendResetPasswordEmail(user.Email, user.Fullname, resetLink, 1*time.Hour); err != nil {
-		// Clean up tokens
-		utils.DeleteKeys(resetTokenKey, emailTokenKey)
-		return response.NewInternalServerError("Failed to send reset password email", err)
-	}
-
-	// Increment attempts
-	go utils.IncrementAttempts(attemptsKey)
-
-	return nil
+	return resetLink, []string{resetTokenKey, emailTokenKey}, nil
 }
 
 func (s *authService) ResetPassword(req *dto.ResetPasswordRequest) error {
@@ -442,8 +772,9 @@ func (s *authService) ResetPassword(req *dto.ResetPasswordRequest) error {
 		return response.NewInternalServerError("Failed to hash password", err)
 	}
 
-	// update user password
+	// update user password, this also completes a reset forced by an admin
 	user.Password = hashedPassword
+	user.PasswordResetRequired = false
 
 	if err := s.user.UpdateUser(user); err != nil {
 		return response.NewInternalServerError("Failed to update password", err)
@@ -485,69 +816,791 @@ func (s *authService) ValidateToken(token string) (string, error) {
 
 }
 
-func (s *authService) GoogleSignIn(tokenId string) (*dto.AuthResponse, error) {
-	payload, err := idtoken.Validate(context.Background(), tokenId, config.AppConfig.GoogleClientID)
+// GetOAuthProviders lists the providers that are configured for sign-in and linking
+func (s *authService) GetOAuthProviders() []string {
+	providers := make([]string, 0, len(config.OAuthProviders))
+	for name := range config.OAuthProviders {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// GetOAuthURL starts a provider login, the per-request state keeps the PKCE verifier, nonce and return URL
+// in redis and is also returned so the handler can bind it to the browser with a cookie.
+// linkUserID is set when a signed-in user links the provider from the profile
+func (s *authService) GetOAuthURL(providerName, returnTo, linkUserID string) (string, string, error) {
+	provider, ok := config.OAuthProviders[providerName]
+	if !ok {
+		return "", "", response.NewNotFound("Sign-in provider is not available")
+	}
+
+	returnURL, err := utils.ResolveReturnURL(returnTo)
 	if err != nil {
-		return nil, response.NewUnauthorized("Invalid Google ID token")
+		return "", "", response.NewBadRequest("Invalid return URL")
 	}
 
-	email, ok := payload.Claims["email"].(string)
-	if !ok || email == "" {
-		return nil, response.NewNotFound("Email not found in token")
+	state, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", response.NewInternalServerError("Failed to generate OAuth state", err)
 	}
 
-	name, _ := payload.Claims["name"].(string)
+	nonce, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return "", "", response.NewInternalServerError("Failed to generate OAuth nonce", err)
+	}
+
+	data := utils.OAuthState{
+		Provider:     provider.Name,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		ReturnURL:    returnURL,
+		LinkUserID:   linkUserID,
+	}
+	if err := utils.AddKeys(oauthStateKey(state), data, oauthStateTTL); err != nil {
+		return "", "", response.NewInternalServerError("Failed to store OAuth state", err)
+	}
+
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(data.CodeVerifier)}
+	if provider.Kind == "google" || provider.Kind == "oidc" {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", nonce))
+	}
+
+	return provider.Config.AuthCodeURL(state, opts...), state, nil
+}
 
-	user, err := s.user.GetUserByEmail(email)
+// HandleOAuthCallback consumes the state once, exchanges the code with its PKCE verifier and resolves the
+// external identity: a linked identity signs in, an unknown verified email creates an account, and an email
+// that already belongs to an account only produces a link token the owner has to confirm after signing in
+func (s *authService) HandleOAuthCallback(providerName, code, state string, client utils.ClientInfo) (*dto.OAuthCallbackResult, error) {
+	var data utils.OAuthState
+	if err := utils.GetKey(oauthStateKey(state), &data); err != nil {
+		return nil, response.NewUnauthorized("OAuth state is invalid or has expired, please try again")
+	}
+	utils.DeleteKeys(oauthStateKey(state))
+
+	provider, ok := config.OAuthProviders[providerName]
+	if !ok || data.Provider != provider.Name {
+		return nil, response.NewUnauthorized("OAuth state does not belong to this provider")
+	}
+
+	ctx := context.Background()
+	token, err := provider.Config.Exchange(ctx, code, oauth2.VerifierOption(data.CodeVerifier))
 	if err != nil {
-		return nil, err
+		return nil, response.NewUnauthorized("Failed to exchange OAuth code")
 	}
 
-	// Jika user belum ada, buat user baru
-	if user == nil {
+	identity, err := utils.FetchExternalIdentity(ctx, provider, token, data.Nonce)
+	if err != nil {
+		return nil, response.NewUnauthorized("Failed to verify the " + provider.Name + " account")
+	}
+
+	result := &dto.OAuthCallbackResult{Provider: provider.Name, ReturnURL: data.ReturnURL}
+
+	// linking from the profile, the identity goes to the signed-in user whatever its email is
+	if data.LinkUserID != "" {
+		if err := s.linkIdentity(data.LinkUserID, identity); err != nil {
+			return nil, err
+		}
+		result.Linked = true
+		return result, nil
+	}
+
+	linked, err := s.user.GetLinkedIdentity(identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to find linked identity", err)
+	}
+
+	if linked != nil {
+		user, err := s.user.GetUserByID(linked.UserID.String())
+		if err != nil || user == nil {
+			return nil, response.NewNotFound("User not found")
+		}
+		s.user.TouchLinkedIdentity(linked.ID.String())
+
+		result.Auth, err = s.oauthSignIn(user, client)
+		return result, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, response.NewForbidden("Your " + provider.Name + " account has no verified email address")
+	}
+
+	user, err := s.user.GetUserByEmail(identity.Email)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to check user existence", err)
+	}
+
+	switch {
+	case user == nil:
 		user = &models.User{
-			Email:    email,
-			Avatar:   utils.RandomUserAvatar(name),
-			Fullname: name,
+			Email:    identity.Email,
+			Avatar:   identity.Avatar,
+			Fullname: identity.Name,
 			Role:     "user",
-			Password: "-", // placeholder karena pakai OAuth
+		}
+		if user.Fullname == "" {
+			user.Fullname = strings.Split(identity.Email, "@")[0]
+		}
+		if user.Avatar == "" {
+			user.Avatar = utils.RandomUserAvatar(user.Fullname)
 		}
 
 		if err := s.user.CreateUser(user); err != nil {
+			return nil, response.NewConflict("Email is already registered")
+		}
+
+	case user.Password == legacyOAuthPassword && provider.Kind == "google":
+		// accounts created by the old google login carry the "-" placeholder instead of a password,
+		// they were only ever reachable through google so the identity is adopted and the placeholder dropped
+		user.Password = ""
+		if err := s.user.UpdateUser(user); err != nil {
+			return nil, response.NewInternalServerError("Failed to update user", err)
+		}
+
+	default:
+		// never sign into an existing account just because the provider reports the same email
+		linkToken, err := s.createIdentityLinkToken(user.ID.String(), identity)
+		if err != nil {
 			return nil, err
 		}
+		result.LinkToken = linkToken
+		return result, nil
+	}
+
+	if err := s.user.CreateLinkedIdentity(newLinkedIdentity(user.ID, identity)); err != nil {
+		return nil, response.NewConflict("This account is already linked to another user")
+	}
+
+	result.Auth, err = s.oauthSignIn(user, client)
+	return result, err
+}
+
+// GetLinkedIdentities lists the external accounts linked to the user
+func (s *authService) GetLinkedIdentities(userID string) ([]dto.LinkedIdentityResponse, error) {
+	identities, err := s.user.GetLinkedIdentitiesByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to get linked identities", err)
+	}
+
+	results := make([]dto.LinkedIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		results = append(results, dto.LinkedIdentityResponse{
+			ID:          identity.ID.String(),
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			LinkedAt:    identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+	return results, nil
+}
+
+// ConfirmIdentityLink finishes the email-conflict flow, only the owner of the account the token
+// was issued for can attach the identity
+func (s *authService) ConfirmIdentityLink(userID string, linkToken string) error {
+	var pending pendingIdentityLink
+	if err := utils.GetKey(identityLinkKey(linkToken), &pending); err != nil {
+		return response.NewBadRequest("Link token is invalid or has expired")
+	}
+
+	if pending.UserID != userID {
+		return response.NewForbidden("Link token was issued for another account")
 	}
+	utils.DeleteKeys(identityLinkKey(linkToken))
 
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Role)
+	return s.linkIdentity(userID, &pending.Identity)
+}
+
+// UnlinkIdentity removes a provider from the account, the last way to sign in cannot be removed
+func (s *authService) UnlinkIdentity(userID string, providerName string) error {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	identities, err := s.user.GetLinkedIdentitiesByUserID(userID)
 	if err != nil {
+		return response.NewInternalServerError("Failed to get linked identities", err)
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == providerName {
+			found = true
+		}
+	}
+	if !found {
+		return response.NewNotFound("Provider is not linked to this account")
+	}
+
+	hasPassword := user.Password != "" && user.Password != legacyOAuthPassword
+	if !hasPassword && len(identities) == 1 {
+		return response.NewBadRequest("Set a password before unlinking your only sign-in method")
+	}
+
+	if err := s.user.DeleteLinkedIdentity(userID, providerName); err != nil {
+		return response.NewInternalServerError("Failed to unlink identity", err)
+	}
+	return nil
+}
+
+func (s *authService) linkIdentity(userID string, identity *utils.ExternalIdentity) error {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	existing, err := s.user.GetLinkedIdentity(identity.Provider, identity.Subject)
+	if err != nil {
+		return response.NewInternalServerError("Failed to find linked identity", err)
+	}
+	if existing != nil {
+		if existing.UserID != user.ID {
+			return response.NewConflict("This " + identity.Provider + " account is already linked to another user")
+		}
+		return nil
+	}
+
+	identities, err := s.user.GetLinkedIdentitiesByUserID(userID)
+	if err != nil {
+		return response.NewInternalServerError("Failed to get linked identities", err)
+	}
+	for _, linked := range identities {
+		if linked.Provider == identity.Provider {
+			return response.NewConflict("Another " + identity.Provider + " account is already linked, unlink it first")
+		}
+	}
+
+	if err := s.user.CreateLinkedIdentity(newLinkedIdentity(user.ID, identity)); err != nil {
+		return response.NewConflict("This " + identity.Provider + " account is already linked to another user")
+	}
+	return nil
+}
+
+func (s *authService) oauthSignIn(user *models.User, client utils.ClientInfo) (*dto.AuthResponse, error) {
+	if user.TwoFactorEnabled {
+		return s.createTwoFactorChallenge(user)
+	}
+
+	accessToken, refreshToken, err := s.startSession(user, client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// pendingIdentityLink is kept in redis until the account owner confirms the link
+type pendingIdentityLink struct {
+	UserID   string                 `json:"userId"`
+	Identity utils.ExternalIdentity `json:"identity"`
+}
+
+func (s *authService) createIdentityLinkToken(userID string, identity *utils.ExternalIdentity) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", response.NewInternalServerError("Failed to generate link token", err)
+	}
+
+	pending := pendingIdentityLink{UserID: userID, Identity: *identity}
+	if err := utils.AddKeys(identityLinkKey(token), pending, identityLinkTTL); err != nil {
+		return "", response.NewInternalServerError("Failed to store link token", err)
+	}
+	return token, nil
+}
+
+func newLinkedIdentity(userID uuid.UUID, identity *utils.ExternalIdentity) *models.LinkedIdentity {
+	now := time.Now()
+	return &models.LinkedIdentity{
+		UserID:      userID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	}
+}
+
+// legacyOAuthPassword is the placeholder the first google login stored instead of a password hash
+const legacyOAuthPassword = "-"
+
+const (
+	oauthStateTTL   = 10 * time.Minute
+	identityLinkTTL = 15 * time.Minute
+)
+
+func oauthStateKey(state string) string {
+	return "ticket:oauth_state:" + state
+}
+
+func identityLinkKey(token string) string {
+	return "ticket:identity_link:" + token
+}
+
+const (
+	twoFactorChallengeTTL  = 5 * time.Minute
+	twoFactorMaxAttempts   = 5
+	twoFactorRecoveryCount = 10
+)
+
+// SetupTwoFactor starts enrolment, the secret stays in redis until the first code confirms it
+func (s *authService) SetupTwoFactor(userID string) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, response.NewConflict("Two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate 2FA secret", err)
+	}
+
+	if err := utils.AddKeys("ticket:2fa_setup:"+userID, secret, 10*time.Minute); err != nil {
+		return nil, response.NewInternalServerError("Failed to store 2FA setup", err)
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(config.AppConfig.AppName, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrolment with a code from the app and returns the recovery codes, they are shown only once.
+// The code also counts as the second factor of the current session, its next tokens carry it
+func (s *authService) EnableTwoFactor(userID string, sessionID string, code string) (*dto.RecoveryCodesResponse, error) {
+	var secret string
+	if err := utils.GetKey("ticket:2fa_setup:"+userID, &secret); err != nil {
+		return nil, response.NewBadRequest("Two-factor setup has expired, please start again")
+	}
+
+	if _, ok := utils.ValidateTOTP(secret, code, time.Now()); !ok {
+		return nil, response.NewBadRequest("Invalid authentication code")
+	}
+
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to secure 2FA secret", err)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate recovery codes", err)
+	}
+
+	if err := s.user.EnableTwoFactor(userID, encrypted, hashes); err != nil {
+		return nil, response.NewInternalServerError("Failed to enable two-factor authentication", err)
+	}
+
+	utils.DeleteKeys("ticket:2fa_setup:" + userID)
+
+	if sessionID != "" {
+		if err := s.session.MarkTwoFactorVerified(sessionID); err != nil {
+			log.Printf("Failed to mark session %s as two-factor verified: %v", sessionID, err)
+		}
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *authService) DisableTwoFactor(userID string, code string) error {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	if utils.RoleRequiresTwoFactor(user.Role) {
+		return response.NewForbidden("Two-factor authentication is mandatory for this role")
+	}
+
+	if !user.TwoFactorEnabled {
+		return response.NewBadRequest("Two-factor authentication is not enabled")
+	}
+
+	if err := s.checkTOTP(user, code); err != nil {
+		return err
+	}
+
+	if err := s.user.DisableTwoFactor(userID); err != nil {
+		return response.NewInternalServerError("Failed to disable two-factor authentication", err)
+	}
+
+	return nil
+}
+
+func (s *authService) RegenerateRecoveryCodes(userID string, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, response.NewBadRequest("Two-factor authentication is not enabled")
+	}
+
+	if err := s.checkTOTP(user, code); err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID.String())
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate recovery codes", err)
+	}
+
+	if err := s.user.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, response.NewInternalServerError("Failed to save recovery codes", err)
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyTwoFactor completes a login that was paused by createTwoFactorChallenge
+func (s *authService) VerifyTwoFactor(req *dto.VerifyTwoFactorRequest, client utils.ClientInfo) (*dto.AuthResponse, error) {
+	challengeKey := "ticket:2fa_challenge:" + req.ChallengeToken
+	attemptsKey := "ticket:2fa_challenge_attempts:" + req.ChallengeToken
+
+	var userID string
+	if err := utils.GetKey(challengeKey, &userID); err != nil {
+		return nil, response.NewUnauthorized("Two-factor challenge has expired, please login again")
+	}
+
+	if err := utils.CheckAttempts(attemptsKey, twoFactorMaxAttempts); err != nil {
+		utils.DeleteKeys(challengeKey, attemptsKey)
+		return nil, response.NewTooManyRequests("Too many invalid codes, please login again")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if req.RecoveryCode != "" {
+		err = s.useRecoveryCode(user, req.RecoveryCode)
+	} else {
+		err = s.checkTOTP(user, req.Code)
+	}
+	if err != nil {
+		utils.IncrementAttempts(attemptsKey)
+		return nil, err
+	}
+
+	utils.DeleteKeys(challengeKey, attemptsKey)
+
+	accessToken, refreshToken, err := s.startSession(user, client, true)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.AuthResponse{
+		User: dto.ProfileResponse{
+			ID:               user.ID.String(),
+			Email:            user.Email,
+			Fullname:         user.Fullname,
+			Balance:          user.Balance,
+			Avatar:           user.Avatar,
+			Role:             user.Role,
+			JoinedAt:         user.CreatedAt,
+			TwoFactorEnabled: user.TwoFactorEnabled,
+		},
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *authService) GetGoogleOAuthURL() string {
-	return config.GoogleOAuthConfig.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+// ResetTwoFactor is the admin recovery path for a lost authenticator, it also signs the user out everywhere
+func (s *authService) ResetTwoFactor(adminID string, userID string) error {
+	if adminID == userID {
+		return response.NewForbidden("Two-factor authentication must be reset by another admin")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return response.NewBadRequest("Two-factor authentication is not enabled for this user")
+	}
+
+	if err := s.user.DisableTwoFactor(userID); err != nil {
+		return response.NewInternalServerError("Failed to reset two-factor authentication", err)
+	}
+
+	return s.LogoutAll(userID)
+}
+
+func (s *authService) GetUserAccount(userID string) (*dto.AdminUserResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// UpdateUserStatus suspends, bans or reactivates an account, blocking it also signs the user out everywhere
+// and rejects the access tokens that are still valid
+func (s *authService) UpdateUserStatus(adminID string, userID string, req *dto.UpdateUserStatusRequest) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, response.NewForbidden("You cannot change the status of your own account")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if user.Status == req.Status {
+		return nil, response.NewBadRequest(fmt.Sprintf("User is already %s", req.Status))
+	}
+	if req.Status != models.UserStatusActive && strings.TrimSpace(req.Reason) == "" {
+		return nil, response.NewBadRequest("A reason is required to suspend or ban a user")
+	}
+
+	now := time.Now()
+	user.Status = req.Status
+	user.StatusReason = strings.TrimSpace(req.Reason)
+	user.StatusChangedAt = &now
+	if err := s.user.UpdateUser(user); err != nil {
+		return nil, response.NewInternalServerError("Failed to update user status", err)
+	}
+
+	heading, message := "Account Reactivated", "Your account has been reactivated, you can sign in again."
+	if req.Status == models.UserStatusActive {
+		utils.ClearUserBlocked(userID)
+	} else {
+		utils.MarkUserBlocked(userID)
+		if err := s.LogoutAll(userID); err != nil {
+			return nil, err
+		}
+
+		heading = map[string]string{models.UserStatusSuspended: "Account Suspended", models.UserStatusBanned: "Account Banned"}[req.Status]
+		message = fmt.Sprintf("Your account has been %s. Reason: %s", req.Status, user.StatusReason)
+	}
+
+	go utils.SendAccountNoticeEmail(user.Email, user.Fullname, heading, message, "", 0)
+
+	return toAdminUserResponse(user), nil
+}
+
+// UnlockUser clears the failed login counter so a locked out user can try again right away
+func (s *authService) UnlockUser(userID string) (*dto.AdminUserResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if err := utils.DeleteKeys(loginAttemptsKey(user.Email)); err != nil {
+		return nil, response.NewInternalServerError("Failed to clear login lockout", err)
+	}
+
+	go utils.SendAccountNoticeEmail(user.Email, user.Fullname, "Account Unlocked",
+		"An administrator cleared the failed sign-in attempts on your account, you can sign in again.", "", 0)
+
+	return toAdminUserResponse(user), nil
 }
 
-func (s *authService) HandleGoogleOAuthCallback(code string) (*dto.AuthResponse, error) {
-	token, err := config.GoogleOAuthConfig.Exchange(context.Background(), code)
+// ForcePasswordReset signs the user out everywhere and blocks password sign-in until the password is
+// changed through the emailed reset link
+func (s *authService) ForcePasswordReset(adminID string, userID string) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, response.NewForbidden("Use change password for your own account")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	resetLink, tokenKeys, err := createPasswordResetLink(user)
 	if err != nil {
-		return nil, response.NewUnauthorized("Failed to exchange Google OAuth code")
+		return nil, err
+	}
+
+	if err := utils.SendAccountNoticeEmail(user.Email, user.Fullname, "Password Reset Required",
+		"An administrator requires you to choose a new password before signing in again.", resetLink, 1*time.Hour); err != nil {
+		utils.DeleteKeys(tokenKeys...)
+		return nil, response.NewInternalServerError("Failed to send reset password email", err)
 	}
 
-	rawIDToken, ok := token.Extra("id_token").(string)
+	user.PasswordResetRequired = true
+	if err := s.user.UpdateUser(user); err != nil {
+		return nil, response.NewInternalServerError("Failed to update user", err)
+	}
+
+	if err := s.LogoutAll(userID); err != nil {
+		return nil, err
+	}
+
+	return toAdminUserResponse(user), nil
+}
+
+// StartImpersonation issues an access token for the user that also names the admin, it has no refresh token
+// and lives at most as long as a regular access token so StopImpersonation can always revoke it
+func (s *authService) StartImpersonation(adminID string, userID string) (*dto.ImpersonationResponse, error) {
+	if adminID == userID {
+		return nil, response.NewBadRequest("You cannot impersonate yourself")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	// staff accounts would hand out their permissions
+	if utils.IsStaffRole(user.Role) {
+		return nil, response.NewForbidden("Only customer accounts can be impersonated")
+	}
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	// the session revocation list only lasts an access token lifetime, see IMPERSONATION_TTL
+	ttl := min(config.AppConfig.ImpersonationTTL, utils.AccessTokenTTL)
+	accessToken, err := utils.GenerateImpersonationToken(user.ID.String(), user.Role, adminID, uuid.NewString(), ttl)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to generate impersonation token", err)
+	}
+
+	return &dto.ImpersonationResponse{
+		TokenType:   "Bearer",
+		AccessToken: accessToken,
+		ExpiresIn:   int(ttl.Seconds()),
+		ExpiresAt:   time.Now().Add(ttl),
+		User: dto.ProfileResponse{
+			ID:       user.ID.String(),
+			Email:    user.Email,
+			Fullname: user.Fullname,
+			Avatar:   user.Avatar,
+			Role:     user.Role,
+			Balance:  user.Balance,
+			JoinedAt: user.CreatedAt,
+		},
+	}, nil
+}
+
+func (s *authService) StopImpersonation(sessionID string) {
+	utils.MarkSessionRevoked(sessionID)
+}
+
+// checkAccountStatus keeps suspended and banned accounts from getting new tokens
+func checkAccountStatus(user *models.User) error {
+	switch user.Status {
+	case models.UserStatusSuspended:
+		return response.NewForbidden("Your account has been suspended")
+	case models.UserStatusBanned:
+		return response.NewForbidden("Your account has been banned")
+	}
+	return nil
+}
+
+func toAdminUserResponse(user *models.User) *dto.AdminUserResponse {
+	attempts, _ := config.RedisClient.Get(config.Ctx, loginAttemptsKey(user.Email)).Int()
+
+	return &dto.AdminUserResponse{
+		ID:                    user.ID.String(),
+		Email:                 user.Email,
+		Fullname:              user.Fullname,
+		Role:                  user.Role,
+		Status:                user.Status,
+		StatusReason:          user.StatusReason,
+		StatusChangedAt:       user.StatusChangedAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		TwoFactorEnabled:      user.TwoFactorEnabled,
+		FailedLoginAttempts:   attempts,
+		LoginLocked:           attempts >= maxLoginAttempts,
+		JoinedAt:              user.CreatedAt,
+	}
+}
+
+func (s *authService) createTwoFactorChallenge(user *models.User) (*dto.AuthResponse, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to create two-factor challenge", err)
+	}
+
+	if err := utils.AddKeys("ticket:2fa_challenge:"+token, user.ID.String(), twoFactorChallengeTTL); err != nil {
+		return nil, response.NewInternalServerError("Failed to store two-factor challenge", err)
+	}
+
+	return &dto.AuthResponse{
+		TwoFactor: &dto.TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    token,
+			ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+		},
+	}, nil
+}
+
+// checkTOTP validates a code for an enrolled user, a code is accepted only once within its time window
+func (s *authService) checkTOTP(user *models.User, code string) error {
+	secret, err := utils.DecryptSecret(user.TwoFactorSecret)
+	if err != nil {
+		return response.NewInternalServerError("Failed to read 2FA secret", err)
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
 	if !ok {
-		return nil, response.NewUnauthorized("ID token not found in Google OAuth response")
+		return response.NewUnauthorized("Invalid authentication code")
+	}
+
+	// the first request to claim the step wins, a concurrent replay of the same code gets false
+	usedKey := fmt.Sprintf("ticket:2fa_used:%s:%d", user.ID.String(), step)
+	claimed, err := utils.ClaimKey(usedKey, 2*time.Minute)
+	if err != nil {
+		return response.NewInternalServerError("Failed to check authentication code", err)
+	}
+	if !claimed {
+		return response.NewUnauthorized("Authentication code has already been used")
+	}
+
+	return nil
+}
+
+func (s *authService) useRecoveryCode(user *models.User, code string) error {
+	codes, err := s.user.GetUnusedRecoveryCodes(user.ID.String())
+	if err != nil {
+		return response.NewInternalServerError("Failed to check recovery code", err)
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, rc := range codes {
+		if !utils.CheckPasswordHash(code, rc.CodeHash) {
+			continue
+		}
+
+		used, err := s.user.UseRecoveryCode(rc.ID.String())
+		if err != nil {
+			return response.NewInternalServerError("Failed to use recovery code", err)
+		}
+		if !used {
+			break
+		}
+		return nil
+	}
+
+	return response.NewUnauthorized("Invalid recovery code")
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(twoFactorRecoveryCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := utils.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, hash)
 	}
 
-	return s.GoogleSignIn(rawIDToken)
+	return codes, hashes, nil
 }
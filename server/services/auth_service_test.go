@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/google/uuid"
+)
+
+func newTestAuthService(users ...*models.User) *authService {
+	repo := &fakeUserRepository{users: map[string]*models.User{}}
+	for _, user := range users {
+		repo.users[user.ID.String()] = user
+	}
+	return &authService{user: repo}
+}
+
+func TestRequestMagicLinkDoesNotTouchLoginAttempts(t *testing.T) {
+	redis := setupRedis(t)
+	setupConfig(t)
+	user := &models.User{ID: uuid.New(), Email: "victim@example.com", Fullname: "Victim"}
+	s := newTestAuthService(user)
+
+	for i := range maxMagicLinkSends {
+		if err := s.RequestMagicLink(&dto.MagicLinkRequest{Email: user.Email}); err != nil {
+			t.Fatalf("send %d: unexpected error %v", i+1, err)
+		}
+	}
+	if redis.Exists(loginAttemptsKey(user.Email)) {
+		t.Fatal("magic link sends must not count towards the password login lockout")
+	}
+
+	err := s.RequestMagicLink(&dto.MagicLinkRequest{Email: user.Email})
+	if appErr, ok := err.(*response.AppError); !ok || appErr.HTTPStatus != 429 {
+		t.Fatalf("expected 429 past the send limit, got %v", err)
+	}
+}
+
+func TestRequestMagicLinkHidesWhetherAccountExists(t *testing.T) {
+	setupRedis(t)
+	setupConfig(t)
+	user := &models.User{ID: uuid.New(), Email: "known@example.com", Fullname: "Known"}
+	s := newTestAuthService(user)
+
+	// the mail server is unreachable, a known email must still answer like an unknown one
+	if err := s.RequestMagicLink(&dto.MagicLinkRequest{Email: user.Email}); err != nil {
+		t.Fatalf("known email: expected no error when the email fails to send, got %v", err)
+	}
+	if err := s.RequestMagicLink(&dto.MagicLinkRequest{Email: "unknown@example.com"}); err != nil {
+		t.Fatalf("unknown email: expected no error, got %v", err)
+	}
+}
+
+func TestVerifyMagicLinkCountsFailedCodes(t *testing.T) {
+	redis := setupRedis(t)
+	setupConfig(t)
+	user := &models.User{ID: uuid.New(), Email: "user@example.com", Fullname: "User"}
+	s := newTestAuthService(user)
+
+	tokenKey := "token-key"
+	data := magicLinkData{UserID: user.ID.String(), Email: user.Email, Code: "123456"}
+	if err := utils.AddKeys(magicLinkKey(tokenKey), data, magicLinkTTL); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.AddKeys("ticket:magic_link_email:"+user.Email, tokenKey, magicLinkTTL); err != nil {
+		t.Fatal(err)
+	}
+
+	for range maxLoginAttempts {
+		if _, err := s.VerifyMagicLink(&dto.VerifyMagicLinkRequest{Email: user.Email, Code: "000000"}, utils.ClientInfo{}); err == nil {
+			t.Fatal("expected a wrong code to be rejected")
+		}
+	}
+	if got, _ := redis.Get(loginAttemptsKey(user.Email)); got != "5" {
+		t.Fatalf("expected 5 failed attempts, got %q", got)
+	}
+
+	// the right code is refused once the lockout is reached
+	_, err := s.VerifyMagicLink(&dto.VerifyMagicLinkRequest{Email: user.Email, Code: "123456"}, utils.ClientInfo{})
+	if appErr, ok := err.(*response.AppError); !ok || appErr.HTTPStatus != 429 {
+		t.Fatalf("expected 429 after too many failed codes, got %v", err)
+	}
+}
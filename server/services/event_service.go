@@ -3,8 +3,10 @@ package services
 import (
 	"errors"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
@@ -29,10 +31,11 @@ type EventService interface {
 type eventService struct {
 	repo   repositories.EventRepository
 	ticket repositories.TicketRepository
+	user   repositories.UserRepository
 }
 
-func NewEventService(repo repositories.EventRepository, ticket repositories.TicketRepository) EventService {
-	return &eventService{repo, ticket}
+func NewEventService(repo repositories.EventRepository, ticket repositories.TicketRepository, user repositories.UserRepository) EventService {
+	return &eventService{repo, ticket, user}
 }
 
 func (s *eventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventResponse, error) {
@@ -72,15 +75,35 @@ func (s *eventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventRespo
 		return nil, response.NewConflict("Event title already exists")
 	}
 
+	// platform events keep the whole sale, organizer events pay the platform fee on every order
+	var organizerID *uuid.UUID
+	feePercent := 0.0
+	if req.OrganizerID != "" {
+		organizer, err := s.user.GetUserByID(req.OrganizerID)
+		if err != nil || organizer == nil || organizer.Role != utils.RoleOrganizer {
+			return nil, response.NewBadRequest("Organizer not found")
+		}
+		organizerID = &organizer.ID
+
+		feePercent = config.AppConfig.PlatformFeePercent
+		if req.PlatformFeePercent != nil {
+			feePercent = *req.PlatformFeePercent
+		}
+	}
+
 	newEvent := &models.Event{
 		ID:          uuid.New(),
 		Image:       req.ImageURL,
 		Title:       req.Title,
 		Description: req.Description,
 		Location:    req.Location,
+		Category:    strings.ToLower(strings.TrimSpace(req.Category)),
 		Date:        parsedDate,
 		StartTime:   req.StartTime,
 		EndTime:     req.EndTime,
+
+		OrganizerID:        organizerID,
+		PlatformFeePercent: feePercent,
 	}
 
 	err = s.repo.CreateEvent(newEvent)
@@ -94,10 +117,12 @@ func (s *eventService) CreateEvent(req *dto.CreateEventRequest) (*dto.EventRespo
 		Image:       newEvent.Image,
 		Description: newEvent.Description,
 		Location:    newEvent.Location,
+		Category:    newEvent.Category,
 		Date:        newEvent.Date,
 		StartTime:   newEvent.StartTime,
 		EndTime:     newEvent.EndTime,
 		Status:      newEvent.Status,
+		OrganizerID: organizerIDString(newEvent.OrganizerID),
 		CreatedAt:   newEvent.CreatedAt,
 	}
 
@@ -176,6 +201,9 @@ func (s *eventService) UpdateEvent(eventID string, req *dto.UpdateEventRequest)
 	event.Title = req.Title
 	event.Description = req.Description
 	event.Location = req.Location
+	if req.Category != "" {
+		event.Category = strings.ToLower(strings.TrimSpace(req.Category))
+	}
 	event.Date = parsedDate
 	event.StartTime = req.StartTime
 	event.EndTime = req.EndTime
@@ -199,10 +227,12 @@ func (s *eventService) UpdateEvent(eventID string, req *dto.UpdateEventRequest)
 		Image:       event.Image,
 		Description: event.Description,
 		Location:    event.Location,
+		Category:    event.Category,
 		Date:        event.Date,
 		StartTime:   event.StartTime,
 		EndTime:     event.EndTime,
 		Status:      event.Status,
+		OrganizerID: organizerIDString(event.OrganizerID),
 		CreatedAt:   event.CreatedAt,
 	}
 
@@ -236,12 +266,14 @@ func (s *eventService) GetAllEvents(params dto.EventQueryParams) ([]dto.EventRes
 			Title:       item.Title,
 			Description: item.Description,
 			Location:    item.Location,
+			Category:    item.Category,
 			StartPrice:  startPrice,
 			IsAvailable: isAvailable,
 			StartTime:   item.StartTime,
 			EndTime:     item.EndTime,
 			Status:      item.Status,
 			Date:        item.Date,
+			OrganizerID: organizerIDString(item.OrganizerID),
 			CreatedAt:   item.CreatedAt,
 		})
 	}
@@ -249,6 +281,13 @@ func (s *eventService) GetAllEvents(params dto.EventQueryParams) ([]dto.EventRes
 	return result, int(total), nil
 }
 
+func organizerIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
 func (s *eventService) GetEventByID(id string) (*dto.EventDetailResponse, error) {
 	event, err := s.repo.GetEventByID(id)
 	if event == nil || err != nil {
@@ -275,6 +314,7 @@ func (s *eventService) GetEventByID(id string) (*dto.EventDetailResponse, error)
 		Image:       event.Image,
 		Description: event.Description,
 		Location:    event.Location,
+		Category:    event.Category,
 		Date:        event.Date,
 		StartTime:   event.StartTime,
 		EndTime:     event.EndTime,
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+const (
+	// exportProgressEvery is how many rows are written between two progress updates of a job
+	exportProgressEvery = 1000
+	// exportMaxAttempts is how often a job is claimed before a lost job is failed instead of requeued
+	exportMaxAttempts = 3
+)
+
+type ExportService interface {
+	CreateExportJob(userID, role string, req *dto.CreateExportRequest) (*dto.ExportJobResponse, error)
+	GetExportJob(userID, id string) (*dto.ExportJobResponse, error)
+	OpenExportFile(userID, id string) (*dto.ExportJobResponse, io.ReadCloser, error)
+	ProcessNextJob() (bool, error)
+	RequeueStaleJobs() error
+	PurgeExpiredExports() error
+}
+
+type exportService struct {
+	repo    repositories.ExportJobRepository
+	storage utils.FileStorage
+	reports map[string]exportReport
+}
+
+func NewExportService(repo repositories.ExportJobRepository, storage utils.FileStorage, admin AdminService, audit AuditService) ExportService {
+	return &exportService{repo: repo, storage: storage, reports: exportReports(admin, audit)}
+}
+
+// exportReport is a report that can be exported by a job, permission is what the requester needs on top
+// of reports:view
+type exportReport struct {
+	permission string
+	validate   func(filters map[string]string) error
+	run        func(filters map[string]string, format string, w io.Writer, progress func(processed, total int)) error
+}
+
+// eventAttendeeExportParams adds the event to the attendee filters, the endpoint takes it from the path
+type eventAttendeeExportParams struct {
+	EventID string `form:"eventId" binding:"required,uuid"`
+	dto.EventAttendeeQueryParams
+}
+
+func exportReports(admin AdminService, audit AuditService) map[string]exportReport {
+	return map[string]exportReport{
+		"orders": newExportReport(utils.PermReportsView,
+			func(p dto.OrderReportQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetOrderReports(p)
+				return total, err
+			}, admin.StreamOrderReports),
+		"ticket-sales": newExportReport(utils.PermReportsView,
+			func(p dto.TicketReportQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetTicketSalesReports(p)
+				return total, err
+			}, admin.StreamTicketSalesReports),
+		"payments": newExportReport(utils.PermReportsView,
+			func(p dto.PaymentReportQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetPaymentReports(p)
+				return total, err
+			}, admin.StreamPaymentReports),
+		"refunds": newExportReport(utils.PermReportsView,
+			func(p dto.RefundReportQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetRefundReports(p)
+				return total, err
+			}, admin.StreamRefundReports),
+		"withdrawals": newExportReport(utils.PermReportsView,
+			func(p dto.WithdrawalReportQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetWithdrawalReports(p)
+				return total, err
+			}, admin.StreamWithdrawalReports),
+		"withdrawal-violations": newExportReport(utils.PermReportsView,
+			func(p dto.WithdrawalViolationQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetWithdrawalViolationReports(p)
+				return total, err
+			}, admin.StreamWithdrawalViolationReports),
+		"organizer-earnings": newExportReport(utils.PermReportsView,
+			func(p dto.OrganizerEarningQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetOrganizerEarnings(p)
+				return total, err
+			}, admin.StreamOrganizerEarnings),
+		"audit-logs": newExportReport(utils.PermAuditView,
+			func(p dto.AuditLogQueryParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := audit.GetAuditLogs(p)
+				return total, err
+			}, audit.StreamAuditLogs),
+		"event-attendees": newExportReport(utils.PermReportsView,
+			func(p eventAttendeeExportParams) (int, error) {
+				p.Page, p.Limit = 1, 1
+				_, total, err := admin.GetEventAttendees(p.EventID, p.EventAttendeeQueryParams)
+				return total, err
+			},
+			func(p eventAttendeeExportParams, fn func(dto.AttendeeResponse) error) error {
+				return admin.StreamEventAttendees(p.EventID, p.EventAttendeeQueryParams, fn)
+			}),
+	}
+}
+
+// newExportReport binds the filters into the report parameters, counts the rows for the progress and
+// streams them into the export file
+func newExportReport[P any, T any](permission string, count func(P) (int, error), stream func(P, func(T) error) error) exportReport {
+	return exportReport{
+		permission: permission,
+		validate: func(filters map[string]string) error {
+			_, err := bindExportFilters[P](filters)
+			return err
+		},
+		run: func(filters map[string]string, format string, w io.Writer, progress func(processed, total int)) error {
+			params, err := bindExportFilters[P](filters)
+			if err != nil {
+				return err
+			}
+
+			total, err := count(params)
+			if err != nil {
+				return err
+			}
+			if err := utils.CheckExportRows(format, total); err != nil {
+				return err
+			}
+			progress(0, total)
+
+			processed := 0
+			err = utils.WriteExport(w, format, func(write func(T) error) error {
+				return stream(params, func(row T) error {
+					if err := write(row); err != nil {
+						return err
+					}
+					processed++
+					if processed%exportProgressEvery == 0 {
+						progress(processed, max(total, processed))
+					}
+					return nil
+				})
+			})
+			progress(processed, max(total, processed))
+			return err
+		},
+	}
+}
+
+// bindExportFilters fills the report parameters like the query string of the report endpoint would
+func bindExportFilters[P any](filters map[string]string) (P, error) {
+	var params P
+
+	form := make(map[string][]string, len(filters))
+	for key, value := range filters {
+		form[key] = []string{value}
+	}
+	if err := binding.MapFormWithTag(&params, form, "form"); err != nil {
+		return params, response.NewBadRequest("Invalid export filters: " + err.Error())
+	}
+	if err := binding.Validator.ValidateStruct(&params); err != nil {
+		return params, response.NewBadRequest("Invalid export filters: " + err.Error())
+	}
+	return params, nil
+}
+
+func (s *exportService) CreateExportJob(userID, role string, req *dto.CreateExportRequest) (*dto.ExportJobResponse, error) {
+	report, ok := s.reports[req.Report]
+	if !ok {
+		return nil, response.NewBadRequest("Unknown report")
+	}
+	if !utils.HasPermission(role, report.permission) {
+		return nil, response.NewForbidden("You are not allowed to export this report")
+	}
+
+	// bad filters are rejected now instead of failing the job later
+	if err := report.validate(req.Filters); err != nil {
+		return nil, err
+	}
+
+	filters, err := json.Marshal(req.Filters)
+	if err != nil {
+		return nil, response.NewBadRequest("Invalid export filters")
+	}
+
+	job := &models.ExportJob{
+		UserID:  uuid.MustParse(userID),
+		Report:  req.Report,
+		Format:  req.Format,
+		Filters: string(filters),
+		Status:  models.ExportJobPending,
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, response.NewInternalServerError("failed to create export job", err)
+	}
+
+	return toExportJobResponse(job), nil
+}
+
+func (s *exportService) GetExportJob(userID, id string) (*dto.ExportJobResponse, error) {
+	job, err := s.ownJob(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toExportJobResponse(job), nil
+}
+
+// OpenExportFile opens the file of a finished job, the caller closes it
+func (s *exportService) OpenExportFile(userID, id string) (*dto.ExportJobResponse, io.ReadCloser, error) {
+	job, err := s.ownJob(userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch job.Status {
+	case models.ExportJobCompleted:
+	case models.ExportJobExpired:
+		return nil, nil, response.NewNotFound("Export file has expired, request a new export")
+	case models.ExportJobFailed:
+		return nil, nil, response.NewConflict("Export failed, request a new export")
+	default:
+		return nil, nil, response.NewConflict("Export is not ready yet")
+	}
+
+	file, err := s.storage.Open(job.FileKey)
+	if errors.Is(err, utils.ErrFileExpired) || errors.Is(err, utils.ErrFileNotFound) {
+		return nil, nil, response.NewNotFound("Export file has expired, request a new export")
+	}
+	if err != nil {
+		return nil, nil, response.NewInternalServerError("failed to open export file", err)
+	}
+
+	return toExportJobResponse(job), file, nil
+}
+
+// ownJob loads a job of the user, jobs of other admins are reported as not found since their files
+// may hold personal data
+func (s *exportService) ownJob(userID, id string) (*models.ExportJob, error) {
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to retrieve export job", err)
+	}
+	if job == nil || job.UserID.String() != userID {
+		return nil, response.NewNotFound("Export job not found")
+	}
+	return job, nil
+}
+
+// ProcessNextJob runs the oldest pending job, it reports false when the queue is empty
+func (s *exportService) ProcessNextJob() (bool, error) {
+	job, err := s.repo.ClaimNextPending()
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	err = s.produceExport(job)
+	now := time.Now()
+	job.CompletedAt = &now
+	if err != nil {
+		log.Printf("Export job %s failed: %v", job.ID, err)
+		job.Status = models.ExportJobFailed
+		job.Error = exportErrorMessage(err)
+		return true, s.repo.Update(job)
+	}
+
+	job.Status = models.ExportJobCompleted
+	if err := s.repo.Update(job); err != nil {
+		return true, err
+	}
+
+	if job.User.Email != "" {
+		message := fmt.Sprintf("Your %s export with %d rows is ready to download.", strings.ReplaceAll(job.Report, "-", " "), job.ProcessedRows)
+		link := config.AppConfig.FrontendURL + "/admin/exports/" + job.ID.String()
+		if err := utils.SendExportReadyEmail(job.User.Email, job.User.Fullname, message, link, config.AppConfig.ExportFileTTL); err != nil {
+			log.Printf("Failed to send export ready email for job %s: %v", job.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// produceExport writes the report straight into the storage through a pipe, the file is never held in
+// memory as a whole. The report runs on its own goroutine where the worker can't recover it, so a panic
+// there is turned into the error of the job
+func (s *exportService) produceExport(job *models.ExportJob) error {
+	report, ok := s.reports[job.Report]
+	if !ok {
+		return fmt.Errorf("unknown report %q", job.Report)
+	}
+
+	var filters map[string]string
+	if job.Filters != "" {
+		if err := json.Unmarshal([]byte(job.Filters), &filters); err != nil {
+			return err
+		}
+	}
+
+	job.FileName = fmt.Sprintf("%s_%s.%s", strings.ReplaceAll(job.Report, "-", "_"), job.CreatedAt.UTC().Format("20060102_150405"), job.Format)
+	job.FileKey = "exports/" + job.ID.String() + "/" + job.FileName
+	expiresAt := time.Now().Add(config.AppConfig.ExportFileTTL)
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Export job %s panicked: %v\n%s", job.ID, r, debug.Stack())
+				writer.CloseWithError(fmt.Errorf("export panicked: %v", r))
+			}
+		}()
+		writer.CloseWithError(report.run(filters, job.Format, writer, func(processed, total int) {
+			job.ProcessedRows, job.TotalRows = processed, total
+			if err := s.repo.UpdateProgress(job.ID.String(), processed, total); err != nil {
+				log.Printf("Failed to update progress of export job %s: %v", job.ID, err)
+			}
+		}))
+	}()
+
+	size, err := s.storage.Save(job.FileKey, reader, expiresAt)
+	// unblocks the report when the storage gave up early
+	reader.CloseWithError(err)
+	<-done
+	if err != nil {
+		return err
+	}
+
+	job.FileSize = size
+	job.ExpiresAt = &expiresAt
+	return nil
+}
+
+// RequeueStaleJobs puts jobs back in the queue whose worker went away while processing them, a job is
+// considered lost when its progress has not moved for the export job timeout
+func (s *exportService) RequeueStaleJobs() error {
+	requeued, failed, err := s.repo.RequeueStale(time.Now().Add(-config.AppConfig.ExportJobTimeout), exportMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if requeued > 0 || failed > 0 {
+		log.Printf("Requeued %d stale export jobs, failed %d that ran out of attempts", requeued, failed)
+	}
+	return nil
+}
+
+// PurgeExpiredExports removes the files of expired jobs along with any file the storage kept past its expiry
+func (s *exportService) PurgeExpiredExports() error {
+	now := time.Now()
+	jobs, err := s.repo.GetExpired(now)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := s.storage.Delete(job.FileKey); err != nil {
+			log.Printf("Failed to delete export file of job %s: %v", job.ID, err)
+			continue
+		}
+		job.Status = models.ExportJobExpired
+		if err := s.repo.Update(&job); err != nil {
+			return err
+		}
+	}
+
+	removed, err := s.storage.PurgeExpired(now)
+	if err != nil {
+		return err
+	}
+	log.Printf("Expired %d export jobs, removed %d files", len(jobs), removed)
+	return nil
+}
+
+// exportErrorMessage keeps internal details out of the job, only client errors are shown as they are
+func exportErrorMessage(err error) string {
+	if appErr, ok := response.IsAppError(err); ok && !response.IsServerError(err) {
+		return appErr.Message
+	}
+	return "The export could not be generated, please try again"
+}
+
+func toExportJobResponse(job *models.ExportJob) *dto.ExportJobResponse {
+	var filters map[string]string
+	if job.Filters != "" {
+		json.Unmarshal([]byte(job.Filters), &filters)
+	}
+
+	progress := 0
+	switch {
+	case job.Status == models.ExportJobCompleted || job.Status == models.ExportJobExpired:
+		progress = 100
+	case job.TotalRows > 0:
+		progress = min(job.ProcessedRows*100/job.TotalRows, 99)
+	}
+
+	resp := &dto.ExportJobResponse{
+		ID:            job.ID.String(),
+		Report:        job.Report,
+		Format:        job.Format,
+		Filters:       filters,
+		Status:        job.Status,
+		Progress:      progress,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		CompletedAt:   job.CompletedAt,
+		ExpiresAt:     job.ExpiresAt,
+	}
+	if job.Status == models.ExportJobCompleted {
+		resp.FileName = job.FileName
+		resp.FileSize = job.FileSize
+		resp.DownloadURL = "/api/v1/admin/exports/" + job.ID.String() + "/download"
+	}
+	return resp
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/google/uuid"
+)
+
+// fakeExportJobRepository hands out a single job and keeps what the service saves
+type fakeExportJobRepository struct {
+	repositories.ExportJobRepository
+	pending *models.ExportJob
+	saved   *models.ExportJob
+}
+
+func (r *fakeExportJobRepository) ClaimNextPending() (*models.ExportJob, error) {
+	job := r.pending
+	r.pending = nil
+	if job != nil {
+		job.Status = models.ExportJobProcessing
+		job.Attempts++
+	}
+	return job, nil
+}
+
+func (r *fakeExportJobRepository) UpdateProgress(id string, processed, total int) error {
+	return nil
+}
+
+func (r *fakeExportJobRepository) Update(job *models.ExportJob) error {
+	saved := *job
+	r.saved = &saved
+	return nil
+}
+
+func TestProcessNextJobFailsPanickingReport(t *testing.T) {
+	setupConfig(t)
+	config.AppConfig.ExportFileTTL = time.Hour
+
+	repo := &fakeExportJobRepository{pending: &models.ExportJob{ID: uuid.New(), Report: "broken", Format: "csv", CreatedAt: time.Now()}}
+	service := &exportService{
+		repo:    repo,
+		storage: utils.NewLocalFileStorage(t.TempDir()),
+		reports: map[string]exportReport{
+			"broken": {run: func(filters map[string]string, format string, w io.Writer, progress func(processed, total int)) error {
+				progress(0, 10)
+				w.Write([]byte("id\n"))
+				panic("nil row")
+			}},
+		},
+	}
+
+	processed, err := service.ProcessNextJob()
+	if !processed || err != nil {
+		t.Fatalf("expected the job to be processed, got %v %v", processed, err)
+	}
+	if repo.saved == nil || repo.saved.Status != models.ExportJobFailed {
+		t.Fatalf("expected the job to be marked failed, got %+v", repo.saved)
+	}
+	if repo.saved.CompletedAt == nil || repo.saved.Error == "" {
+		t.Fatalf("expected a failed job with its completion time and error, got %+v", repo.saved)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"net"
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gopkg.in/gomail.v2"
+)
+
+// setupRedis points the shared redis client at an in-memory server for the duration of the test
+func setupRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	server := miniredis.RunT(t)
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { config.RedisClient.Close() })
+	return server
+}
+
+// setupConfig loads a minimal config, mail goes to a closed port so every send fails fast
+func setupConfig(t *testing.T) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	config.AppConfig = &config.Config{FrontendURL: "http://localhost:5173"}
+	config.MailDialer = gomail.NewDialer("127.0.0.1", port, "", "")
+}
+
+// fakeUserRepository keeps users in memory, methods a test doesn't need panic through the nil interface
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[string]*models.User
+}
+
+func (r *fakeUserRepository) GetUserByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepository) GetUserByID(id string) (*models.User, error) {
+	return r.users[id], nil
+}
+
+func (r *fakeUserRepository) UpdateUser(user *models.User) error {
+	r.users[user.ID.String()] = user
+	return nil
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 )
 
 type Services struct {
@@ -14,18 +16,36 @@ type Services struct {
 	UserTicketService UserTicketService
 	WithdrawalService WithdrawalService
 	AdminService      AdminService
+	SessionService    SessionService
+	AccountService    AccountService
+	APIClientService  APIClientService
+	RoleService       RoleService
+	OrganizerService  OrganizerService
+	AuditService      AuditService
+	ExportService     ExportService
 }
 
 func InitServices(r *repositories.Repositories) *Services {
+	paymentService := NewPaymentService(r.PaymentRepository)
+	adminService := NewAdminService(r.AdminRepository)
+	auditService := NewAuditService(r.AuditRepository)
+
 	return &Services{
-		UserService:       NewUserService(r.UserRepository),
-		AuthService:       NewAuthService(r.AuthRepository),
-		EventService:      NewEventService(r.EventRepository, r.TicketRepository),
+		UserService:       NewUserService(r.UserRepository, r.SessionRepository),
+		AuthService:       NewAuthService(r.AuthRepository, r.SessionRepository),
+		EventService:      NewEventService(r.EventRepository, r.TicketRepository, r.UserRepository),
 		TicketService:     NewTicketService(r.TicketRepository, r.EventRepository),
 		OrderService:      NewOrderService(r.OrderRepository, r.UserRepository, r.TicketRepository, r.EventRepository, r.UserTicketRepository),
-		PaymentService:    NewPaymentService(r.PaymentRepository, r.OrderRepository, r.TicketRepository, r.UserTicketRepository),
+		PaymentService:    paymentService,
 		UserTicketService: NewUserTicketService(r.UserTicketRepository),
 		WithdrawalService: NewWithdrawalService(r.WithdrawalRepository),
-		AdminService:      NewAdminService(r.AdminRepository),
+		AdminService:      adminService,
+		SessionService:    NewSessionService(r.SessionRepository, r.UserRepository),
+		AccountService:    NewAccountService(r.AccountRepository, r.SessionRepository),
+		APIClientService:  NewAPIClientService(r.APIClientRepository),
+		RoleService:       NewRoleService(r.RoleRepository, r.UserRepository, r.EventRepository, r.SessionRepository),
+		OrganizerService:  NewOrganizerService(r.OrganizerRepository),
+		AuditService:      auditService,
+		ExportService:     NewExportService(r.ExportJobRepository, utils.NewLocalFileStorage(config.AppConfig.ExportStorageDir), adminService, auditService),
 	}
 }
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+	"math"
 	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
@@ -107,17 +109,86 @@ func (s *orderService) CreateNewOrder(req dto.CreateOrderRequest, userID string)
 					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
 						Name: stripe.String(ticket.Name),
 					},
-					UnitAmount: stripe.Int64(int64(ticket.Price * 100)),
+					UnitAmount: stripe.Int64(toMinorUnits(ticket.Price)),
 				},
 				Quantity: stripe.Int64(int64(item.Quantity)),
 			})
 		}
 
+		if req.WalletAmount > totalPrice {
+			return "", response.NewBadRequest("wallet amount exceeds order total")
+		}
+		if req.WalletAmount > user.Balance {
+			return "", response.NewBadRequest("insufficient balance")
+		}
+
+		amountDue := math.Round((totalPrice-req.WalletAmount)*100) / 100
+
 		order.TotalPrice = totalPrice
+		order.WalletAmount = req.WalletAmount
 		if err := tx.Create(order).Error; err != nil {
 			return "", response.NewInternalServerError("failed to create order", err)
 		}
 
+		// hold the wallet part until the order is paid, it is returned if the order fails or expires
+		if req.WalletAmount > 0 {
+			hold := &models.BalanceHold{
+				UserID:      user.ID,
+				Source:      "order",
+				ReferenceID: order.ID,
+				Amount:      req.WalletAmount,
+			}
+			if err := s.repo.HoldUserBalance(tx, hold); err != nil {
+				if errors.Is(err, repositories.ErrInsufficientBalance) {
+					return "", response.NewBadRequest("insufficient balance")
+				}
+				return "", response.NewInternalServerError("failed to hold wallet balance", err)
+			}
+
+			walletPayment := &models.Payment{
+				ID:       uuid.New(),
+				UserID:   user.ID,
+				OrderID:  order.ID,
+				Fullname: req.Fullname,
+				Email:    req.Email,
+				Method:   "wallet",
+				Status:   "pending",
+				Amount:   req.WalletAmount,
+			}
+			if err := tx.Create(walletPayment).Error; err != nil {
+				return "", response.NewInternalServerError("failed to create wallet payment", err)
+			}
+
+			// fully covered by the wallet, no card payment needed. The order is settled in the same
+			// transaction so a failure leaves neither a pending order nor a hold behind
+			if amountDue <= 0 {
+				if err := s.repo.FulfillOrder(tx, order.ID.String()); err != nil {
+					return "", response.NewInternalServerError("failed to complete wallet payment", err)
+				}
+				result = &dto.CheckoutSessionResponse{
+					OrderID:      order.ID.String(),
+					PaymentID:    walletPayment.ID.String(),
+					Status:       "paid",
+					WalletAmount: req.WalletAmount,
+				}
+				return order.ID.String(), nil
+			}
+
+			// stripe line items must add up to the remaining amount
+			stripeItems = []*stripe.CheckoutSessionLineItemParams{
+				{
+					PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+						Currency: stripe.String("idr"),
+						ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+							Name: stripe.String(event.Title + " (after wallet balance)"),
+						},
+						UnitAmount: stripe.Int64(toMinorUnits(amountDue)),
+					},
+					Quantity: stripe.Int64(1),
+				},
+			}
+		}
+
 		paymentID := uuid.New()
 		payment := &models.Payment{
 			ID:       paymentID,
@@ -127,7 +198,7 @@ func (s *orderService) CreateNewOrder(req dto.CreateOrderRequest, userID string)
 			Email:    req.Email,
 			Method:   "stripe",
 			Status:   "pending",
-			Amount:   totalPrice,
+			Amount:   amountDue,
 		}
 		if err := tx.Create(payment).Error; err != nil {
 			return "", response.NewInternalServerError("failed to create payment", err)
@@ -140,6 +211,7 @@ func (s *orderService) CreateNewOrder(req dto.CreateOrderRequest, userID string)
 			SuccessURL:         stripe.String(config.AppConfig.StripeSuccessUrlDev),
 			CancelURL:          stripe.String(config.AppConfig.StripeCancelUrlDev),
 			ClientReferenceID:  stripe.String(order.ID.String()),
+			ExpiresAt:          stripe.Int64(time.Now().Add(repositories.CheckoutTTL).Unix()),
 			Metadata: map[string]string{
 				"user_id":    user.ID.String(),
 				"order_id":   order.ID.String(),
@@ -156,9 +228,13 @@ func (s *orderService) CreateNewOrder(req dto.CreateOrderRequest, userID string)
 		}
 
 		result = &dto.CheckoutSessionResponse{
-			PaymentID: paymentID.String(),
-			SessionID: sess.ID,
-			URL:       sess.URL,
+			OrderID:      order.ID.String(),
+			PaymentID:    paymentID.String(),
+			SessionID:    sess.ID,
+			URL:          sess.URL,
+			Status:       "pending",
+			WalletAmount: req.WalletAmount,
+			AmountDue:    amountDue,
 		}
 		return order.ID.String(), nil
 	})
@@ -170,6 +246,11 @@ func (s *orderService) CreateNewOrder(req dto.CreateOrderRequest, userID string)
 	return result, nil
 }
 
+// toMinorUnits converts an amount to the cents stripe expects, rounded since the float may sit just below them
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
 func (s *orderService) GetMyOrders(userID string, params dto.OrderQueryParams) ([]dto.OrderResponse, int, error) {
 	orders, total, err := s.repo.GetMyOrders(userID, params)
 	if err != nil {
@@ -179,17 +260,18 @@ func (s *orderService) GetMyOrders(userID string, params dto.OrderQueryParams) (
 	var results []dto.OrderResponse
 	for _, o := range orders {
 		results = append(results, dto.OrderResponse{
-			ID:         o.ID.String(),
-			EventName:  o.Event.Title,
-			EventImage: o.Event.Image,
-			EventID:    o.Event.ID.String(),
-			Fullname:   o.Fullname,
-			Email:      o.Email,
-			Phone:      o.Phone,
-			TotalPrice: o.TotalPrice,
-			PaymentURL: o.PaymentURL,
-			Status:     o.Status,
-			CreatedAt:  o.CreatedAt,
+			ID:           o.ID.String(),
+			EventName:    o.Event.Title,
+			EventImage:   o.Event.Image,
+			EventID:      o.Event.ID.String(),
+			Fullname:     o.Fullname,
+			Email:        o.Email,
+			Phone:        o.Phone,
+			TotalPrice:   o.TotalPrice,
+			WalletAmount: o.WalletAmount,
+			PaymentURL:   o.PaymentURL,
+			Status:       o.Status,
+			CreatedAt:    o.CreatedAt,
 		})
 	}
 
@@ -292,20 +374,15 @@ func (s *orderService) RefundOrder(orderID string, userID string, reason string)
 		return nil, response.NewBadRequest("you have already used one of the tickets")
 	}
 
-	// mark order
+	// the order, the buyer's balance and the organizer's share change together
 	now := time.Now()
-	order.Status = "refunded"
-	order.IsRefunded = true
-	order.RefundedAt = &now
-	order.RefundReason = reason
-	order.RefundAmount = totalRefund
-	if err := s.repo.UpdateOrder(order); err != nil {
-		return nil, err
+	if err := s.repo.RefundOrder(orderID, totalRefund, reason, now); err != nil {
+		if errors.Is(err, repositories.ErrOrderNotRefundable) {
+			return nil, response.NewBadRequest("order not refundable")
+		}
+		return nil, response.NewInternalServerError("failed to refund order", err)
 	}
 
-	s.repo.UpdatePaymentStatus(order.ID.String(), "refunded")
-	s.repo.IncreaseUserBalance(userID, totalRefund)
-
 	user, _ := s.user.GetUserByID(userID)
 	return &dto.RefundOrderResponse{
 		OrderID:      order.ID.String(),
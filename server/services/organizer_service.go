@@ -0,0 +1,46 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+)
+
+type OrganizerService interface {
+	SettleDueEarnings() error
+}
+
+type organizerService struct {
+	repo repositories.OrganizerRepository
+}
+
+func NewOrganizerService(repo repositories.OrganizerRepository) OrganizerService {
+	return &organizerService{repo}
+}
+
+// ** khusus cron job, credits organizer earnings once the event is over and the settlement delay has passed,
+// organizers then withdraw the balance through the normal withdrawal flow
+func (s *organizerService) SettleDueEarnings() error {
+	earnings, err := s.repo.GetDueEarnings(time.Now().Add(-config.AppConfig.OrganizerSettlementDelay))
+	if err != nil {
+		return fmt.Errorf("failed to get due organizer earnings: %w", err)
+	}
+
+	settled := 0
+	for _, earning := range earnings {
+		ok, err := s.repo.SettleEarning(earning.ID.String())
+		if err != nil {
+			log.Printf("failed to settle organizer earning %s: %v", earning.ID, err)
+			continue
+		}
+		if ok {
+			settled++
+		}
+	}
+
+	log.Printf("%d organizer earnings settled\n", settled)
+	return nil
+}
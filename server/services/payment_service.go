@@ -2,14 +2,14 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
 
-	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v75"
+	"github.com/stripe/stripe-go/v75/refund"
 )
 
 type PaymentService interface {
@@ -17,14 +17,20 @@ type PaymentService interface {
 	StripeWebhookNotification(event stripe.Event) error
 }
 type paymentService struct {
-	repo       repositories.PaymentRepository
-	order      repositories.OrderRepository
-	ticket     repositories.TicketRepository
-	userTicket repositories.UserTicketRepository
+	repo repositories.PaymentRepository
+	// refundCard refunds a card payment in full, idempotencyKey keeps webhook retries from refunding twice
+	refundCard func(paymentIntentID string, idempotencyKey string) error
 }
 
-func NewPaymentService(repo repositories.PaymentRepository, order repositories.OrderRepository, ticket repositories.TicketRepository, userTicket repositories.UserTicketRepository) PaymentService {
-	return &paymentService{repo, order, ticket, userTicket}
+func NewPaymentService(repo repositories.PaymentRepository) PaymentService {
+	return &paymentService{repo: repo, refundCard: refundPaymentIntent}
+}
+
+func refundPaymentIntent(paymentIntentID string, idempotencyKey string) error {
+	params := &stripe.RefundParams{PaymentIntent: stripe.String(paymentIntentID)}
+	params.SetIdempotencyKey(idempotencyKey)
+	_, err := refund.New(params)
+	return err
 }
 
 // ** khusus cron job update status to failed
@@ -45,7 +51,7 @@ func (s *paymentService) ExpireOldPendingPayments() error {
 }
 
 func (s *paymentService) StripeWebhookNotification(event stripe.Event) error {
-	if event.Type != "checkout.session.completed" {
+	if event.Type != "checkout.session.completed" && event.Type != "checkout.session.expired" {
 		return fmt.Errorf("%s is not a valid event", event.Type)
 	}
 
@@ -66,73 +72,53 @@ func (s *paymentService) StripeWebhookNotification(event stripe.Event) error {
 		return fmt.Errorf("payment not found")
 	}
 
-	if payment.Status == "paid" {
+	// settled by an earlier delivery, a late payment may also have been refunded already
+	if payment.Status == "paid" || payment.Status == "refunded" {
 		return nil
 	}
 
-	payment.Method = "card"
-	payment.Status = "paid"
-	now := time.Now().UTC()
-	err = s.repo.UpdatePayment(&models.Payment{
-		ID:     payment.ID,
-		Method: "card",
-		Status: "paid",
-		PaidAt: &now,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to update payment: %w", err)
-	}
-	order, err := s.order.GetOrderByID(payment.OrderID.String())
-	if err != nil || order == nil {
-		return fmt.Errorf("order not found: %w", err)
+	// abandoned checkout, release the wallet amount held for this order
+	if event.Type == "checkout.session.expired" {
+		if err := s.repo.FailOrderPayments(payment.OrderID.String()); err != nil {
+			return fmt.Errorf("failed to mark order payments as failed: %w", err)
+		}
+		return nil
 	}
 
-	if err := s.order.UpdateOrderStatus(order.ID.String(), "paid"); err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+	orderID := payment.OrderID.String()
+	err = s.repo.FulfillOrder(orderID, payment.ID.String())
+	if errors.Is(err, repositories.ErrOrderNotPending) {
+		err = s.fulfillLatePayment(&session, orderID, payment.ID.String())
 	}
-
-	orderDetails, err := s.order.GetOrderDetails(order.ID.String())
-	if err != nil || len(orderDetails) == 0 {
-		return fmt.Errorf("failed to fetch order details: %w", err)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill order %s: %w", orderID, err)
 	}
+	return nil
+}
 
-	for _, detail := range orderDetails {
-		ticket, err := s.ticket.GetTicketByID(detail.TicketID.String())
-		if err != nil || ticket == nil {
-			return fmt.Errorf("failed to fetch ticket: %w", err)
-		}
-
-		ticket.Sold += detail.Quantity
-
-		if err := s.ticket.UpdateTicket(ticket); err != nil {
-			return fmt.Errorf("failed to update ticket sold count: %w", err)
-		}
+// fulfillLatePayment handles a card payment completed after the order expired. The order is fulfilled
+// after all when its wallet amount can still be held, otherwise the card is refunded so the customer is
+// not charged for an order without tickets
+func (s *paymentService) fulfillLatePayment(session *stripe.CheckoutSession, orderID string, paymentID string) error {
+	err := s.repo.FulfillExpiredOrder(orderID, paymentID)
+	if !errors.Is(err, repositories.ErrInsufficientBalance) {
+		return err
 	}
 
-	for _, detail := range orderDetails {
-		existingCount, err := s.userTicket.CountUserTicketsByTicketID(detail.TicketID)
-		if err != nil {
-			return fmt.Errorf("failed to count existing user tickets: %w", err)
-		}
-
-		for i := range detail.Quantity {
-			qrCode := fmt.Sprintf("TICKET-%s-%d", detail.TicketID.String(), existingCount+int64(i)+1)
-
-			userTicket := &models.UserTicket{
-				ID:       uuid.New(),
-				UserID:   order.UserID,
-				EventID:  order.EventID,
-				TicketID: detail.TicketID,
-				IsUsed:   false,
-				QRCode:   qrCode,
-			}
-
-			if err := s.userTicket.CreateUserTicket(userTicket); err != nil {
-				return fmt.Errorf("failed to create user ticket: %w", err)
-			}
-		}
+	if session.PaymentIntent == nil || session.PaymentIntent.ID == "" {
+		return fmt.Errorf("missing payment intent to refund")
+	}
+	if err := s.refundCard(session.PaymentIntent.ID, "late-payment-"+paymentID); err != nil {
+		return fmt.Errorf("failed to refund late payment: %w", err)
 	}
 
-	return nil
-
+	payment, err := s.repo.GetPaymentByID(paymentID)
+	if err != nil {
+		return err
+	}
+	paidAt := time.Now().UTC()
+	payment.Method = "card"
+	payment.Status = "refunded"
+	payment.PaidAt = &paidAt
+	return s.repo.UpdatePayment(payment)
 }
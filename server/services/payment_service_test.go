@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v75"
+)
+
+// fakePaymentRepository holds one card payment whose order expired and can no longer hold its wallet amount
+type fakePaymentRepository struct {
+	repositories.PaymentRepository
+	payment *models.Payment
+}
+
+func (r *fakePaymentRepository) GetPaymentByID(paymentID string) (*models.Payment, error) {
+	payment := *r.payment
+	return &payment, nil
+}
+
+func (r *fakePaymentRepository) UpdatePayment(payment *models.Payment) error {
+	r.payment = payment
+	return nil
+}
+
+func (r *fakePaymentRepository) FulfillOrder(orderID string, cardPaymentID string) error {
+	return repositories.ErrOrderNotPending
+}
+
+func (r *fakePaymentRepository) FulfillExpiredOrder(orderID string, cardPaymentID string) error {
+	return repositories.ErrInsufficientBalance
+}
+
+func TestLatePaymentIsRefundedWhenTheOrderCannotBeFulfilled(t *testing.T) {
+	payment := &models.Payment{ID: uuid.New(), OrderID: uuid.New(), Method: "stripe", Status: "failed"}
+	repo := &fakePaymentRepository{payment: payment}
+
+	var refunds []string
+	s := &paymentService{repo: repo, refundCard: func(paymentIntentID string, idempotencyKey string) error {
+		refunds = append(refunds, paymentIntentID+" "+idempotencyKey)
+		return nil
+	}}
+
+	raw, _ := json.Marshal(map[string]any{
+		"id":             "cs_1",
+		"payment_intent": "pi_1",
+		"metadata":       map[string]string{"payment_id": payment.ID.String()},
+	})
+	event := stripe.Event{Type: "checkout.session.completed", Data: &stripe.EventData{Raw: raw}}
+
+	// stripe retries the webhook, the card is refunded once
+	for range 2 {
+		if err := s.StripeWebhookNotification(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(refunds) != 1 || refunds[0] != "pi_1 late-payment-"+payment.ID.String() {
+		t.Fatalf("expected one refund of the payment intent, got %v", refunds)
+	}
+	if repo.payment.Status != "refunded" || repo.payment.Method != "card" {
+		t.Fatalf("expected the payment to be recorded as refunded, got %+v", repo.payment)
+	}
+}
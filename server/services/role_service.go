@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	GetRoles() []dto.RoleResponse
+	GetUserRoles(userID string) (*dto.UserRolesResponse, error)
+	UpdateUserRole(adminID, userID string, req *dto.UpdateUserRoleRequest) (*dto.UserRolesResponse, error)
+	AssignEventRole(adminID, userID string, req *dto.CreateRoleAssignmentRequest) (*dto.RoleAssignmentResponse, error)
+	RevokeEventRole(userID, assignmentID string) error
+}
+
+type roleService struct {
+	repo    repositories.RoleRepository
+	user    repositories.UserRepository
+	event   repositories.EventRepository
+	session repositories.SessionRepository
+}
+
+func NewRoleService(repo repositories.RoleRepository, user repositories.UserRepository, event repositories.EventRepository, session repositories.SessionRepository) RoleService {
+	return &roleService{repo, user, event, session}
+}
+
+func (s *roleService) GetRoles() []dto.RoleResponse {
+	roles := make([]dto.RoleResponse, 0, len(utils.Roles))
+	for _, role := range utils.Roles {
+		roles = append(roles, dto.RoleResponse{
+			Name:        role,
+			Permissions: utils.RolePermissions[role],
+			Scopable:    slices.Contains(utils.ScopableRoles, role),
+		})
+	}
+	return roles
+}
+
+func (s *roleService) GetUserRoles(userID string) (*dto.UserRolesResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	assignments, err := s.repo.GetAssignmentsByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to get role assignments", err)
+	}
+
+	result := &dto.UserRolesResponse{
+		UserID:      user.ID.String(),
+		Role:        user.Role,
+		Permissions: utils.RolePermissions[user.Role],
+		Assignments: make([]dto.RoleAssignmentResponse, 0, len(assignments)),
+	}
+	for i := range assignments {
+		result.Assignments = append(result.Assignments, toRoleAssignmentDTO(&assignments[i]))
+	}
+	return result, nil
+}
+
+// UpdateUserRole changes the global role, the role is carried in access tokens so the user's sessions are revoked
+func (s *roleService) UpdateUserRole(adminID, userID string, req *dto.UpdateUserRoleRequest) (*dto.UserRolesResponse, error) {
+	if adminID == userID {
+		return nil, response.NewForbidden("You cannot change your own role")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	if user.Role != req.Role {
+		user.Role = req.Role
+		if err := s.user.UpdateUser(user); err != nil {
+			return nil, response.NewInternalServerError("Failed to update role", err)
+		}
+
+		ids, err := s.session.RevokeUserSessions(userID, "role_changed")
+		if err != nil {
+			return nil, response.NewInternalServerError("Failed to revoke sessions", err)
+		}
+		for _, id := range ids {
+			utils.MarkSessionRevoked(id)
+		}
+
+		go utils.SendAccountNoticeEmail(user.Email, user.Fullname, "Role Changed",
+			fmt.Sprintf("Your role has been changed to %s, please sign in again to continue.", req.Role), "", 0)
+	}
+
+	return s.GetUserRoles(userID)
+}
+
+// AssignEventRole grants a scoped role on one event, it takes effect on the next request
+func (s *roleService) AssignEventRole(adminID, userID string, req *dto.CreateRoleAssignmentRequest) (*dto.RoleAssignmentResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("User not found")
+	}
+
+	event, err := s.event.GetEventByID(req.EventID)
+	if err != nil || event == nil {
+		return nil, response.NewNotFound("Event not found")
+	}
+
+	existing, err := s.repo.GetAssignment(userID, req.Role, req.EventID)
+	if err != nil {
+		return nil, response.NewInternalServerError("Failed to check role assignments", err)
+	}
+	if existing != nil {
+		return nil, response.NewConflict("User already holds this role on the event")
+	}
+
+	assignment := &models.RoleAssignment{
+		UserID:    user.ID,
+		Role:      req.Role,
+		EventID:   uuid.MustParse(req.EventID),
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateAssignment(assignment); err != nil {
+		return nil, response.NewInternalServerError("Failed to assign role", err)
+	}
+	assignment.Event = *event
+
+	res := toRoleAssignmentDTO(assignment)
+	return &res, nil
+}
+
+func (s *roleService) RevokeEventRole(userID, assignmentID string) error {
+	assignment, err := s.repo.GetAssignmentByID(assignmentID)
+	if err != nil || assignment == nil || assignment.UserID.String() != userID {
+		return response.NewNotFound("Role assignment not found")
+	}
+
+	if err := s.repo.DeleteAssignment(assignmentID); err != nil {
+		return response.NewInternalServerError("Failed to revoke role", err)
+	}
+	return nil
+}
+
+func toRoleAssignmentDTO(assignment *models.RoleAssignment) dto.RoleAssignmentResponse {
+	return dto.RoleAssignmentResponse{
+		ID:         assignment.ID.String(),
+		Role:       assignment.Role,
+		EventID:    assignment.EventID.String(),
+		EventTitle: assignment.Event.Title,
+		CreatedBy:  assignment.CreatedBy,
+		CreatedAt:  assignment.CreatedAt,
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+)
+
+type SessionService interface {
+	GetUserSessions(userID, currentSessionID string) ([]dto.SessionResponse, error)
+	RevokeUserSession(userID, sessionID, reason string) error
+	RevokeAllUserSessions(userID, reason string) error
+}
+
+type sessionService struct {
+	repo repositories.SessionRepository
+	user repositories.UserRepository
+}
+
+func NewSessionService(repo repositories.SessionRepository, user repositories.UserRepository) SessionService {
+	return &sessionService{repo, user}
+}
+
+func (s *sessionService) GetUserSessions(userID, currentSessionID string) ([]dto.SessionResponse, error) {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("user not found")
+	}
+
+	sessions, err := s.repo.GetActiveSessionsByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to fetch sessions", err)
+	}
+
+	var result []dto.SessionResponse
+	for _, session := range sessions {
+		result = append(result, toSessionDTO(&session, currentSessionID))
+	}
+
+	return result, nil
+}
+
+// RevokeUserSession revokes one of the user's sessions, sessions of other users are reported as not found
+func (s *sessionService) RevokeUserSession(userID, sessionID, reason string) error {
+	session, err := s.repo.GetSessionByID(sessionID)
+	if err != nil || session == nil || session.UserID.String() != userID {
+		return response.NewNotFound("session not found")
+	}
+
+	if session.RevokedAt != nil {
+		return response.NewBadRequest("session already revoked")
+	}
+
+	if err := s.repo.RevokeSession(sessionID, reason); err != nil {
+		return response.NewInternalServerError("failed to revoke session", err)
+	}
+	utils.MarkSessionRevoked(sessionID)
+
+	return nil
+}
+
+func (s *sessionService) RevokeAllUserSessions(userID, reason string) error {
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("user not found")
+	}
+
+	ids, err := s.repo.RevokeUserSessions(userID, reason)
+	if err != nil {
+		return response.NewInternalServerError("failed to revoke sessions", err)
+	}
+
+	for _, id := range ids {
+		utils.MarkSessionRevoked(id)
+	}
+
+	return nil
+}
+
+func toSessionDTO(session *models.UserSession, currentSessionID string) dto.SessionResponse {
+	return dto.SessionResponse{
+		ID:         session.ID.String(),
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Location:   session.Location,
+		Client:     session.Client,
+		Current:    session.ID.String() == currentSessionID,
+		LastSeenAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
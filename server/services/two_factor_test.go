@@ -0,0 +1,139 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/google/uuid"
+)
+
+// fakeSessionRepository keeps the sessions a test opens
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	sessions map[string]*models.UserSession
+}
+
+func (r *fakeSessionRepository) CreateSession(session *models.UserSession, token *models.RefreshToken) error {
+	r.sessions[session.ID.String()] = session
+	return nil
+}
+
+// newTwoFactorUser returns a user enrolled in two-factor together with the plain TOTP secret
+func newTwoFactorUser(t *testing.T) (*models.User, string) {
+	t.Helper()
+	config.AppConfig.TwoFactorEncryptionKey = "test-key"
+	config.AppConfig.AccessTokenSecret = "access-secret"
+	config.AppConfig.RefreshTokenSecret = "refresh-secret"
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.User{ID: uuid.New(), Email: "staff@example.com", Fullname: "Staff", Role: "finance",
+		TwoFactorEnabled: true, TwoFactorSecret: encrypted}, secret
+}
+
+// currentTOTP is the code an authenticator app would show right now
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestCheckTOTPAcceptsCodeOnce(t *testing.T) {
+	setupRedis(t)
+	setupConfig(t)
+	user, secret := newTwoFactorUser(t)
+	s := newTestAuthService(user)
+	code := currentTOTP(t, secret)
+
+	// concurrent replays of the same code, only one may pass
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.checkTOTP(user, code) == nil {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted.Load() != 1 {
+		t.Fatalf("expected the code to be accepted exactly once, got %d", accepted.Load())
+	}
+}
+
+func TestTwoFactorClaimFollowsTheSession(t *testing.T) {
+	setupRedis(t)
+	setupConfig(t)
+	user, secret := newTwoFactorUser(t)
+	sessions := &fakeSessionRepository{sessions: map[string]*models.UserSession{}}
+	s := newTestAuthService(user)
+	s.session = sessions
+
+	mfaClaim := func(accessToken string) bool {
+		claims, err := utils.DecodeAccessToken(accessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claims.TwoFactor
+	}
+
+	// a login that skipped the second factor, e.g. a session opened before enrolment, is not verified
+	accessToken, _, err := s.startSession(user, utils.ClientInfo{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mfaClaim(accessToken) {
+		t.Fatal("expected no mfa claim for a session without a second factor check")
+	}
+
+	challenge, err := s.createTwoFactorChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := s.VerifyTwoFactor(&dto.VerifyTwoFactorRequest{
+		ChallengeToken: challenge.TwoFactor.ChallengeToken,
+		Code:           currentTOTP(t, secret),
+	}, utils.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mfaClaim(result.AccessToken) {
+		t.Fatal("expected the mfa claim after the second factor check")
+	}
+
+	claims, _ := utils.DecodeAccessToken(result.AccessToken)
+	if session := sessions.sessions[claims.SessionID]; session == nil || !session.TwoFactor {
+		t.Fatalf("expected the session to remember the second factor, got %+v", session)
+	}
+}
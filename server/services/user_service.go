@@ -1,6 +1,11 @@
 package services
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
@@ -13,14 +18,19 @@ type UserService interface {
 	GetUserProfile(userID string) (*dto.ProfileResponse, error)
 	ChangePassword(userID string, req *dto.ChangePasswordRequest) error
 	UpdateUserDetail(userID string, req *dto.UpdateProfileRequest) (*models.User, error)
+
+	// email change, applied only once the new address is confirmed
+	RequestEmailChange(userID, sessionID string, req *dto.ChangeEmailRequest) error
+	ConfirmEmailChange(token string) error
 }
 
 type userService struct {
-	user repositories.UserRepository
+	user    repositories.UserRepository
+	session repositories.SessionRepository
 }
 
-func NewUserService(user repositories.UserRepository) UserService {
-	return &userService{user: user}
+func NewUserService(user repositories.UserRepository, session repositories.SessionRepository) UserService {
+	return &userService{user: user, session: session}
 }
 
 func (s *userService) GetUserProfile(userID string) (*dto.ProfileResponse, error) {
@@ -37,6 +47,9 @@ func (s *userService) GetUserProfile(userID string) (*dto.ProfileResponse, error
 		Role:     user.Role,
 		Balance:  user.Balance,
 		JoinedAt: user.CreatedAt,
+
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		HasPassword:      user.Password != "" && user.Password != "-",
 	}
 	return profile, nil
 }
@@ -90,8 +103,9 @@ func (s *userService) ChangePassword(userID string, req *dto.ChangePasswordReque
 		return response.NewNotFound("User not found")
 	}
 
-	// Verify current password
-	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+	// Verify current password, accounts created through a sign-in provider have none yet
+	hasPassword := user.Password != "" && user.Password != "-"
+	if hasPassword && !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
 		return response.NewBadRequest("Current password is incorrect")
 	}
 
@@ -109,3 +123,136 @@ func (s *userService) ChangePassword(userID string, req *dto.ChangePasswordReque
 
 	return nil
 }
+
+// RequestEmailChange sends a confirmation link to the new address and a notice to the current one,
+// a newer request replaces the pending one
+func (s *userService) RequestEmailChange(userID, sessionID string, req *dto.ChangeEmailRequest) error {
+	attemptsKey := "ticket:email_change_attempts:" + userID
+	if err := utils.CheckAttempts(attemptsKey, 3); err != nil {
+		return response.NewTooManyRequests("Too many email change requests, please try again later")
+	}
+
+	user, err := s.user.GetUserByID(userID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	// accounts with a password have to prove they still know it, every wrong guess counts towards the limit
+	hasPassword := user.Password != "" && user.Password != "-"
+	if hasPassword && !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		utils.IncrementAttempts(attemptsKey)
+		return response.NewBadRequest("Current password is incorrect")
+	}
+
+	if strings.EqualFold(req.NewEmail, user.Email) {
+		return response.NewBadRequest("New email is the same as the current email")
+	}
+
+	existing, err := s.user.GetUserByEmail(req.NewEmail)
+	if err != nil {
+		return response.NewInternalServerError("Failed to check email availability", err)
+	}
+	if existing != nil {
+		return response.NewConflict("Email is already registered")
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return response.NewInternalServerError("Failed to generate confirmation token", err)
+	}
+
+	pendingKey := "ticket:email_change_user:" + userID
+	var previous string
+	if err := utils.GetKey(pendingKey, &previous); err == nil {
+		utils.DeleteKeys(emailChangeKey(previous))
+	}
+
+	data := emailChangeData{
+		UserID:    userID,
+		SessionID: sessionID,
+		OldEmail:  user.Email,
+		NewEmail:  req.NewEmail,
+	}
+	if err := utils.AddKeys(emailChangeKey(token), data, emailChangeTTL); err != nil {
+		return response.NewInternalServerError("Failed to store email change request", err)
+	}
+	if err := utils.AddKeys(pendingKey, token, emailChangeTTL); err != nil {
+		utils.DeleteKeys(emailChangeKey(token))
+		return response.NewInternalServerError("Failed to store email change request", err)
+	}
+
+	confirmLink := fmt.Sprintf("%s/confirm-email?token=%s", config.AppConfig.FrontendURL, token)
+	if err := utils.SendEmailChangeConfirmEmail(req.NewEmail, user.Fullname, confirmLink, emailChangeTTL); err != nil {
+		utils.DeleteKeys(emailChangeKey(token), pendingKey)
+		return response.NewInternalServerError("Failed to send confirmation email", err)
+	}
+
+	utils.IncrementAttempts(attemptsKey)
+	go utils.SendEmailChangeNoticeEmail(user.Email, user.Fullname, req.NewEmail)
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending change, the address is checked again because another account
+// may have registered it in the meantime, then every session except the requesting one is revoked
+func (s *userService) ConfirmEmailChange(token string) error {
+	var data emailChangeData
+	if err := utils.GetKey(emailChangeKey(token), &data); err != nil {
+		return response.NewBadRequest("Confirmation link is invalid or has expired")
+	}
+
+	// deleting the key is the claim, the link works once
+	claimed, err := config.RedisClient.Del(config.Ctx, emailChangeKey(token)).Result()
+	if err != nil || claimed == 0 {
+		return response.NewBadRequest("Confirmation link has already been used")
+	}
+	utils.DeleteKeys("ticket:email_change_user:" + data.UserID)
+
+	user, err := s.user.GetUserByID(data.UserID)
+	if err != nil || user == nil {
+		return response.NewNotFound("User not found")
+	}
+
+	if user.Email != data.OldEmail {
+		return response.NewConflict("The account email has changed since this link was sent")
+	}
+
+	existing, err := s.user.GetUserByEmail(data.NewEmail)
+	if err != nil {
+		return response.NewInternalServerError("Failed to check email availability", err)
+	}
+	if existing != nil {
+		return response.NewConflict("Email is already registered")
+	}
+
+	user.Email = data.NewEmail
+	if err := s.user.UpdateUser(user); err != nil {
+		// unique index on email, lost a race with a registration
+		return response.NewConflict("Email is already registered")
+	}
+
+	ids, err := s.session.RevokeOtherSessions(data.UserID, data.SessionID, "email_changed")
+	if err != nil {
+		return response.NewInternalServerError("Failed to revoke sessions", err)
+	}
+	for _, id := range ids {
+		utils.MarkSessionRevoked(id)
+	}
+
+	utils.DeleteKeys("login:attempt:"+data.OldEmail, "ticket:magic_link_email:"+data.OldEmail)
+
+	return nil
+}
+
+type emailChangeData struct {
+	UserID    string `json:"userId"`
+	SessionID string `json:"sessionId"`
+	OldEmail  string `json:"oldEmail"`
+	NewEmail  string `json:"newEmail"`
+}
+
+const emailChangeTTL = 1 * time.Hour
+
+func emailChangeKey(token string) string {
+	return "ticket:email_change:" + token
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/google/uuid"
+)
+
+func newTestUserService(t *testing.T) (*userService, *models.User) {
+	t.Helper()
+	hash, err := utils.HashPassword("correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Email: "jane@example.com", Fullname: "Jane", Password: hash}
+	return &userService{user: &fakeUserRepository{users: map[string]*models.User{user.ID.String(): user}}}, user
+}
+
+func httpStatus(err error) int {
+	if appErr, ok := err.(*response.AppError); ok {
+		return appErr.HTTPStatus
+	}
+	return 0
+}
+
+func TestRequestEmailChangeLimitsPasswordGuesses(t *testing.T) {
+	setupRedis(t)
+	setupConfig(t)
+	s, user := newTestUserService(t)
+
+	for i := range 3 {
+		err := s.RequestEmailChange(user.ID.String(), "", &dto.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "guess"})
+		if httpStatus(err) != 400 {
+			t.Fatalf("guess %d: expected a wrong password error, got %v", i+1, err)
+		}
+	}
+
+	// the right password no longer helps once the guesses are used up
+	err := s.RequestEmailChange(user.ID.String(), "", &dto.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "correct-horse"})
+	if httpStatus(err) != 429 {
+		t.Fatalf("expected 429 after three wrong passwords, got %v", err)
+	}
+}
+
+func TestRequestEmailChangeComparesEmailsCaseInsensitively(t *testing.T) {
+	setupRedis(t)
+	setupConfig(t)
+	s, user := newTestUserService(t)
+
+	err := s.RequestEmailChange(user.ID.String(), "", &dto.ChangeEmailRequest{NewEmail: "Jane@Example.com", CurrentPassword: "correct-horse"})
+	if httpStatus(err) != 400 {
+		t.Fatalf("expected the same address in another case to be rejected, got %v", err)
+	}
+}
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
 	"time"
 
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/dto"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"github.com/fiqrioemry/event_ticketing_system_app/server/repositories"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/utils"
 
 	"github.com/fiqrioemry/go-api-toolkit/response"
 	"github.com/google/uuid"
@@ -14,7 +21,33 @@ import (
 type WithdrawalService interface {
 	CreateWithdrawal(userID string, req dto.CreateWithdrawalRequest) (*dto.WithdrawalResponse, error)
 	GetAllWithdrawals() ([]dto.WithdrawalResponse, error)
-	ReviewWithdrawal(id, adminID, status string) (*dto.WithdrawalResponse, error)
+	ReviewWithdrawal(id, adminID string, req dto.ReviewWithdrawalRequest) (*dto.WithdrawalResponse, error)
+
+	// user withdrawal history
+	GetMyWithdrawals(userID string, params dto.WithdrawalQueryParams) ([]dto.WithdrawalResponse, int, error)
+	GetMyWithdrawalByID(id, userID string) (*dto.WithdrawalResponse, error)
+	CancelWithdrawal(id, userID string) (*dto.WithdrawalResponse, error)
+
+	// payout accounts
+	CreatePayoutAccount(userID string, req dto.CreatePayoutAccountRequest) (*dto.PayoutAccountResponse, error)
+	GetPayoutAccounts(userID string) ([]dto.PayoutAccountResponse, error)
+	DeletePayoutAccount(id, userID string) error
+	VerifyPayoutAccount(id string, req dto.VerifyPayoutAccountRequest) (*dto.PayoutAccountResponse, error)
+
+	// withdrawal policy & identity verification
+	GetWithdrawalPolicy(userID string) (*dto.WithdrawalPolicyResponse, error)
+	SubmitIdentity(userID string, req dto.SubmitIdentityRequest) (*dto.IdentityResponse, error)
+	GetMyIdentity(userID string) (*dto.IdentityResponse, error)
+	GetIdentities(params dto.IdentityQueryParams) ([]dto.IdentityResponse, error)
+	OpenIdentityDocument(id string) (io.ReadCloser, int64, error)
+	ReviewIdentity(id, adminID string, req dto.ReviewIdentityRequest) (*dto.IdentityResponse, error)
+
+	// payout batches
+	CreatePayoutBatch(adminID string, req dto.CreatePayoutBatchRequest) (*dto.PayoutBatchResponse, error)
+	GetPayoutBatches() ([]dto.PayoutBatchResponse, error)
+	GetPayoutBatchByID(id string) (*dto.PayoutBatchResponse, error)
+	ExportPayoutBatch(id string) (string, []byte, error)
+	ReconcilePayoutBatch(id, adminID string, req dto.ReconcilePayoutBatchRequest) (*dto.PayoutReconcileResponse, error)
 }
 
 type withdrawalService struct {
@@ -35,17 +68,53 @@ func (s *withdrawalService) CreateWithdrawal(userID string, req dto.CreateWithdr
 		return nil, response.NewBadRequest("insufficient balance")
 	}
 
+	fee, err := s.enforcePolicy(user, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetPayoutAccountByID(req.PayoutAccountID)
+	if err != nil || account == nil || account.UserID != user.ID {
+		return nil, response.NewNotFound("payout account not found")
+	}
+
+	if account.Status != "verified" {
+		return nil, response.NewBadRequest("payout account is not verified yet")
+	}
+
 	withdrawal := &models.WithdrawalRequest{
-		ID:        uuid.New(),
-		UserID:    user.ID,
-		Amount:    req.Amount,
-		Status:    "pending",
-		Reason:    req.Reason,
-		CreatedAt: time.Now(),
+		ID:              uuid.New(),
+		UserID:          user.ID,
+		PayoutAccountID: &account.ID,
+		Amount:          req.Amount,
+		Fee:             fee,
+		Status:          "pending",
+		Reason:          req.Reason,
+		CreatedAt:       time.Now(),
 	}
-	if err := s.repo.CreateWithdrawal(withdrawal); err != nil {
-		return nil, err
+	// the caps are checked in the same transaction that creates the request, with the user row locked,
+	// so concurrent requests cannot both slip under them
+	now := time.Now()
+	caps := repositories.WithdrawalCaps{
+		Daily:      config.AppConfig.WithdrawalDailyCap,
+		Monthly:    config.AppConfig.WithdrawalMonthlyCap,
+		DayStart:   startOfDay(now),
+		MonthStart: startOfMonth(now),
 	}
+	if err := s.repo.CreateWithdrawal(withdrawal, caps); err != nil {
+		var capErr *repositories.WithdrawalCapError
+		switch {
+		case errors.Is(err, repositories.ErrInsufficientBalance):
+			return nil, response.NewBadRequest("insufficient balance")
+		case errors.As(err, &capErr):
+			msg := capExceededMessage(capErr)
+			s.recordViolation(user, capErr.Rule, req.Amount, msg)
+			return nil, response.NewBadRequest(msg)
+		}
+		return nil, response.NewInternalServerError("failed to create withdrawal request", err)
+	}
+	withdrawal.PayoutAccount = *account
+
 	return toWithdrawalDTO(withdrawal), nil
 }
 
@@ -62,7 +131,7 @@ func (s *withdrawalService) GetAllWithdrawals() ([]dto.WithdrawalResponse, error
 	return res, nil
 }
 
-func (s *withdrawalService) ReviewWithdrawal(id, adminID, status string) (*dto.WithdrawalResponse, error) {
+func (s *withdrawalService) ReviewWithdrawal(id, adminID string, req dto.ReviewWithdrawalRequest) (*dto.WithdrawalResponse, error) {
 	w, err := s.repo.GetWithdrawalByID(id)
 	if err != nil || w == nil {
 		return nil, response.NewNotFound("withdrawal request not found").WithContext("withdrawalID", id)
@@ -72,31 +141,570 @@ func (s *withdrawalService) ReviewWithdrawal(id, adminID, status string) (*dto.W
 		return nil, response.NewBadRequest("withdrawal already reviewed")
 	}
 
-	w.Status = status
-	w.ApprovedAt = &time.Time{}
-	*w.ApprovedAt = time.Now()
+	reviewed, err := s.repo.ReviewWithdrawal(id, adminID, req.Status, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrWithdrawalNotPending):
+			return nil, response.NewBadRequest("withdrawal already reviewed")
+		case errors.Is(err, repositories.ErrInsufficientBalance):
+			return nil, response.NewBadRequest("insufficient balance to approve withdrawal")
+		}
+		return nil, response.NewInternalServerError("failed to review withdrawal", err)
+	}
+	reviewed.PayoutAccount = w.PayoutAccount
 
-	if err := s.repo.UpdateWithdrawal(w); err != nil {
-		return nil, err
+	return toWithdrawalDTO(reviewed), nil
+}
+
+func (s *withdrawalService) GetMyWithdrawals(userID string, params dto.WithdrawalQueryParams) ([]dto.WithdrawalResponse, int, error) {
+	list, total, err := s.repo.GetUserWithdrawals(userID, params)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError("failed to retrieve withdrawals", err)
+	}
+
+	var res []dto.WithdrawalResponse
+	for _, w := range list {
+		res = append(res, *toWithdrawalDTO(&w))
+	}
+	return res, int(total), nil
+}
+
+func (s *withdrawalService) GetMyWithdrawalByID(id, userID string) (*dto.WithdrawalResponse, error) {
+	w, err := s.repo.GetUserWithdrawalByID(id, userID)
+	if err != nil || w == nil {
+		return nil, response.NewNotFound("withdrawal request not found").WithContext("withdrawalID", id)
+	}
+
+	return toWithdrawalDTO(w), nil
+}
+
+func (s *withdrawalService) CancelWithdrawal(id, userID string) (*dto.WithdrawalResponse, error) {
+	if _, err := s.repo.GetUserWithdrawalByID(id, userID); err != nil {
+		return nil, response.NewNotFound("withdrawal request not found").WithContext("withdrawalID", id)
 	}
 
-	if status == "approved" {
-		_ = s.repo.DecreaseUserBalance(w.UserID.String(), w.Amount)
+	w, err := s.repo.CancelWithdrawal(id, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrWithdrawalNotPending) {
+			return nil, response.NewBadRequest("only pending withdrawals can be cancelled")
+		}
+		return nil, response.NewInternalServerError("failed to cancel withdrawal", err)
 	}
 
 	return toWithdrawalDTO(w), nil
 }
 
+func (s *withdrawalService) CreatePayoutAccount(userID string, req dto.CreatePayoutAccountRequest) (*dto.PayoutAccountResponse, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("user not found")
+	}
+
+	account := &models.PayoutAccount{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		BankCode:      req.BankCode,
+		AccountNumber: req.AccountNumber,
+		HolderName:    req.HolderName,
+		Status:        "pending",
+	}
+	if err := s.repo.CreatePayoutAccount(account); err != nil {
+		return nil, response.NewInternalServerError("failed to save payout account", err)
+	}
+
+	return toPayoutAccountDTO(account), nil
+}
+
+func (s *withdrawalService) GetPayoutAccounts(userID string) ([]dto.PayoutAccountResponse, error) {
+	accounts, err := s.repo.GetPayoutAccountsByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to retrieve payout accounts", err)
+	}
+
+	var res []dto.PayoutAccountResponse
+	for _, a := range accounts {
+		res = append(res, *toPayoutAccountDTO(&a))
+	}
+	return res, nil
+}
+
+func (s *withdrawalService) DeletePayoutAccount(id, userID string) error {
+	account, err := s.repo.GetPayoutAccountByID(id)
+	if err != nil || account == nil || account.UserID.String() != userID {
+		return response.NewNotFound("payout account not found")
+	}
+
+	pending, err := s.repo.HasPendingWithdrawalsForAccount(id)
+	if err != nil {
+		return response.NewInternalServerError("failed to check pending withdrawals", err)
+	}
+	if pending {
+		return response.NewBadRequest("payout account has pending withdrawals")
+	}
+
+	if err := s.repo.DeletePayoutAccount(id); err != nil {
+		return response.NewInternalServerError("failed to delete payout account", err)
+	}
+	return nil
+}
+
+func (s *withdrawalService) VerifyPayoutAccount(id string, req dto.VerifyPayoutAccountRequest) (*dto.PayoutAccountResponse, error) {
+	account, err := s.repo.GetPayoutAccountByID(id)
+	if err != nil || account == nil {
+		return nil, response.NewNotFound("payout account not found")
+	}
+
+	account.Status = req.Status
+	account.VerifiedAt = nil
+	if req.Status == "verified" {
+		now := time.Now()
+		account.VerifiedAt = &now
+	}
+
+	if err := s.repo.UpdatePayoutAccount(account); err != nil {
+		return nil, response.NewInternalServerError("failed to update payout account", err)
+	}
+
+	return toPayoutAccountDTO(account), nil
+}
+
+// enforcePolicy checks the request against the configured withdrawal rules and returns the fee,
+// every violation is stored so it shows up in the admin reports. The daily and monthly caps are
+// checked by CreateWithdrawal
+func (s *withdrawalService) enforcePolicy(user *models.User, amount float64) (float64, error) {
+	cfg := config.AppConfig
+	userID := user.ID.String()
+
+	reject := func(rule, message string, err error) (float64, error) {
+		s.recordViolation(user, rule, amount, message)
+		return 0, err
+	}
+
+	if cfg.WithdrawalMinAmount > 0 && amount < cfg.WithdrawalMinAmount {
+		msg := fmt.Sprintf("minimum withdrawal amount is %.2f", cfg.WithdrawalMinAmount)
+		return reject("min_amount", msg, response.NewBadRequest(msg))
+	}
+
+	if cfg.WithdrawalMaxAmount > 0 && amount > cfg.WithdrawalMaxAmount {
+		msg := fmt.Sprintf("maximum withdrawal amount is %.2f", cfg.WithdrawalMaxAmount)
+		return reject("max_amount", msg, response.NewBadRequest(msg))
+	}
+
+	fee := withdrawalFee(amount)
+	if fee >= amount {
+		msg := fmt.Sprintf("withdrawal amount must be greater than the %.2f fee", fee)
+		return reject("fee", msg, response.NewBadRequest(msg))
+	}
+
+	if cfg.WithdrawalRefundCoolingOff > 0 {
+		lastRefund, err := s.repo.GetLastRefundAt(userID)
+		if err != nil {
+			return 0, response.NewInternalServerError("failed to check refund history", err)
+		}
+		if lastRefund != nil {
+			availableAt := lastRefund.Add(cfg.WithdrawalRefundCoolingOff)
+			if time.Now().Before(availableAt) {
+				msg := "withdrawals are paused after a refund, try again after " + availableAt.Format("2006-01-02 15:04")
+				return reject("cooling_off", msg, response.NewBadRequest(msg))
+			}
+		}
+	}
+
+	if cfg.WithdrawalKYCThreshold > 0 && amount > cfg.WithdrawalKYCThreshold {
+		identity, err := s.repo.GetIdentityByUserID(userID)
+		if err != nil || identity == nil || identity.Status != "verified" {
+			msg := fmt.Sprintf("withdrawals above %.2f require a verified identity", cfg.WithdrawalKYCThreshold)
+			return reject("kyc_required", msg, response.NewForbidden(msg))
+		}
+	}
+
+	return fee, nil
+}
+
+func (s *withdrawalService) recordViolation(user *models.User, rule string, amount float64, message string) {
+	violation := &models.WithdrawalPolicyViolation{
+		UserID:  user.ID,
+		Rule:    rule,
+		Amount:  amount,
+		Message: message,
+	}
+	if err := s.repo.CreatePolicyViolation(violation); err != nil {
+		log.Printf("failed to record withdrawal policy violation: %v", err)
+	}
+}
+
+func capExceededMessage(err *repositories.WithdrawalCapError) string {
+	if err.Rule == "monthly_cap" {
+		return fmt.Sprintf("monthly withdrawal cap of %.2f exceeded, %.2f left this month", err.Cap, max(err.Cap-err.Used, 0))
+	}
+	return fmt.Sprintf("daily withdrawal cap of %.2f exceeded, %.2f left today", err.Cap, max(err.Cap-err.Used, 0))
+}
+
+func (s *withdrawalService) GetWithdrawalPolicy(userID string) (*dto.WithdrawalPolicyResponse, error) {
+	cfg := config.AppConfig
+	now := time.Now()
+
+	today, err := s.repo.SumUserWithdrawalsSince(userID, startOfDay(now))
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to fetch withdrawal usage", err)
+	}
+
+	month, err := s.repo.SumUserWithdrawalsSince(userID, startOfMonth(now))
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to fetch withdrawal usage", err)
+	}
+
+	identityStatus := "none"
+	if identity, err := s.repo.GetIdentityByUserID(userID); err == nil && identity != nil {
+		identityStatus = identity.Status
+	}
+
+	return &dto.WithdrawalPolicyResponse{
+		MinAmount:          cfg.WithdrawalMinAmount,
+		MaxAmount:          cfg.WithdrawalMaxAmount,
+		FeeType:            cfg.WithdrawalFeeType,
+		FeeValue:           cfg.WithdrawalFeeValue,
+		DailyCap:           cfg.WithdrawalDailyCap,
+		MonthlyCap:         cfg.WithdrawalMonthlyCap,
+		CoolingOffHours:    cfg.WithdrawalRefundCoolingOff.Hours(),
+		KYCThreshold:       cfg.WithdrawalKYCThreshold,
+		WithdrawnToday:     today,
+		WithdrawnThisMonth: month,
+		IdentityStatus:     identityStatus,
+	}, nil
+}
+
+// SubmitIdentity creates the user's identity record or resubmits a rejected/pending one for review
+func (s *withdrawalService) SubmitIdentity(userID string, req dto.SubmitIdentityRequest) (*dto.IdentityResponse, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || user == nil {
+		return nil, response.NewNotFound("user not found")
+	}
+
+	identity, err := s.repo.GetIdentityByUserID(userID)
+	if err != nil || identity == nil {
+		identity = &models.IdentityVerification{ID: uuid.New(), UserID: user.ID}
+	} else if identity.Status == "verified" {
+		return nil, response.NewConflict("identity is already verified")
+	}
+
+	identity.FullName = req.FullName
+	identity.DocumentType = req.DocumentType
+	identity.DocumentNumber = req.DocumentNumber
+	previousDocument := identity.DocumentID
+	identity.DocumentID = req.DocumentID
+	identity.Status = "pending"
+	identity.ReviewedBy = ""
+	identity.ReviewedAt = nil
+
+	if err := s.repo.SaveIdentity(identity); err != nil {
+		return nil, response.NewInternalServerError("failed to save identity", err)
+	}
+	utils.DeletePrivateDocument(previousDocument)
+
+	return toIdentityDTO(identity), nil
+}
+
+func (s *withdrawalService) GetMyIdentity(userID string) (*dto.IdentityResponse, error) {
+	identity, err := s.repo.GetIdentityByUserID(userID)
+	if err != nil || identity == nil {
+		return nil, response.NewNotFound("identity not submitted yet")
+	}
+
+	return toIdentityDTO(identity), nil
+}
+
+func (s *withdrawalService) GetIdentities(params dto.IdentityQueryParams) ([]dto.IdentityResponse, error) {
+	list, err := s.repo.GetIdentities(params.Status)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to fetch identities", err)
+	}
+
+	var result []dto.IdentityResponse
+	for _, identity := range list {
+		result = append(result, *toReviewerIdentityDTO(&identity))
+	}
+	return result, nil
+}
+
+func (s *withdrawalService) OpenIdentityDocument(id string) (io.ReadCloser, int64, error) {
+	identity, err := s.repo.GetIdentityByID(id)
+	if err != nil || identity == nil || identity.DocumentID == "" {
+		return nil, 0, response.NewNotFound("identity document not found")
+	}
+
+	file, size, err := utils.OpenPrivateDocument(identity.DocumentID)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError("failed to retrieve identity document", err)
+	}
+	return file, size, nil
+}
+
+func (s *withdrawalService) ReviewIdentity(id, adminID string, req dto.ReviewIdentityRequest) (*dto.IdentityResponse, error) {
+	identity, err := s.repo.GetIdentityByID(id)
+	if err != nil || identity == nil {
+		return nil, response.NewNotFound("identity not found")
+	}
+
+	if identity.Status != "pending" {
+		return nil, response.NewBadRequest("identity already reviewed")
+	}
+
+	now := time.Now()
+	identity.Status = req.Status
+	identity.ReviewedBy = adminID
+	identity.ReviewedAt = &now
+
+	if err := s.repo.SaveIdentity(identity); err != nil {
+		return nil, response.NewInternalServerError("failed to update identity", err)
+	}
+
+	return toReviewerIdentityDTO(identity), nil
+}
+
+func withdrawalFee(amount float64) float64 {
+	cfg := config.AppConfig
+	if cfg.WithdrawalFeeType == "percent" {
+		return math.Round(amount*cfg.WithdrawalFeeValue) / 100
+	}
+	return cfg.WithdrawalFeeValue
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func toIdentityDTO(i *models.IdentityVerification) *dto.IdentityResponse {
+	return &dto.IdentityResponse{
+		ID:             i.ID.String(),
+		UserID:         i.UserID.String(),
+		FullName:       i.FullName,
+		DocumentType:   i.DocumentType,
+		DocumentNumber: i.DocumentNumber,
+		HasDocument:    i.DocumentID != "",
+		Status:         i.Status,
+		ReviewedAt:     i.ReviewedAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+func toReviewerIdentityDTO(i *models.IdentityVerification) *dto.IdentityResponse {
+	res := toIdentityDTO(i)
+	if res.HasDocument {
+		res.DocumentURL = "/api/v1/withdrawals/identities/" + res.ID + "/document"
+	}
+	return res
+}
+
+func (s *withdrawalService) CreatePayoutBatch(adminID string, req dto.CreatePayoutBatchRequest) (*dto.PayoutBatchResponse, error) {
+	batch := &models.PayoutBatch{
+		ID:        uuid.New(),
+		Format:    req.Format,
+		Status:    "exported",
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreatePayoutBatch(batch); err != nil {
+		if errors.Is(err, repositories.ErrNoPayableWithdrawals) {
+			return nil, response.NewBadRequest(err.Error())
+		}
+		return nil, response.NewInternalServerError("failed to create payout batch", err)
+	}
+
+	return s.GetPayoutBatchByID(batch.ID.String())
+}
+
+func (s *withdrawalService) GetPayoutBatches() ([]dto.PayoutBatchResponse, error) {
+	batches, err := s.repo.GetPayoutBatches()
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to fetch payout batches", err)
+	}
+
+	var result []dto.PayoutBatchResponse
+	for _, b := range batches {
+		result = append(result, *toPayoutBatchDTO(&b))
+	}
+	return result, nil
+}
+
+func (s *withdrawalService) GetPayoutBatchByID(id string) (*dto.PayoutBatchResponse, error) {
+	batch, err := s.repo.GetPayoutBatchByID(id)
+	if err != nil || batch == nil {
+		return nil, response.NewNotFound("payout batch not found")
+	}
+
+	return toPayoutBatchDTO(batch), nil
+}
+
+// ExportPayoutBatch builds the bulk-transfer file in the batch's format, the withdrawal ID is the transfer reference
+func (s *withdrawalService) ExportPayoutBatch(id string) (string, []byte, error) {
+	batch, err := s.repo.GetPayoutBatchByID(id)
+	if err != nil || batch == nil {
+		return "", nil, response.NewNotFound("payout batch not found")
+	}
+
+	var lines []utils.PayoutLine
+	var missing []string
+	for _, w := range batch.Withdrawals {
+		if w.PayoutAccountID == nil || w.PayoutAccount.AccountNumber == "" {
+			missing = append(missing, w.ID.String())
+			continue
+		}
+		lines = append(lines, utils.PayoutLine{
+			Reference:     w.ID.String(),
+			BankCode:      w.PayoutAccount.BankCode,
+			AccountNumber: w.PayoutAccount.AccountNumber,
+			HolderName:    w.PayoutAccount.HolderName,
+			Amount:        w.Amount - w.Fee,
+		})
+	}
+	// batches created before legacy withdrawals were left out may hold payouts without bank details
+	if len(missing) > 0 {
+		return "", nil, response.NewConflict("some withdrawals in the batch have no payout account to transfer to").
+			WithContext("withdrawalIds", missing)
+	}
+
+	if batch.Format == "fixed" {
+		return "payout_" + batch.ID.String() + ".txt", utils.BuildPayoutFixedWidth(batch.ID.String(), lines), nil
+	}
+
+	content, err := utils.BuildPayoutCSV(lines)
+	if err != nil {
+		return "", nil, response.NewInternalServerError("failed to build payout file", err)
+	}
+	return "payout_" + batch.ID.String() + ".csv", content, nil
+}
+
+// ReconcilePayoutBatch applies the bank's result file, rows that do not belong to the batch
+// or were settled by an earlier import are reported back as skipped
+func (s *withdrawalService) ReconcilePayoutBatch(id, adminID string, req dto.ReconcilePayoutBatchRequest) (*dto.PayoutReconcileResponse, error) {
+	batch, err := s.repo.GetPayoutBatchByID(id)
+	if err != nil || batch == nil {
+		return nil, response.NewNotFound("payout batch not found")
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return nil, response.NewBadRequest("failed to read result file")
+	}
+	defer file.Close()
+
+	var results []utils.PayoutResult
+	if batch.Format == "fixed" {
+		results, err = utils.ParsePayoutResultFixedWidth(file)
+	} else {
+		results, err = utils.ParsePayoutResultCSV(file)
+	}
+	if err != nil {
+		return nil, response.NewBadRequest("invalid result file: " + err.Error())
+	}
+
+	inBatch := make(map[string]bool)
+	for _, w := range batch.Withdrawals {
+		inBatch[w.ID.String()] = true
+	}
+
+	res := &dto.PayoutReconcileResponse{BatchID: batch.ID.String()}
+	for _, r := range results {
+		status := utils.NormalizePayoutStatus(r.Status)
+		if !inBatch[r.Reference] || status == "" {
+			res.Skipped = append(res.Skipped, r.Reference)
+			continue
+		}
+
+		note := r.Message
+		if note == "" {
+			note = "bank result: " + r.Status
+		}
+
+		if err := s.repo.SettlePayout(batch.ID.String(), r.Reference, status, adminID, note); err != nil {
+			if errors.Is(err, repositories.ErrPayoutAlreadySettled) {
+				res.Skipped = append(res.Skipped, r.Reference)
+				continue
+			}
+			return nil, response.NewInternalServerError("failed to settle payout", err)
+		}
+
+		if status == "paid" {
+			res.Paid++
+		} else {
+			res.Failed++
+		}
+	}
+
+	if err := s.repo.CompletePayoutBatch(batch.ID.String()); err != nil {
+		return nil, response.NewInternalServerError("failed to update payout batch", err)
+	}
+
+	return res, nil
+}
+
+func toPayoutBatchDTO(b *models.PayoutBatch) *dto.PayoutBatchResponse {
+	res := &dto.PayoutBatchResponse{
+		ID:           b.ID.String(),
+		Format:       b.Format,
+		Status:       b.Status,
+		ItemCount:    b.ItemCount,
+		TotalAmount:  b.TotalAmount,
+		CreatedBy:    b.CreatedBy,
+		CreatedAt:    b.CreatedAt,
+		ReconciledAt: b.ReconciledAt,
+	}
+
+	for _, w := range b.Withdrawals {
+		res.Withdrawals = append(res.Withdrawals, *toWithdrawalDTO(&w))
+	}
+
+	return res
+}
+
 func toWithdrawalDTO(w *models.WithdrawalRequest) *dto.WithdrawalResponse {
 	res := &dto.WithdrawalResponse{
 		ID:         w.ID.String(),
 		UserID:     w.UserID.String(),
 		Amount:     w.Amount,
+		Fee:        w.Fee,
+		NetAmount:  w.Amount - w.Fee,
 		Status:     w.Status,
 		Reason:     w.Reason,
 		CreatedAt:  w.CreatedAt,
+		ReviewedBy: w.ReviewedBy,
 		ApprovedAt: w.ApprovedAt,
+		PaidAt:     w.PaidAt,
+	}
+
+	if w.PayoutBatchID != nil {
+		res.PayoutBatchID = w.PayoutBatchID.String()
+	}
+
+	if w.PayoutAccount.ID != uuid.Nil {
+		res.PayoutAccount = toPayoutAccountDTO(&w.PayoutAccount)
+	}
+
+	for _, h := range w.History {
+		res.Timeline = append(res.Timeline, dto.WithdrawalTimelineResponse{
+			Status:    h.Status,
+			Note:      h.Note,
+			ActorID:   h.ActorID,
+			CreatedAt: h.CreatedAt,
+		})
 	}
 
 	return res
 }
+
+func toPayoutAccountDTO(a *models.PayoutAccount) *dto.PayoutAccountResponse {
+	return &dto.PayoutAccountResponse{
+		ID:            a.ID.String(),
+		UserID:        a.UserID.String(),
+		BankCode:      a.BankCode,
+		AccountNumber: a.AccountNumber,
+		HolderName:    a.HolderName,
+		Status:        a.Status,
+		VerifiedAt:    a.VerifiedAt,
+		CreatedAt:     a.CreatedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// APIScopes are the scopes an API client can be granted, "*" grants all of them
+var APIScopes = []string{
+	"account:read", "account:write",
+	"events:read", "events:write",
+	"orders:read", "orders:write",
+	"tickets:read", "tickets:write",
+	"checkin:write",
+	"withdrawals:read", "withdrawals:write",
+	"organizer:read",
+	"admin:read", "admin:write",
+}
+
+// scopeAreas maps the first path segment after /api/v1 to the scope area guarding it
+var scopeAreas = map[string]string{
+	"auth":        "account",
+	"user":        "account",
+	"events":      "events",
+	"tickets":     "events",
+	"orders":      "orders",
+	"payments":    "orders",
+	"user-ticket": "tickets",
+	"withdrawals": "withdrawals",
+	"organizer":   "organizer",
+	"admin":       "admin",
+}
+
+// GenerateAPIKey returns a new key and the short prefix shown in listings, only the hash is ever stored
+func GenerateAPIKey() (string, string, error) {
+	secret, err := GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	key := "tk_" + secret
+	return key, key[:11], nil
+}
+
+// HashAPIKey is a plain SHA-256, keys are random 256-bit values so a slow hash adds nothing
+// and the hash can be looked up directly
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequiredScope derives the scope a route needs from its path, reads need <area>:read and
+// everything else <area>:write, ticket validation and use need checkin:write. A path outside the
+// known areas needs the global wildcard rather than a scope no client can be granted
+func RequiredScope(method, fullPath string) string {
+	path := strings.TrimPrefix(fullPath, "/api/v1/")
+	if path == fullPath || path == "" {
+		return ""
+	}
+
+	if path == "user-ticket/validate" || path == "user-ticket/:id/use" {
+		return "checkin:write"
+	}
+
+	segment, _, _ := strings.Cut(path, "/")
+	area, ok := scopeAreas[segment]
+	if !ok {
+		return "*"
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return area + ":read"
+	}
+	return area + ":write"
+}
+
+// ScopeAllowed accepts an exact scope, the area wildcard (events:*) or the global wildcard (*)
+func ScopeAllowed(granted []string, required string) bool {
+	if required == "" || slices.Contains(granted, "*") || slices.Contains(granted, required) {
+		return true
+	}
+	area, _, _ := strings.Cut(required, ":")
+	return slices.Contains(granted, area+":*")
+}
+
+// ValidAPIScope reports whether the scope can be granted to a client
+func ValidAPIScope(scope string) bool {
+	if scope == "*" || slices.Contains(APIScopes, scope) {
+		return true
+	}
+	area, action, ok := strings.Cut(scope, ":")
+	return ok && action == "*" && slices.ContainsFunc(APIScopes, func(s string) bool {
+		return strings.HasPrefix(s, area+":")
+	})
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/models"
 	"github.com/gin-gonic/gin"
@@ -30,3 +32,177 @@ func buildDescription(action string, resource string, payload any) string {
 	}
 	return fmt.Sprintf("User performed %s on %s with data: %s", action, resource, string(jsonData))
 }
+
+// AuditAction names what the handler did and attaches the resulting state, the audit middleware writes the entry
+func AuditAction(c *gin.Context, action string, resource string, after any) {
+	c.Set("auditAction", action)
+	c.Set("auditResource", resource)
+	c.Set("auditAfter", after)
+}
+
+// AuditBefore attaches the state of the resource before the change so the entry carries a diff
+func AuditBefore(c *gin.Context, before any) {
+	c.Set("auditBefore", before)
+}
+
+// BuildRequestAuditLog builds the entry for a finished request, anything the handler did not name is
+// derived from the route, the request body stands in for the "after" state
+func BuildRequestAuditLog(c *gin.Context, body map[string]any) *models.AuditLog {
+	path := strings.TrimPrefix(c.FullPath(), "/api/v1")
+	action, resource := routeAction(c.Request.Method, path)
+
+	if v, ok := c.Get("auditAction"); ok {
+		action = v.(string)
+	}
+	if v, ok := c.Get("auditResource"); ok {
+		resource = v.(string)
+	}
+
+	var after any = body
+	if v, ok := c.Get("auditAfter"); ok {
+		after = v
+	}
+	before, _ := c.Get("auditBefore")
+
+	userID, _ := c.Get("userID")
+	actor, _ := userID.(string)
+
+	entry := BuildAuditLog(c, actor, action, resource, RedactAuditData(after))
+	entry.ImpersonatorID = GetImpersonatorID(c)
+	entry.ResourceID = c.Param("id")
+	entry.Method = c.Request.Method
+	entry.Path = c.Request.URL.Path
+	entry.StatusCode = c.Writer.Status()
+
+	// a failed request changed nothing, only the attempt is kept
+	if entry.StatusCode >= http.StatusBadRequest {
+		entry.Description = fmt.Sprintf("User attempted %s on %s", action, resource)
+		return entry
+	}
+
+	entry.Before = marshalAudit(RedactAuditData(before))
+	entry.After = marshalAudit(RedactAuditData(after))
+	entry.Changes = marshalAudit(DiffAuditData(before, after))
+	return entry
+}
+
+// routeAction derives the default action and resource from the route, e.g. POST /orders/:id/refund is
+// "refund" on "orders" and DELETE /admin/api-clients/:id is "delete" on "api-clients"
+func routeAction(method, path string) (string, string) {
+	var static []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			static = append(static, segment)
+		}
+	}
+	if len(static) > 1 && static[0] == "admin" {
+		static = static[1:]
+	}
+
+	action := map[string]string{
+		http.MethodPost:   "create",
+		http.MethodPut:    "update",
+		http.MethodPatch:  "update",
+		http.MethodDelete: "delete",
+		http.MethodGet:    "view",
+	}[method]
+
+	if len(static) == 0 {
+		return action, "unknown"
+	}
+	if len(static) > 1 {
+		action = static[len(static)-1]
+	}
+	return action, static[0]
+}
+
+// auditSensitiveKeys are never written to the audit trail, matched case-insensitively. New request or
+// response fields holding credentials, one-time codes or account and document numbers belong here
+var auditSensitiveKeys = map[string]bool{
+	"password": true, "currentpassword": true, "newpassword": true, "confirmpassword": true,
+	"token": true, "accesstoken": true, "refreshtoken": true, "linktoken": true, "challengetoken": true,
+	"idtoken": true, "codeverifier": true, "code": true, "otp": true, "secret": true, "clientsecret": true,
+	"recoverycode": true, "recoverycodes": true, "apikey": true, "key": true, "qrcode": true,
+	"accountnumber": true, "documentnumber": true,
+}
+
+// RedactAuditData turns the value into plain JSON data with every sensitive field masked
+func RedactAuditData(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil
+	}
+	return redact(data)
+}
+
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if auditSensitiveKeys[strings.ToLower(key)] {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redact(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+// AuditChange is one field that differs between the before and after snapshots
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// DiffAuditData compares the top-level fields of both snapshots, it returns nil when either side
+// is missing or is not an object. Fields missing from the after snapshot are left out since it is
+// often the partial request body of an update
+func DiffAuditData(before, after any) map[string]AuditChange {
+	from, ok := RedactAuditData(before).(map[string]any)
+	if !ok {
+		return nil
+	}
+	to, ok := RedactAuditData(after).(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	changes := map[string]AuditChange{}
+	for key, value := range to {
+		previous, existed := from[key]
+		if !existed || !jsonEqual(previous, value) {
+			changes[key] = AuditChange{From: previous, To: value}
+		}
+	}
+	return changes
+}
+
+func jsonEqual(a, b any) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
+
+func marshalAudit(value any) string {
+	if value == nil {
+		return ""
+	}
+	raw, err := json.Marshal(value)
+	if err != nil || string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactAuditDataMasksSensitiveFields(t *testing.T) {
+	payloads := map[string]any{
+		"2fa verify":     map[string]any{"challengeToken": "ch_123", "code": "123456"},
+		"payout account": map[string]any{"bankCode": "014", "accountNumber": "1234567890", "accountName": "Jane"},
+		"identity":       map[string]any{"documentType": "passport", "documentNumber": "X1234567"},
+		"nested": map[string]any{"accounts": []any{
+			map[string]any{"AccountNumber": "1234567890"},
+		}},
+		"struct": struct {
+			Email         string `json:"email"`
+			RecoveryCodes []string
+		}{"jane@example.com", []string{"aaaa-bbbb"}},
+	}
+
+	secrets := []string{"ch_123", "123456", "1234567890", "X1234567", "aaaa-bbbb"}
+	for name, payload := range payloads {
+		raw, err := json.Marshal(RedactAuditData(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(string(raw), secret) {
+				t.Errorf("%s: %s leaked into %s", name, secret, raw)
+			}
+		}
+	}
+
+	// everything else is kept as it is
+	redacted := RedactAuditData(payloads["payout account"]).(map[string]any)
+	if redacted["bankCode"] != "014" || redacted["accountName"] != "Jane" {
+		t.Errorf("expected the other fields to be kept, got %v", redacted)
+	}
+}
+
+func TestDiffAuditDataNeverShowsSecrets(t *testing.T) {
+	before := map[string]any{"accountNumber": "111", "accountName": "Jane"}
+	after := map[string]any{"accountNumber": "222", "accountName": "Jane Doe"}
+
+	changes := DiffAuditData(before, after)
+	if _, changed := changes["accountNumber"]; changed {
+		t.Errorf("expected redacted fields to compare equal, got %v", changes["accountNumber"])
+	}
+	if changes["accountName"].To != "Jane Doe" {
+		t.Errorf("expected the name change, got %v", changes)
+	}
+}
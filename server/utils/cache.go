@@ -101,6 +101,15 @@ func KeyExists(redisKey string) bool {
 	return result > 0
 }
 
+// ClaimKey sets the key only when it does not exist yet, it reports false when someone else already holds it
+func ClaimKey(redisKey string, duration time.Duration) (bool, error) {
+	claimed, err := config.RedisClient.SetNX(config.Ctx, redisKey, "1", duration).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim key: %w", err)
+	}
+	return claimed, nil
+}
+
 func SetKeyExpiry(redisKey string, duration time.Duration) error {
 	err := config.RedisClient.Expire(config.Ctx, redisKey, duration).Err()
 	if err != nil {
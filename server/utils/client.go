@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo describes where a request comes from, it is stored on every login session
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	Device    string
+	Location  string
+
+	// Native clients get their tokens in the response body instead of cookies
+	Native bool
+}
+
+func GetClientInfo(c *gin.Context) ClientInfo {
+	ip := c.ClientIP()
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	return ClientInfo{
+		IP:        ip,
+		UserAgent: userAgent,
+		Device:    describeDevice(userAgent),
+		Location:  approximateLocation(c, ip),
+		Native:    TokensInBody(c),
+	}
+}
+
+// describeDevice turns a user agent into a short label such as "Chrome on Windows (desktop)"
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp") || strings.Contains(ua, "dart") || strings.Contains(ua, "cfnetwork"):
+		browser = "Mobile app"
+	case strings.Contains(ua, "curl") || strings.Contains(ua, "postman"):
+		browser = "API client"
+	}
+
+	os := "Unknown OS"
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ios"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os") || strings.Contains(ua, "macintosh"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	kind := "desktop"
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		kind = "tablet"
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		kind = "mobile"
+	}
+
+	return browser + " on " + os + " (" + kind + ")"
+}
+
+// approximateLocation relies on the geo headers set by the CDN/proxy in front of the API,
+// without them only local network addresses can be recognised
+func approximateLocation(c *gin.Context, ip string) string {
+	city := c.GetHeader("CF-IPCity")
+	country := c.GetHeader("CF-IPCountry")
+	if country == "" {
+		country = c.GetHeader("X-Country-Code")
+	}
+
+	switch {
+	case city != "" && country != "":
+		return city + ", " + country
+	case country != "":
+		return country
+	}
+
+	if parsed := net.ParseIP(ip); parsed != nil && (parsed.IsLoopback() || parsed.IsPrivate()) {
+		return "Local network"
+	}
+
+	return "Unknown"
+}
@@ -27,3 +27,17 @@ func MustGetRole(c *gin.Context) string {
 	}
 	return userRole
 }
+
+// GetImpersonatorID returns the admin behind an impersonation token, empty for regular tokens
+func GetImpersonatorID(c *gin.Context) string {
+	impersonatorID, _ := c.Get("impersonatorID")
+	idStr, _ := impersonatorID.(string)
+	return idStr
+}
+
+// GetSessionID returns the session the access token was issued for, tokens issued before sessions existed have none
+func GetSessionID(c *gin.Context) string {
+	sessionID, _ := c.Get("sessionID")
+	idStr, _ := sessionID.(string)
+	return idStr
+}
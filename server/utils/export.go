@@ -4,109 +4,777 @@ package utils
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/go-api-toolkit/response"
 	"github.com/gin-gonic/gin"
 	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
 )
 
-func ExportCSV(c *gin.Context, filename string, records any) {
-	v := reflect.ValueOf(records)
-	if v.Kind() != reflect.Slice {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid data format for CSV export"})
-		return
+// ExportDateLayout is how dates are written to exports, always in UTC
+const ExportDateLayout = "2006-01-02 15:04:05"
+
+// csvFlushEvery is how many CSV rows are buffered before they are pushed to the client
+const csvFlushEvery = 500
+
+var exportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":  "application/pdf",
+}
+
+// ExportContentType is the MIME type of an export format
+func ExportContentType(format string) string {
+	return exportContentTypes[format]
+}
+
+// StreamExport writes the rows handed over by stream straight to the response as a csv, xlsx or pdf
+// file named name plus the extension. Rows are written as the cursor yields them so the result set is
+// never held in memory, an error before anything was sent is answered as JSON. count is only asked for
+// xlsx files, a report that doesn't fit on a sheet is rejected before it is streamed
+func StreamExport[T any](c *gin.Context, format, name string, count func() (int, error), stream func(write func(T) error) error) {
+	out := &exportResponseWriter{c: c, filename: name + "." + format, contentType: exportContentTypes[format]}
+
+	if format == "xlsx" {
+		rows, err := count()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+		if err := CheckExportRows(format, rows); err != nil {
+			response.Error(c, err)
+			return
+		}
+	}
+
+	if err := WriteExport(out, format, stream); err != nil {
+		if !out.started {
+			response.Error(c, err)
+			return
+		}
+		// the file is already partly sent, the client only sees a truncated download
+		GetLogger().Error("export interrupted", zap.String("file", out.filename), zap.Error(err))
+		c.Error(err)
+	}
+}
+
+// WriteExport writes the rows handed over by stream to w as a csv, xlsx or pdf file. The columns come from
+// the json tags of T, an `export` tag of money, percent or status picks the formatting of the column and
+// a total option, e.g. `export:"money,total"`, sums the column in the totals row of the xlsx file
+func WriteExport[T any](w io.Writer, format string, stream func(write func(T) error) error) error {
+	columns := exportColumns(reflect.TypeFor[T]())
+
+	var writer exportWriter
+	switch format {
+	case "csv":
+		writer = newCSVExportWriter(w, columns)
+	case "xlsx":
+		xlsx, err := newXLSXExportWriter(w, columns)
+		if err != nil {
+			return response.NewInternalServerError("failed to generate XLSX", err)
+		}
+		writer = xlsx
+	case "pdf":
+		writer = newPDFExportWriter(w, columns)
+	default:
+		return response.NewBadRequest("Unsupported export format")
+	}
+
+	if err := stream(func(row T) error {
+		return writer.WriteRow(reflect.ValueOf(row))
+	}); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return response.NewInternalServerError("failed to generate export", err)
+	}
+	return nil
+}
+
+// CheckExportRows rejects an export that can't hold the rows, only xlsx files have a limit
+func CheckExportRows(format string, rows int) error {
+	if limit := xlsxRowLimit(); format == "xlsx" && rows > limit {
+		return response.NewBadRequest(fmt.Sprintf("The report has more than %d rows, which is more than an XLSX export can hold. Narrow the filters or export as CSV", limit))
+	}
+	return nil
+}
+
+type exportWriter interface {
+	WriteRow(row reflect.Value) error
+	Close() error
+}
+
+// exportResponseWriter sends the download headers with the first byte, until then the response can
+// still turn into an error
+type exportResponseWriter struct {
+	c           *gin.Context
+	filename    string
+	contentType string
+	started     bool
+}
+
+func (w *exportResponseWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Disposition", "attachment; filename="+w.filename)
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Cache-Control", "no-cache")
+		w.c.Status(http.StatusOK)
 	}
+	return w.c.Writer.Write(p)
+}
 
-	c.Header("Content-Disposition", "attachment; filename="+filename)
-	c.Header("Content-Type", "text/csv; charset=utf-8")
-	c.Header("Cache-Control", "no-cache")
+func (w *exportResponseWriter) Flush() {
+	if w.started {
+		w.c.Writer.Flush()
+	}
+}
 
-	writer := csv.NewWriter(c.Writer)
+type exportColumn struct {
+	index  int
+	header string
+	format string
+	total  bool
+}
 
-	// headers
-	if v.Len() > 0 {
-		first := v.Index(0)
-		var headers []string
-		for i := 0; i < first.NumField(); i++ {
-			headers = append(headers, first.Type().Field(i).Tag.Get("json"))
+func exportColumns(t reflect.Type) []exportColumn {
+	var columns []exportColumn
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
 		}
-		writer.Write(headers)
+		if name == "" {
+			name = field.Name
+		}
+		col := exportColumn{index: i, header: exportHeader(name)}
+		for _, option := range strings.Split(field.Tag.Get("export"), ",") {
+			if option == "total" {
+				col.total = true
+			} else if option != "" {
+				col.format = option
+			}
+		}
+		columns = append(columns, col)
 	}
+	return columns
+}
 
-	// rows
-	for i := 0; i < v.Len(); i++ {
-		val := v.Index(i)
-		var row []string
-		for j := 0; j < val.NumField(); j++ {
-			row = append(row, toString(val.Field(j).Interface()))
+// exportHeader turns a json name into a column title, e.g. "refundedAt" into "Refunded At" and "orderId"
+// into "Order ID"
+func exportHeader(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
 		}
-		writer.Write(row)
 	}
+	words = append(words, name[start:])
 
-	writer.Flush()
+	for i, word := range words {
+		switch strings.ToLower(word) {
+		case "id", "ip", "url", "qr":
+			words[i] = strings.ToUpper(word)
+		default:
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
 }
 
-func toString(v interface{}) string {
-	return fmt.Sprintf("%v", v)
+// field returns the value of the column, nil for nil pointers
+func (col exportColumn) field(row reflect.Value) any {
+	field := row.Field(col.index)
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	return field.Interface()
 }
 
-func ExportPDF(c *gin.Context, filename string, records interface{}) {
-	v := reflect.ValueOf(records)
-	if v.Kind() != reflect.Slice || v.Len() == 0 {
-		c.JSON(500, gin.H{"error": "Invalid or empty data for PDF export"})
-		return
+// text formats the value of the column for csv and pdf, grouped adds thousands separators to money
+func (col exportColumn) text(row reflect.Value, grouped bool) string {
+	switch v := col.field(row).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(ExportDateLayout)
+	case float64:
+		switch col.format {
+		case "money":
+			return FormatMoney(v, grouped)
+		case "percent":
+			return strconv.FormatFloat(v, 'f', 2, 64) + "%"
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case string:
+		if col.format == "status" {
+			return FormatStatus(v)
+		}
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
 	}
+}
 
-	pdf := gofpdf.New("L", "mm", "A4", "")
-	pdf.SetFont("Arial", "", 10)
-	pdf.AddPage()
+// FormatMoney rounds the amount to two decimals, grouped separates thousands with commas
+func FormatMoney(amount float64, grouped bool) string {
+	text := strconv.FormatFloat(math.Round(amount*100)/100, 'f', 2, 64)
+	if !grouped {
+		return text
+	}
 
-	first := v.Index(0)
-	numCols := first.NumField()
-	var headers []string
-	var colWidths []float64
-
-	for i := 0; i < numCols; i++ {
-		field := first.Type().Field(i)
-		fieldName := field.Tag.Get("json")
-		headers = append(headers, fieldName)
-
-		switch fieldName {
-		case "orderId", "paymentId", "userId", "withdrawalId":
-			colWidths = append(colWidths, 60)
-		case "email":
-			colWidths = append(colWidths, 55)
-		case "amount", "status":
-			colWidths = append(colWidths, 30)
-		case "createdAt", "approvedAt", "refundedAt":
-			colWidths = append(colWidths, 45)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, decimals, _ := strings.Cut(text, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + "." + decimals
+}
+
+// FormatStatus turns a status value into a label, e.g. "pending_review" into "Pending Review"
+func FormatStatus(status string) string {
+	words := strings.Fields(strings.ReplaceAll(status, "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+type csvExportWriter struct {
+	out     io.Writer
+	writer  *csv.Writer
+	columns []exportColumn
+	rows    int
+	err     error
+}
+
+func newCSVExportWriter(w io.Writer, columns []exportColumn) *csvExportWriter {
+	writer := &csvExportWriter{out: w, writer: csv.NewWriter(w), columns: columns}
+
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+	writer.err = writer.writer.Write(headers)
+	return writer
+}
+
+func (w *csvExportWriter) WriteRow(row reflect.Value) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	record := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		record[i] = col.text(row, false)
+	}
+	if err := w.writer.Write(record); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%csvFlushEvery == 0 {
+		w.writer.Flush()
+		if flusher, ok := w.out.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	return w.writer.Error()
+}
+
+func (w *csvExportWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// xlsxSampleRows is how many rows are held back to size the columns, the stream writer needs the widths
+// before the first row is written
+const xlsxSampleRows = 200
+
+// xlsxMaxColumnWidth keeps long free text such as reasons from stretching a column across the screen
+const xlsxMaxColumnWidth = 60
+
+// XLSXMaxRows is how many data rows fit on the report sheet, Excel stops at 1,048,576 rows and the
+// header and the totals row take two of them
+const XLSXMaxRows = 1048576 - 2
+
+// xlsxRowLimit is how many data rows an XLSX export takes, EXPORT_XLSX_MAX_ROWS within the sheet limit
+func xlsxRowLimit() int {
+	if limit := config.AppConfig.ExportXLSXMaxRows; limit > 0 && limit < XLSXMaxRows {
+		return limit
+	}
+	return XLSXMaxRows
+}
+
+// xlsxExportWriter streams the rows to a "Report" sheet with a frozen header and a totals row, and fills
+// a "Summary" sheet with the row count, the totals and a breakdown per status column. The stream writer
+// spools the rows to a temp file, but excelize zips the whole workbook in memory on Close, which is why
+// the rows are capped at EXPORT_XLSX_MAX_ROWS
+type xlsxExportWriter struct {
+	out        io.Writer
+	file       *excelize.File
+	stream     *excelize.StreamWriter
+	columns    []exportColumn
+	styles     map[string]int
+	sample     []reflect.Value
+	started    bool
+	rows       int
+	totals     []float64
+	breakdowns []*xlsxBreakdown
+}
+
+// xlsxBreakdown counts the rows and sums the total columns per value of a status column
+type xlsxBreakdown struct {
+	column int
+	values []string
+	groups map[string]*xlsxGroup
+}
+
+type xlsxGroup struct {
+	rows   int
+	totals []float64
+}
+
+func newXLSXExportWriter(w io.Writer, columns []exportColumn) (*xlsxExportWriter, error) {
+	file := excelize.NewFile()
+	file.SetSheetName("Sheet1", "Summary")
+	if _, err := file.NewSheet("Report"); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter("Report")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	moneyFormat := 4
+	// percent columns hold the percentage itself, e.g. 12.5 for 12.5%, the built-in format would show 1250%
+	percentFormat := `0.00"%"`
+	dateFormat := "yyyy-mm-dd hh:mm:ss"
+	styles := map[string]*excelize.Style{
+		"header": {
+			Font:   &excelize.Font{Bold: true},
+			Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"E7E6E6"}},
+			Border: []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+		},
+		"money":        {NumFmt: moneyFormat},
+		"percent":      {CustomNumFmt: &percentFormat},
+		"date":         {CustomNumFmt: &dateFormat},
+		"total":        {Font: &excelize.Font{Bold: true}, Border: []excelize.Border{{Type: "top", Color: "000000", Style: 1}}},
+		"total-money":  {Font: &excelize.Font{Bold: true}, Border: []excelize.Border{{Type: "top", Color: "000000", Style: 1}}, NumFmt: moneyFormat},
+		"total-number": {Font: &excelize.Font{Bold: true}, Border: []excelize.Border{{Type: "top", Color: "000000", Style: 1}}, NumFmt: 3},
+	}
+	writer := &xlsxExportWriter{
+		out:     w,
+		file:    file,
+		stream:  stream,
+		columns: columns,
+		styles:  map[string]int{},
+		totals:  make([]float64, len(columns)),
+	}
+	for name, style := range styles {
+		id, err := file.NewStyle(style)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		writer.styles[name] = id
+	}
+
+	for i, col := range columns {
+		if col.format == "status" {
+			writer.breakdowns = append(writer.breakdowns, &xlsxBreakdown{column: i, groups: map[string]*xlsxGroup{}})
+		}
+	}
+	return writer, nil
+}
+
+func (w *xlsxExportWriter) WriteRow(row reflect.Value) error {
+	// rows added after the count was checked, nothing is sent before Close so the export is still rejected cleanly
+	if limit := xlsxRowLimit(); w.rows+len(w.sample) >= limit {
+		return CheckExportRows("xlsx", limit+1)
+	}
+	w.count(row)
+
+	if !w.started {
+		w.sample = append(w.sample, row)
+		if len(w.sample) < xlsxSampleRows {
+			return nil
+		}
+		return w.start()
+	}
+	return w.writeRow(row)
+}
+
+// count adds the row to the totals and the status breakdowns of the summary
+func (w *xlsxExportWriter) count(row reflect.Value) {
+	for i, col := range w.columns {
+		if col.total {
+			amount, _ := exportNumber(col.field(row))
+			w.totals[i] += amount
+		}
+	}
+
+	for _, breakdown := range w.breakdowns {
+		value := w.columns[breakdown.column].text(row, false)
+		group, ok := breakdown.groups[value]
+		if !ok {
+			group = &xlsxGroup{totals: make([]float64, len(w.columns))}
+			breakdown.groups[value] = group
+			breakdown.values = append(breakdown.values, value)
+		}
+		group.rows++
+		for i, col := range w.columns {
+			if col.total {
+				amount, _ := exportNumber(col.field(row))
+				group.totals[i] += amount
+			}
+		}
+	}
+}
+
+// start sizes the columns to the sampled rows, freezes the header and writes the rows held back so far
+func (w *xlsxExportWriter) start() error {
+	w.started = true
+
+	for i, col := range w.columns {
+		width := utf8.RuneCountInString(col.header)
+		for _, row := range w.sample {
+			width = max(width, utf8.RuneCountInString(col.text(row, true)))
+		}
+		if col.total {
+			// the totals row is usually wider than any single row
+			width += 3
+		}
+		if err := w.stream.SetColWidth(i+1, i+1, float64(min(width+2, xlsxMaxColumnWidth))); err != nil {
+			return err
+		}
+	}
+
+	if err := w.stream.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	headers := make([]any, len(w.columns))
+	for i, col := range w.columns {
+		headers[i] = excelize.Cell{StyleID: w.styles["header"], Value: col.header}
+	}
+	if err := w.stream.SetRow("A1", headers); err != nil {
+		return err
+	}
+
+	sample := w.sample
+	w.sample = nil
+	for _, row := range sample {
+		if err := w.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *xlsxExportWriter) writeRow(row reflect.Value) error {
+	values := make([]any, len(w.columns))
+	for i, col := range w.columns {
+		switch v := col.field(row).(type) {
+		case time.Time:
+			if v.IsZero() {
+				values[i] = nil
+				continue
+			}
+			values[i] = excelize.Cell{StyleID: w.styles["date"], Value: v.UTC()}
+		case float64:
+			if style, ok := w.styles[col.format]; ok {
+				values[i] = excelize.Cell{StyleID: style, Value: math.Round(v*100) / 100}
+				continue
+			}
+			values[i] = v
+		case string:
+			values[i] = col.text(row, false)
 		default:
-			colWidths = append(colWidths, 40)
+			values[i] = v
+		}
+	}
+
+	w.rows++
+	cell, _ := excelize.CoordinatesToCellName(1, w.rows+1)
+	return w.stream.SetRow(cell, values)
+}
+
+// writeTotals adds a SUM row under the data, the cached value lets viewers that don't recalculate show it
+func (w *xlsxExportWriter) writeTotals() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	values := make([]any, len(w.columns))
+	for i, col := range w.columns {
+		if !col.total {
+			values[i] = excelize.Cell{StyleID: w.styles["total"]}
+			continue
+		}
+		name, _ := excelize.ColumnNumberToName(i + 1)
+		values[i] = excelize.Cell{
+			StyleID: w.styles[w.totalStyle(col)],
+			Formula: fmt.Sprintf("SUM(%s2:%s%d)", name, name, w.rows+1),
+			Value:   w.totalValue(col, w.totals[i]),
+		}
+	}
+	if !w.columns[0].total {
+		values[0] = excelize.Cell{StyleID: w.styles["total"], Value: "Total"}
+	}
+
+	cell, _ := excelize.CoordinatesToCellName(1, w.rows+2)
+	return w.stream.SetRow(cell, values)
+}
+
+// writeSummary fills the summary sheet, it only holds aggregates so the regular cell API is used
+func (w *xlsxExportWriter) writeSummary() error {
+	sheet := "Summary"
+	row := 1
+	set := func(col int, value any, style string) error {
+		cell, _ := excelize.CoordinatesToCellName(col, row)
+		if err := w.file.SetCellValue(sheet, cell, value); err != nil {
+			return err
+		}
+		if style == "" {
+			return nil
+		}
+		return w.file.SetCellStyle(sheet, cell, cell, w.styles[style])
+	}
+
+	var totalColumns []int
+	for i, col := range w.columns {
+		if col.total {
+			totalColumns = append(totalColumns, i)
 		}
 	}
 
-	for i := range numCols {
-		pdf.CellFormat(colWidths[i], 8, headers[i], "1", 0, "", false, 0, "")
+	if err := set(1, "Generated At", "header"); err != nil {
+		return err
+	}
+	if err := set(2, time.Now().UTC(), "date"); err != nil {
+		return err
+	}
+	row++
+	if err := set(1, "Rows", "header"); err != nil {
+		return err
+	}
+	if err := set(2, w.rows, ""); err != nil {
+		return err
 	}
-	pdf.Ln(-1)
 
-	for i := range v.Len() {
-		row := v.Index(i)
-		for j := 0; j < numCols; j++ {
-			cellValue := fmt.Sprintf("%v", row.Field(j).Interface())
-			pdf.CellFormat(colWidths[j], 8, truncate(cellValue, int(colWidths[j]/2.5)), "1", 0, "", false, 0, "")
+	for _, i := range totalColumns {
+		row++
+		if err := set(1, "Total "+w.columns[i].header, "header"); err != nil {
+			return err
+		}
+		if err := set(2, w.totalValue(w.columns[i], w.totals[i]), w.totalStyle(w.columns[i])); err != nil {
+			return err
 		}
-		pdf.Ln(-1)
 	}
 
-	c.Header("Content-Disposition", "attachment; filename="+filename)
-	c.Header("Content-Type", "application/pdf")
-	c.Header("Cache-Control", "no-cache")
+	for _, breakdown := range w.breakdowns {
+		row += 2
+		if err := set(1, w.columns[breakdown.column].header, "header"); err != nil {
+			return err
+		}
+		if err := set(2, "Rows", "header"); err != nil {
+			return err
+		}
+		for n, i := range totalColumns {
+			if err := set(n+3, w.columns[i].header, "header"); err != nil {
+				return err
+			}
+		}
+
+		for _, value := range breakdown.values {
+			group := breakdown.groups[value]
+			row++
+			if err := set(1, value, ""); err != nil {
+				return err
+			}
+			if err := set(2, group.rows, ""); err != nil {
+				return err
+			}
+			for n, i := range totalColumns {
+				style := ""
+				if w.columns[i].format == "money" {
+					style = "money"
+				}
+				if err := set(n+3, w.totalValue(w.columns[i], group.totals[i]), style); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := w.file.SetColWidth(sheet, "A", "A", 28); err != nil {
+		return err
+	}
+	last, _ := excelize.ColumnNumberToName(max(len(totalColumns)+2, 2))
+	return w.file.SetColWidth(sheet, "B", last, 20)
+}
+
+func (w *xlsxExportWriter) totalStyle(col exportColumn) string {
+	if col.format == "money" {
+		return "total-money"
+	}
+	return "total-number"
+}
+
+// totalValue rounds money to cents, the sum of many rounded amounts can drift past them
+func (w *xlsxExportWriter) totalValue(col exportColumn, total float64) float64 {
+	if col.format == "money" {
+		return math.Round(total*100) / 100
+	}
+	return total
+}
+
+func (w *xlsxExportWriter) Close() error {
+	defer w.file.Close()
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if err := w.writeTotals(); err != nil {
+		return err
+	}
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	if err := w.writeSummary(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+// exportNumber returns the value of a numeric column as a float64
+func exportNumber(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// pdfExportWriter lays the rows out as a table, gofpdf keeps the document in memory so the rows stop
+// at EXPORT_PDF_MAX_ROWS with a note pointing to the CSV export
+type pdfExportWriter struct {
+	out       io.Writer
+	pdf       *gofpdf.Fpdf
+	columns   []exportColumn
+	widths    []float64
+	rows      int
+	truncated bool
+}
+
+func newPDFExportWriter(w io.Writer, columns []exportColumn) *pdfExportWriter {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetFont("Arial", "", 8)
+
+	// the page width is shared by the columns, id and email columns get a double share
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	shares := make([]float64, len(columns))
+	total := 0.0
+	for i, col := range columns {
+		shares[i] = 1
+		if strings.HasSuffix(col.header, " ID") || col.header == "Email" {
+			shares[i] = 2
+		}
+		total += shares[i]
+	}
+	widths := make([]float64, len(columns))
+	for i := range columns {
+		widths[i] = (pageWidth - left - right) * shares[i] / total
+	}
+
+	writer := &pdfExportWriter{out: w, pdf: pdf, columns: columns, widths: widths}
+	pdf.SetHeaderFunc(writer.header)
+	pdf.AddPage()
+	return writer
+}
+
+// header repeats the column titles on every page
+func (w *pdfExportWriter) header() {
+	w.pdf.SetFont("Arial", "B", 8)
+	for i, col := range w.columns {
+		w.pdf.CellFormat(w.widths[i], 7, truncate(col.header, int(w.widths[i]/1.6)), "1", 0, "", false, 0, "")
+	}
+	w.pdf.Ln(-1)
+	w.pdf.SetFont("Arial", "", 8)
+}
+
+func (w *pdfExportWriter) WriteRow(row reflect.Value) error {
+	if w.rows >= config.AppConfig.ExportPDFMaxRows {
+		w.truncated = true
+		return nil
+	}
+	w.rows++
+
+	for i, col := range w.columns {
+		align := ""
+		if col.format == "money" || col.format == "percent" {
+			align = "R"
+		}
+		w.pdf.CellFormat(w.widths[i], 6, truncate(col.text(row, true), int(w.widths[i]/1.6)), "1", 0, align, false, 0, "")
+	}
+	w.pdf.Ln(-1)
+	return w.pdf.Error()
+}
 
-	if err := pdf.Output(c.Writer); err != nil {
-		c.JSON(500, gin.H{"error": "failed to generate PDF"})
+func (w *pdfExportWriter) Close() error {
+	if w.truncated {
+		w.pdf.Ln(4)
+		w.pdf.CellFormat(0, 6, fmt.Sprintf("Only the first %d rows are included, use the CSV export for the full report", w.rows), "", 1, "", false, 0, "")
 	}
+	return w.pdf.Output(w.out)
 }
 
 func truncate(s string, max int) string {
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+
+	"github.com/fiqrioemry/go-api-toolkit/response"
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+type exportTestRow struct {
+	OrderID    string    `json:"orderId"`
+	Status     string    `json:"status" export:"status"`
+	Amount     float64   `json:"amount" export:"money,total"`
+	FeePercent float64   `json:"feePercent" export:"percent"`
+	PaidAt     time.Time `json:"paidAt"`
+}
+
+var exportTestRows = []exportTestRow{
+	{OrderID: "o-1", Status: "paid", Amount: 1234.5, FeePercent: 12.5, PaidAt: time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)},
+	{OrderID: "o-2", Status: "refunded", Amount: 10, FeePercent: 0},
+}
+
+func writeTestExport(t *testing.T, format string, rows []exportTestRow) []byte {
+	t.Helper()
+	config.AppConfig = &config.Config{ExportPDFMaxRows: 100, ExportXLSXMaxRows: 100}
+	var out bytes.Buffer
+	err := WriteExport(&out, format, func(write func(exportTestRow) error) error {
+		for _, row := range rows {
+			if err := write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestWriteExportCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeTestExport(t, "csv", exportTestRows))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"Order ID", "Status", "Amount", "Fee Percent", "Paid At"},
+		{"o-1", "Paid", "1234.50", "12.50%", "2025-07-01 09:30:00"},
+		{"o-2", "Refunded", "10.00", "0.00%", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %v", len(want), records)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("record %d column %d: expected %q, got %q", i, j, want[i][j], records[i][j])
+			}
+		}
+	}
+}
+
+func TestWriteExportXLSX(t *testing.T) {
+	file, err := excelize.OpenReader(bytes.NewReader(writeTestExport(t, "xlsx", exportTestRows)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cells := map[string]string{
+		"A1": "Order ID",
+		"B2": "Paid",
+		"C2": "1,234.50",
+		"D2": "12.50%",
+		"D3": "0.00%",
+		"C4": "1,244.50",
+	}
+	for cell, want := range cells {
+		got, err := file.GetCellValue("Report", cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", cell, want, got)
+		}
+	}
+
+	if formula, _ := file.GetCellFormula("Report", "C4"); formula != "SUM(C2:C3)" {
+		t.Errorf("expected a SUM in the totals row, got %q", formula)
+	}
+	if panes, _ := file.GetPanes("Report"); !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("expected the header row to be frozen, got %+v", panes)
+	}
+}
+
+func TestStreamExportRejectsXLSXPastTheSheetLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	response.InitGin(response.InitConfig{Logger: zap.NewNop()})
+	config.AppConfig = &config.Config{ExportXLSXMaxRows: 1000}
+
+	streamed := false
+	stream := func(write func(exportTestRow) error) error {
+		streamed = true
+		return write(exportTestRows[0])
+	}
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/reports/orders?export=xlsx", nil)
+	StreamExport(c, "xlsx", "orders", func() (int, error) { return 1001, nil }, stream)
+
+	if recorder.Code != http.StatusBadRequest || streamed {
+		t.Fatalf("expected a 400 before streaming, got %d (streamed %v)", recorder.Code, streamed)
+	}
+
+	// csv has no limit, the count is not even asked for
+	recorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/reports/orders?export=csv", nil)
+	StreamExport(c, "csv", "orders", func() (int, error) {
+		t.Error("count must only run for xlsx")
+		return 0, nil
+	}, stream)
+
+	if recorder.Code != http.StatusOK || !streamed {
+		t.Fatalf("expected the csv to stream, got %d", recorder.Code)
+	}
+}
+
+func TestWriteExportXLSXStopsAtTheRowLimit(t *testing.T) {
+	config.AppConfig = &config.Config{ExportXLSXMaxRows: 1}
+
+	// rows added after the count was checked are refused before anything is written
+	var out bytes.Buffer
+	err := WriteExport(&out, "xlsx", func(write func(exportTestRow) error) error {
+		for _, row := range exportTestRows {
+			if err := write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var appErr *response.AppError
+	if !errors.As(err, &appErr) || appErr.HTTPStatus != http.StatusBadRequest || out.Len() != 0 {
+		t.Fatalf("expected a 400 with nothing written, got %v and %d bytes", err, out.Len())
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
@@ -11,18 +12,37 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+const (
+	AccessTokenTTL  = 60 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 type Claims struct {
-	UserID string `json:"userId"`
-	Role   string `json:"role"`
+	UserID    string `json:"userId"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	TwoFactor bool   `json:"mfa,omitempty"`
+
+	// set on impersonation tokens, UserID is then the impersonated user
+	ImpersonatorID string `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID, role string) (string, error) {
+// RefreshClaims identifies the session and the single refresh token (jti) that was issued for it
+type RefreshClaims struct {
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// GenerateAccessToken issues the short-lived token, twoFactor tells whether the session passed a 2FA check
+func GenerateAccessToken(userID, role, sessionID string, twoFactor bool) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		TwoFactor: twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(60 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    config.AppConfig.AppName,
@@ -40,7 +60,34 @@ func GenerateAccessToken(userID, role string) (string, error) {
 	return tokenString, nil
 }
 
-func GenerateRefreshToken(userID string) (string, error) {
+// GenerateImpersonationToken issues a time-boxed access token for an admin acting as a user, sessionID
+// identifies the impersonation so it can be ended early through MarkSessionRevoked
+func GenerateImpersonationToken(userID, role, impersonatorID, sessionID string, ttl time.Duration) (string, error) {
+	claims := Claims{
+		UserID:         userID,
+		Role:           role,
+		SessionID:      sessionID,
+		ImpersonatorID: impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    config.AppConfig.AppName,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	secretKey := []byte(config.AppConfig.AccessTokenSecret)
+
+	tokenString, err := token.SignedString(secretKey)
+	if err != nil {
+		return "", errors.New("failed to sign impersonation token: " + err.Error())
+	}
+
+	return tokenString, nil
+}
+
+func GenerateRefreshToken(userID, sessionID, tokenID string) (string, error) {
 	if userID == "" {
 		return "", errors.New("userID cannot be empty")
 	}
@@ -49,12 +96,16 @@ func GenerateRefreshToken(userID string) (string, error) {
 		return "", errors.New("refresh token secret is not configured")
 	}
 
-	claims := jwt.RegisteredClaims{
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		NotBefore: jwt.NewNumericDate(time.Now()),
-		Issuer:    config.AppConfig.AppName,
+	claims := RefreshClaims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    config.AppConfig.AppName,
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -91,12 +142,12 @@ func DecodeAccessToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token claims")
 }
 
-func DecodeRefreshToken(tokenString string) (string, error) {
+func DecodeRefreshToken(tokenString string) (*RefreshClaims, error) {
 	if tokenString == "" {
-		return "", errors.New("token cannot be empty")
+		return nil, errors.New("token cannot be empty")
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
@@ -104,14 +155,59 @@ func DecodeRefreshToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", errors.New("failed to parse refresh token: " + err.Error())
+		return nil, errors.New("failed to parse refresh token: " + err.Error())
+	}
+
+	if claims, ok := token.Claims.(*RefreshClaims); ok && token.Valid && claims.ID != "" {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid refresh token claims")
+}
+
+// GetAccessToken reads the access token from the Authorization bearer header, falling back to the cookie
+func GetAccessToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		return claims.Subject, nil
+	token, _ := c.Cookie("accessToken")
+	return token
+}
+
+// TokensInBody reports whether the client asked for tokens in the response body instead of cookies,
+// either with the X-Token-Delivery: body header or by already authenticating with a bearer token
+func TokensInBody(c *gin.Context) bool {
+	if strings.EqualFold(c.GetHeader("X-Token-Delivery"), "body") {
+		return true
 	}
+	scheme, _, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	return strings.EqualFold(scheme, "Bearer")
+}
+
+// MarkSessionRevoked flags the session for as long as its access tokens can still be valid
+func MarkSessionRevoked(sessionID string) {
+	AddKeys("ticket:session_revoked:"+sessionID, "1", AccessTokenTTL)
+}
+
+func IsSessionRevoked(sessionID string) bool {
+	return KeyExists("ticket:session_revoked:" + sessionID)
+}
+
+// MarkUserBlocked rejects every access token of a suspended or banned user until they expire
+func MarkUserBlocked(userID string) {
+	AddKeys("ticket:user_blocked:"+userID, "1", AccessTokenTTL)
+}
+
+func ClearUserBlocked(userID string) {
+	DeleteKeys("ticket:user_blocked:" + userID)
+}
 
-	return "", errors.New("invalid refresh token claims")
+func IsUserBlocked(userID string) bool {
+	return KeyExists("ticket:user_blocked:" + userID)
 }
 
 func SetRefreshTokenCookie(c *gin.Context, refreshToken string) {
@@ -119,10 +215,10 @@ func SetRefreshTokenCookie(c *gin.Context, refreshToken string) {
 
 	if domain == "localhost" {
 		c.SetSameSite(http.SameSiteLaxMode) // Gunakan Lax untuk localhost
-		c.SetCookie("refreshToken", refreshToken, 7*24*3600, "/", domain, false, false)
+		c.SetCookie("refreshToken", refreshToken, int(RefreshTokenTTL.Seconds()), "/", domain, false, false)
 	} else {
 		c.SetSameSite(http.SameSiteNoneMode)
-		c.SetCookie("refreshToken", refreshToken, 7*24*3600, "/", domain, true, true)
+		c.SetCookie("refreshToken", refreshToken, int(RefreshTokenTTL.Seconds()), "/", domain, true, true)
 	}
 }
 
@@ -131,10 +227,10 @@ func SetAccessTokenCookie(c *gin.Context, accessToken string) {
 
 	if domain == "localhost" {
 		c.SetSameSite(http.SameSiteLaxMode)
-		c.SetCookie("accessToken", accessToken, 3600, "/", domain, false, false)
+		c.SetCookie("accessToken", accessToken, int(AccessTokenTTL.Seconds()), "/", domain, false, false)
 	} else {
 		c.SetSameSite(http.SameSiteNoneMode)
-		c.SetCookie("accessToken", accessToken, 3600, "/", domain, true, true)
+		c.SetCookie("accessToken", accessToken, int(AccessTokenTTL.Seconds()), "/", domain, true, true)
 	}
 }
 
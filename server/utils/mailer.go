@@ -19,14 +19,19 @@ type EmailTemplate struct {
 }
 
 type EmailData struct {
-	UserName    string
-	Email       string
-	ResetLink   string
-	OTPCode     string
-	ExpiryTime  string
-	AppName     string
-	SupportURL  string
-	CompanyName string
+	UserName     string
+	Email        string
+	ResetLink    string
+	LoginLink    string
+	DownloadLink string
+	NewEmail     string
+	OTPCode      string
+	Heading      string
+	Message      string
+	ExpiryTime   string
+	AppName      string
+	SupportURL   string
+	CompanyName  string
 }
 
 // Email templates
@@ -85,6 +90,236 @@ var emailTemplates = map[string]EmailTemplate{
 </html>`,
 	},
 
+	"magic_link": {
+		Subject: "Your Sign-in Link - {{.AppName}}",
+		Template: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Sign In</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px; }
+        .content { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .button { display: inline-block; background: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 0; }
+        .otp-box { background: #e3f2fd; border: 2px solid #2196f3; padding: 20px; text-align: center; border-radius: 8px; margin: 20px 0; }
+        .otp-code { font-size: 32px; font-weight: bold; color: #1976d2; letter-spacing: 5px; margin: 10px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; text-align: center; }
+        .warning { background: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.AppName}}</h1>
+        <p>Sign-in Request</p>
+    </div>
+
+    <div class="content">
+        <h2>Hello {{.UserName}},</h2>
+
+        <p>Click the button below to sign in to {{.AppName}}, no password needed:</p>
+
+        <div style="text-align: center;">
+            <a href="{{.LoginLink}}" class="button">Sign In</a>
+        </div>
+
+        <p>Or enter this code on the sign-in page:</p>
+
+        <div class="otp-box">
+            <div class="otp-code">{{.OTPCode}}</div>
+        </div>
+
+        <div class="warning">
+            <strong>Important:</strong> This link and code expire in {{.ExpiryTime}} and can only be used once. Don't share them with anyone.
+        </div>
+
+        <p>If you didn't try to sign in, you can safely ignore this email.</p>
+
+        <p>Best regards,<br>The {{.CompanyName}} Team</p>
+    </div>
+
+    <div class="footer">
+        <p>This email was sent to {{.Email}}. If you didn't request this, please contact support.</p>
+    </div>
+</body>
+</html>`,
+	},
+	"email_change_confirm": {
+		Subject: "Confirm Your New Email - {{.AppName}}",
+		Template: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Confirm Email Change</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px; }
+        .content { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .button { display: inline-block; background: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; text-align: center; }
+        .warning { background: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.AppName}}</h1>
+        <p>Email Change Request</p>
+    </div>
+
+    <div class="content">
+        <h2>Hello {{.UserName}},</h2>
+
+        <p>Please confirm that you want to use <strong>{{.Email}}</strong> for your {{.AppName}} account:</p>
+
+        <div style="text-align: center;">
+            <a href="{{.ResetLink}}" class="button">Confirm Email</a>
+        </div>
+
+        <div class="warning">
+            <strong>Important:</strong> This link expires in {{.ExpiryTime}}. Your email stays unchanged until you confirm, and other devices will be signed out afterwards.
+        </div>
+
+        <p>If you didn't request this change, you can safely ignore this email.</p>
+
+        <p>Best regards,<br>The {{.CompanyName}} Team</p>
+    </div>
+
+    <div class="footer">
+        <p>This email was sent to {{.Email}}. If you didn't request this, please contact support.</p>
+    </div>
+</body>
+</html>`,
+	},
+	"email_change_notice": {
+		Subject: "Email Change Requested - {{.AppName}}",
+		Template: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Email Change Requested</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px; }
+        .content { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .button { display: inline-block; background: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; text-align: center; }
+        .warning { background: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.AppName}}</h1>
+        <p>Security Notice</p>
+    </div>
+
+    <div class="content">
+        <h2>Hello {{.UserName}},</h2>
+
+        <p>Someone signed in to your {{.AppName}} account asked to change its email to <strong>{{.NewEmail}}</strong>.</p>
+
+        <div class="warning">
+            <strong>Wasn't you?</strong> Change your password right away and contact our support team at {{.SupportURL}}. The change only happens once the new address is confirmed.
+        </div>
+
+        <p>Best regards,<br>The {{.CompanyName}} Team</p>
+    </div>
+
+    <div class="footer">
+        <p>This email was sent to {{.Email}}, the current address of your account.</p>
+    </div>
+</body>
+</html>`,
+	},
+	"account_notice": {
+		Subject: "{{.Heading}} - {{.AppName}}",
+		Template: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Heading}}</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px; }
+        .content { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .button { display: inline-block; background: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; text-align: center; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.AppName}}</h1>
+        <p>{{.Heading}}</p>
+    </div>
+
+    <div class="content">
+        <h2>Hello {{.UserName}},</h2>
+
+        <p>{{.Message}}</p>
+        {{if .ResetLink}}
+        <a href="{{.ResetLink}}" class="button">Reset Password</a>
+
+        <p>This link will expire in {{.ExpiryTime}}.</p>
+        {{end}}
+        <p>If you have questions about this change, contact our support team at {{.SupportURL}}.</p>
+
+        <p>Best regards,<br>The {{.CompanyName}} Team</p>
+    </div>
+
+    <div class="footer">
+        <p>This email was sent to {{.Email}} because an administrator changed your account.</p>
+    </div>
+</body>
+</html>`,
+	},
+	"export_ready": {
+		Subject: "Your Export Is Ready - {{.AppName}}",
+		Template: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Export Ready</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; text-align: center; border-radius: 8px; margin-bottom: 30px; }
+        .content { background: white; padding: 30px; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .button { display: inline-block; background: #007bff; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; font-weight: bold; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; text-align: center; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.AppName}}</h1>
+        <p>Export Ready</p>
+    </div>
+
+    <div class="content">
+        <h2>Hello {{.UserName}},</h2>
+
+        <p>{{.Message}}</p>
+
+        <a href="{{.DownloadLink}}" class="button">Download Export</a>
+
+        <p>The file will be available for {{.ExpiryTime}}, after that you need to request a new export.</p>
+
+        <p>Best regards,<br>The {{.CompanyName}} Team</p>
+    </div>
+
+    <div class="footer">
+        <p>This email was sent to {{.Email}} because you requested a report export.</p>
+    </div>
+</body>
+</html>`,
+	},
 	"otp_verification": {
 		Subject: "Your OTP Code - {{.AppName}}",
 		Template: `
@@ -255,6 +490,69 @@ func SendOTPEmail(toEmail, userName, otpCode string, expiryDuration time.Duratio
 	return SendTemplateEmail("otp_verification", toEmail, data)
 }
 
+// SendMagicLinkEmail sends the passwordless sign-in link together with its 6-digit code
+func SendMagicLinkEmail(toEmail, userName, loginLink, code string, expiryDuration time.Duration) error {
+	data := EmailData{
+		UserName:   userName,
+		Email:      toEmail,
+		LoginLink:  loginLink,
+		OTPCode:    code,
+		ExpiryTime: formatDuration(expiryDuration),
+	}
+
+	return SendTemplateEmail("magic_link", toEmail, data)
+}
+
+// SendEmailChangeConfirmEmail sends the confirmation link to the new address
+func SendEmailChangeConfirmEmail(newEmail, userName, confirmLink string, expiryDuration time.Duration) error {
+	data := EmailData{
+		UserName:   userName,
+		Email:      newEmail,
+		ResetLink:  confirmLink,
+		ExpiryTime: formatDuration(expiryDuration),
+	}
+
+	return SendTemplateEmail("email_change_confirm", newEmail, data)
+}
+
+// SendEmailChangeNoticeEmail warns the current address that a change was requested
+func SendEmailChangeNoticeEmail(oldEmail, userName, newEmail string) error {
+	data := EmailData{
+		UserName: userName,
+		Email:    oldEmail,
+		NewEmail: newEmail,
+	}
+
+	return SendTemplateEmail("email_change_notice", oldEmail, data)
+}
+
+// SendAccountNoticeEmail tells the user an administrator changed their account, resetLink is optional
+func SendAccountNoticeEmail(toEmail, userName, heading, message, resetLink string, expiryDuration time.Duration) error {
+	data := EmailData{
+		UserName:   userName,
+		Email:      toEmail,
+		Heading:    heading,
+		Message:    message,
+		ResetLink:  resetLink,
+		ExpiryTime: formatDuration(expiryDuration),
+	}
+
+	return SendTemplateEmail("account_notice", toEmail, data)
+}
+
+// SendExportReadyEmail tells the admin a requested export can be downloaded until it expires
+func SendExportReadyEmail(toEmail, userName, message, downloadLink string, expiryDuration time.Duration) error {
+	data := EmailData{
+		UserName:     userName,
+		Email:        toEmail,
+		Message:      message,
+		DownloadLink: downloadLink,
+		ExpiryTime:   formatDuration(expiryDuration),
+	}
+
+	return SendTemplateEmail("export_ready", toEmail, data)
+}
+
 // SendWelcomeEmail sends welcome email
 func SendWelcomeEmail(toEmail, userName string) error {
 	data := EmailData{
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthState is what the login redirect stores under its state value until the provider calls back
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"codeVerifier"`
+	Nonce        string `json:"nonce"`
+	ReturnURL    string `json:"returnUrl"`
+
+	// set when a signed-in user links the provider from the profile instead of signing in
+	LinkUserID string `json:"linkUserId,omitempty"`
+}
+
+// ResolveReturnURL only allows a path on the frontend or a URL on one of the allowed origins,
+// anything else would turn the login into an open redirect
+func ResolveReturnURL(raw string) (string, error) {
+	frontend := strings.TrimRight(config.AppConfig.FrontendRedirectURL, "/")
+	if raw == "" {
+		return frontend, nil
+	}
+
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") && !strings.Contains(raw, "\\") {
+		return frontend + raw, nil
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", errors.New("invalid return url")
+	}
+
+	origin := parsed.Scheme + "://" + parsed.Host
+	if origin == originOf(frontend) || slices.Contains(config.AppConfig.AllowedOrigins, origin) {
+		return parsed.String(), nil
+	}
+
+	return "", errors.New("return url is not allowed")
+}
+
+func originOf(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// SetOAuthStateCookie binds the state to the browser that started the login
+func SetOAuthStateCookie(c *gin.Context, state string) {
+	domain := config.AppConfig.CookieDomain
+
+	if domain == "localhost" {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie("oauthState", state, 600, "/", domain, false, true)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie("oauthState", state, 600, "/", domain, true, true)
+	}
+}
+
+func ClearOAuthStateCookie(c *gin.Context) {
+	domain := config.AppConfig.CookieDomain
+
+	if domain == "localhost" {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie("oauthState", "", -1, "/", domain, false, true)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie("oauthState", "", -1, "/", domain, true, true)
+	}
+}
+
+// ExternalIdentity is the account the provider vouches for after the code exchange
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Avatar        string
+}
+
+// FetchExternalIdentity reads the signed-in account from the provider. Google and OIDC ID tokens are
+// verified against the provider's keys and must carry the nonce sent with the authorization request
+func FetchExternalIdentity(ctx context.Context, provider *config.OAuthProvider, token *oauth2.Token, nonce string) (*ExternalIdentity, error) {
+	switch provider.Kind {
+	case "google":
+		return googleIdentity(ctx, provider, token, nonce)
+	case "github":
+		return githubIdentity(ctx, provider, token)
+	case "facebook":
+		return facebookIdentity(ctx, provider, token)
+	case "oidc":
+		return oidcIdentity(ctx, provider, token, nonce)
+	default:
+		return nil, fmt.Errorf("unsupported provider kind %q", provider.Kind)
+	}
+}
+
+func googleIdentity(ctx context.Context, provider *config.OAuthProvider, token *oauth2.Token, nonce string) (*ExternalIdentity, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("id token not found in response")
+	}
+
+	payload, err := idtoken.Validate(ctx, rawIDToken, provider.Config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenNonce, _ := payload.Claims["nonce"].(string)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	identity := &ExternalIdentity{Provider: provider.Name, Subject: payload.Subject}
+	identity.Email, _ = payload.Claims["email"].(string)
+	identity.EmailVerified, _ = payload.Claims["email_verified"].(bool)
+	identity.Name, _ = payload.Claims["name"].(string)
+	identity.Avatar, _ = payload.Claims["picture"].(string)
+
+	return identity, nil
+}
+
+func githubIdentity(ctx context.Context, provider *config.OAuthProvider, token *oauth2.Token) (*ExternalIdentity, error) {
+	client := provider.Config.Client(ctx, token)
+
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(client, provider.UserInfoURL, &profile); err != nil {
+		return nil, err
+	}
+
+	// the profile email is optional and unverified, the primary verified address comes from /user/emails
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(client, provider.UserInfoURL+"/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &ExternalIdentity{
+		Provider: provider.Name,
+		Subject:  strconv.FormatInt(profile.ID, 10),
+		Name:     profile.Name,
+		Avatar:   profile.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = profile.Login
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func facebookIdentity(ctx context.Context, provider *config.OAuthProvider, token *oauth2.Token) (*ExternalIdentity, error) {
+	var profile struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Picture struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := getJSON(provider.Config.Client(ctx, token), provider.UserInfoURL, &profile); err != nil {
+		return nil, err
+	}
+
+	// facebook doesn't say whether the user still owns the email, it is never trusted to match accounts
+	return &ExternalIdentity{
+		Provider:      provider.Name,
+		Subject:       profile.ID,
+		Email:         profile.Email,
+		EmailVerified: false,
+		Name:          profile.Name,
+		Avatar:        profile.Picture.Data.URL,
+	}, nil
+}
+
+func oidcIdentity(ctx context.Context, provider *config.OAuthProvider, token *oauth2.Token, nonce string) (*ExternalIdentity, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("id token not found in response")
+	}
+
+	idToken, err := provider.Verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	var claims struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := getJSON(provider.Config.Client(ctx, token), provider.UserInfoURL, &claims); err != nil {
+		return nil, err
+	}
+	// the userinfo response must describe the account the ID token was issued for
+	if claims.Subject != idToken.Subject {
+		return nil, errors.New("userinfo subject does not match the id token")
+	}
+
+	// some providers send email_verified as the string "true"
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return &ExternalIdentity{
+		Provider:      provider.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		Name:          claims.Name,
+		Avatar:        claims.Picture,
+	}, nil
+}
+
+func getJSON(client *http.Client, endpoint string, out any) error {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
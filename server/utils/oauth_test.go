@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+func TestOIDCIdentityVerifiesIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userinfoSubject := "user-1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"sub": userinfoSubject, "email": "jane@example.com", "email_verified": "true"})
+	}))
+	defer server.Close()
+
+	const issuer = "https://id.example.com"
+	provider := &config.OAuthProvider{
+		Name:        "sso",
+		Kind:        "oidc",
+		Config:      &oauth2.Config{ClientID: "client"},
+		UserInfoURL: server.URL,
+		Verifier:    oidc.NewVerifier(issuer, &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{key.Public()}}, &oidc.Config{ClientID: "client"}),
+	}
+
+	tokenWith := func(signer *rsa.PrivateKey, audience, nonce string) *oauth2.Token {
+		raw, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   issuer,
+			"sub":   "user-1",
+			"aud":   audience,
+			"nonce": nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}).SignedString(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]any{"id_token": raw})
+	}
+
+	ctx := context.Background()
+	identity, err := oidcIdentity(ctx, provider, tokenWith(key, "client", "n-1"), "n-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Subject != "user-1" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	cases := map[string]*oauth2.Token{
+		"foreign signature": tokenWith(other, "client", "n-1"),
+		"other audience":    tokenWith(key, "someone-else", "n-1"),
+		"replayed nonce":    tokenWith(key, "client", "n-0"),
+		"missing id token":  {AccessToken: "access"},
+	}
+	for name, token := range cases {
+		if _, err := oidcIdentity(ctx, provider, token, "n-1"); err == nil {
+			t.Errorf("%s: expected the token to be rejected", name)
+		}
+	}
+
+	userinfoSubject = "user-2"
+	if _, err := oidcIdentity(ctx, provider, tokenWith(key, "client", "n-1"), "n-1"); err == nil {
+		t.Error("expected a userinfo response for another subject to be rejected")
+	}
+}
@@ -0,0 +1,166 @@
+// utils/payout.go
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PayoutLine is one transfer instruction in a bank bulk-transfer file
+type PayoutLine struct {
+	Reference     string
+	BankCode      string
+	AccountNumber string
+	HolderName    string
+	Amount        float64
+}
+
+// PayoutResult is one row of the bank's result file
+type PayoutResult struct {
+	Reference string
+	Status    string
+	Message   string
+}
+
+// fixed-width column sizes, shared by the transfer file and the result file
+const (
+	fixedReferenceWidth = 36
+	fixedBankCodeWidth  = 20
+	fixedAccountWidth   = 34
+	fixedHolderWidth    = 35
+	fixedAmountWidth    = 15
+	fixedStatusWidth    = 10
+)
+
+// BuildPayoutCSV writes one transfer per row, amounts use two decimals
+func BuildPayoutCSV(lines []PayoutLine) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"reference", "bank_code", "account_number", "holder_name", "amount", "currency"})
+	for _, l := range lines {
+		writer.Write([]string{l.Reference, l.BankCode, l.AccountNumber, l.HolderName, fmt.Sprintf("%.2f", l.Amount), "IDR"})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// BuildPayoutFixedWidth writes a header (H), one detail record (D) per transfer and a trailer (T),
+// amounts are zero padded in cents
+func BuildPayoutFixedWidth(batchID string, lines []PayoutLine) []byte {
+	var buf bytes.Buffer
+	var total float64
+
+	fmt.Fprintf(&buf, "H%s%s\n", padRight(batchID, fixedReferenceWidth), time.Now().Format("20060102"))
+	for _, l := range lines {
+		total += l.Amount
+		buf.WriteString("D")
+		buf.WriteString(padRight(l.Reference, fixedReferenceWidth))
+		buf.WriteString(padRight(l.BankCode, fixedBankCodeWidth))
+		buf.WriteString(padRight(l.AccountNumber, fixedAccountWidth))
+		buf.WriteString(padRight(strings.ToUpper(l.HolderName), fixedHolderWidth))
+		buf.WriteString(toCents(l.Amount))
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "T%06d%s\n", len(lines), toCents(total))
+
+	return buf.Bytes()
+}
+
+// ParsePayoutResultCSV reads a result file with the columns reference, status and an optional message
+func ParsePayoutResultCSV(r io.Reader) ([]PayoutResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PayoutResult
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("line %d: expected at least reference and status", i+1)
+		}
+		if i == 0 && strings.EqualFold(strings.TrimSpace(row[0]), "reference") {
+			continue
+		}
+
+		result := PayoutResult{
+			Reference: strings.TrimSpace(row[0]),
+			Status:    strings.ToLower(strings.TrimSpace(row[1])),
+		}
+		if len(row) > 2 {
+			result.Message = strings.TrimSpace(row[2])
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ParsePayoutResultFixedWidth reads detail records laid out as D + reference + status + message,
+// header and trailer records are ignored
+func ParsePayoutResultFixedWidth(r io.Reader) ([]PayoutResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PayoutResult
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || line[0] != 'D' {
+			continue
+		}
+
+		if len(line) < 1+fixedReferenceWidth+fixedStatusWidth {
+			return nil, fmt.Errorf("line %d: record too short", i+1)
+		}
+
+		statusEnd := 1 + fixedReferenceWidth + fixedStatusWidth
+		results = append(results, PayoutResult{
+			Reference: strings.TrimSpace(line[1 : 1+fixedReferenceWidth]),
+			Status:    strings.ToLower(strings.TrimSpace(line[1+fixedReferenceWidth : statusEnd])),
+			Message:   strings.TrimSpace(line[statusEnd:]),
+		})
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("no detail records found")
+	}
+
+	return results, nil
+}
+
+// NormalizePayoutStatus maps the status wording banks use onto paid/failed, anything else is unknown
+func NormalizePayoutStatus(status string) string {
+	switch status {
+	case "paid", "success", "successful", "ok", "completed":
+		return "paid"
+	case "failed", "rejected", "returned", "error":
+		return "failed"
+	default:
+		return ""
+	}
+}
+
+// padRight pads or cuts s to width characters, counted in runes so a multi-byte name is never split
+func padRight(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func toCents(amount float64) string {
+	return fmt.Sprintf("%0*d", fixedAmountWidth, int64(amount*100+0.5))
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBuildPayoutFixedWidthPadsByCharacter(t *testing.T) {
+	lines := []PayoutLine{
+		{Reference: "w-1", BankCode: "014", AccountNumber: "1234567890", HolderName: "Jane Doe", Amount: 100},
+		{Reference: "w-2", BankCode: "014", AccountNumber: "1234567890", HolderName: strings.Repeat("Ñ", 40), Amount: 250.5},
+	}
+
+	records := strings.Split(strings.TrimSuffix(string(BuildPayoutFixedWidth("batch-1", lines)), "\n"), "\n")
+	if len(records) != 4 {
+		t.Fatalf("expected a header, two details and a trailer, got %q", records)
+	}
+
+	width := 1 + fixedReferenceWidth + fixedBankCodeWidth + fixedAccountWidth + fixedHolderWidth + fixedAmountWidth
+	for _, record := range records[1:3] {
+		if !utf8.ValidString(record) {
+			t.Fatalf("expected a valid UTF-8 record, got %q", record)
+		}
+		if n := utf8.RuneCountInString(record); n != width {
+			t.Fatalf("expected %d characters, got %d in %q", width, n, record)
+		}
+	}
+	if !strings.HasSuffix(records[2], "000000000025050") {
+		t.Fatalf("expected the amount in cents at the end of the record, got %q", records[2])
+	}
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Built-in roles, a user holds one of them globally and may be granted more on single events
+const (
+	RoleSuperAdmin   = "super_admin"
+	RoleFinance      = "finance"
+	RoleEventManager = "event_manager"
+	RoleCheckinStaff = "checkin_staff"
+	RoleOrganizer    = "organizer"
+	RoleUser         = "user"
+)
+
+// Permissions checked by the RequirePermission middleware
+const (
+	PermEventsCreate      = "events:create"      // create new events
+	PermEventsManage      = "events:manage"      // update and delete events and their ticket types
+	PermCheckinScan       = "checkin:scan"       // validate QR codes and mark tickets as used
+	PermReportsView       = "reports:view"       // admin dashboard and reports
+	PermWithdrawalsReview = "withdrawals:review" // approve or reject withdrawals and payout accounts
+	PermIdentitiesReview  = "identities:review"  // KYC review
+	PermPayoutsManage     = "payouts:manage"     // payout batches, export and reconciliation
+	PermUsersManage       = "users:manage"       // user sessions and 2FA resets
+	PermUsersImpersonate  = "users:impersonate"  // act as a customer for support
+	PermAPIClientsManage  = "api_clients:manage" // API keys for integrations
+	PermRolesManage       = "roles:manage"       // role changes and event-scoped grants
+	PermOrganizerReports  = "organizer:reports"  // reports and earnings of the organizer's own events
+	PermAuditView         = "audit:view"         // audit trail of every mutating request
+
+	PermTicketsView        = "tickets:view"        // own tickets and their printout
+	PermOrdersCreate       = "orders:create"       // buy and refund own orders
+	PermWithdrawalsRequest = "withdrawals:request" // own withdrawals, payout accounts and identity
+)
+
+// RolePermissions maps every built-in role to what it may do
+var RolePermissions = map[string][]string{
+	RoleSuperAdmin: {
+		PermEventsCreate, PermEventsManage, PermCheckinScan, PermReportsView, PermWithdrawalsReview, PermIdentitiesReview,
+		PermPayoutsManage, PermUsersManage, PermUsersImpersonate, PermAPIClientsManage, PermRolesManage, PermAuditView,
+		PermTicketsView,
+	},
+	RoleFinance: {
+		PermReportsView, PermWithdrawalsReview, PermIdentitiesReview, PermPayoutsManage, PermAuditView,
+	},
+	RoleEventManager: {
+		PermEventsCreate, PermEventsManage, PermCheckinScan,
+	},
+	RoleCheckinStaff: {
+		PermCheckinScan,
+	},
+	RoleOrganizer: {
+		PermEventsCreate, PermOrganizerReports, PermWithdrawalsRequest,
+	},
+	RoleUser: {
+		PermTicketsView, PermOrdersCreate, PermWithdrawalsRequest,
+	},
+}
+
+// Roles is the display order of the built-in roles
+var Roles = []string{RoleSuperAdmin, RoleFinance, RoleEventManager, RoleCheckinStaff, RoleOrganizer, RoleUser}
+
+// ScopableRoles can be granted on a single event on top of the global role
+var ScopableRoles = []string{RoleEventManager, RoleCheckinStaff}
+
+// OwnerPermissions are granted to organizers on the events they own
+var OwnerPermissions = []string{PermEventsManage, PermCheckinScan}
+
+func ValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+func HasPermission(role, permission string) bool {
+	return slices.Contains(RolePermissions[role], permission)
+}
+
+// RolesWithPermission lists the roles that grant the permission, used to look up event-scoped grants
+func RolesWithPermission(permission string) []string {
+	var roles []string
+	for _, role := range Roles {
+		if HasPermission(role, permission) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// IsStaffRole is true for every role other than a regular user
+func IsStaffRole(role string) bool {
+	return role != RoleUser
+}
+
+// RoleRequiresTwoFactor is true for the roles that can move money or change other accounts
+func RoleRequiresTwoFactor(role string) bool {
+	return role == RoleSuperAdmin || role == RoleFinance || role == RoleEventManager
+}
+
+// CanAccessEvent reports whether the request may act on the event, requests allowed through a global
+// role have no event scope and may act on any event
+func CanAccessEvent(c *gin.Context, eventID string) bool {
+	scope, exists := c.Get("eventScope")
+	if !exists {
+		return true
+	}
+	eventIDs, _ := scope.([]string)
+	return slices.Contains(eventIDs, eventID)
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFileNotFound = errors.New("file not found")
+	ErrFileExpired  = errors.New("file has expired")
+)
+
+// FileStorage keeps generated files such as report exports until they expire, an expired file can no
+// longer be opened and is removed by PurgeExpired
+type FileStorage interface {
+	Save(key string, r io.Reader, expiresAt time.Time) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	PurgeExpired(now time.Time) (int, error)
+}
+
+// expirySuffix marks the file next to every stored file that holds its expiry time
+const expirySuffix = ".expires"
+
+// localFileStorage stores the files in a directory on the local disk
+type localFileStorage struct {
+	dir string
+}
+
+func NewLocalFileStorage(dir string) FileStorage {
+	return &localFileStorage{dir: dir}
+}
+
+// Save writes the file through a temporary file so a half-written file is never opened
+func (s *localFileStorage) Save(key string, r io.Reader, expiresAt time.Time) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.WriteFile(path+expirySuffix, []byte(expiresAt.UTC().Format(time.RFC3339)), 0o640); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (s *localFileStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := readExpiry(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(expiresAt) {
+		return nil, ErrFileExpired
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+	return file, err
+}
+
+func (s *localFileStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{path, path + expirySuffix} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeExpired removes every file whose expiry has passed, it returns how many were removed
+func (s *localFileStorage) PurgeExpired(now time.Time) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(s.dir, func(name string, entry os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil || entry.IsDir() || !strings.HasSuffix(name, expirySuffix) {
+			return err
+		}
+
+		path := strings.TrimSuffix(name, expirySuffix)
+		expiresAt, err := readExpiry(path)
+		if err != nil || now.Before(expiresAt) {
+			return nil
+		}
+
+		for _, file := range []string{path, name} {
+			if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// path maps the key into the storage directory, keys may not climb out of it
+func (s *localFileStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.HasSuffix(clean, expirySuffix) {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+func readExpiry(path string) (time.Time, error) {
+	raw, err := os.ReadFile(path + expirySuffix)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(raw)))
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeZoneSegment is a stretch of time in which a location keeps one UTC offset, Offset is formatted
+// for MySQL CONVERT_TZ, e.g. "+07:00"
+type TimeZoneSegment struct {
+	From   time.Time
+	To     time.Time
+	Offset string
+}
+
+// SplitByUTCOffset cuts [from, to) at every daylight saving change of the location so each part can be
+// converted to local time with a fixed offset, which works without the MySQL time zone tables
+func SplitByUTCOffset(from, to time.Time, loc *time.Location) []TimeZoneSegment {
+	var segments []TimeZoneSegment
+
+	for start := from; start.Before(to); {
+		local := start.In(loc)
+		_, offset := local.Zone()
+
+		_, end := local.ZoneBounds()
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+
+		segments = append(segments, TimeZoneSegment{From: start, To: end, Offset: formatUTCOffset(offset)})
+		start = end
+	}
+
+	return segments
+}
+
+func formatUTCOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// UTCOffsetAt is the UTC offset the location has at t, formatted for MySQL CONVERT_TZ
+func UTCOffsetAt(t time.Time, loc *time.Location) string {
+	_, offset := t.In(loc).Zone()
+	return formatUTCOffset(offset)
+}
@@ -9,9 +9,11 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
 
+	"github.com/cloudinary/cloudinary-go/v2/api"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
@@ -141,6 +143,90 @@ func UploadImageWithValidation(fileHeader *multipart.FileHeader) (string, error)
 	return UploadToCloudinary(file)
 }
 
+// UploadPrivateDocument stores a sensitive image such as an identity document as an authenticated asset,
+// it has no public URL and is only reachable through OpenPrivateDocument. The public ID is returned
+func UploadPrivateDocument(fileHeader *multipart.FileHeader) (string, error) {
+	if fileHeader == nil {
+		return "", errors.New("no image file provided")
+	}
+
+	if err := ValidateImageFile(fileHeader); err != nil {
+		return "", err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	uploadResult, err := config.Cloud.Upload.Upload(context.Background(), file, uploader.UploadParams{
+		Folder: config.AppConfig.CloudFolder + "/private",
+		Type:   api.Authenticated,
+		Format: privateDocumentFormat,
+	})
+	if err != nil {
+		log.Printf("failed to upload private document to Cloudinary %v :", err)
+		return "", err
+	}
+
+	return uploadResult.PublicID, nil
+}
+
+// privateDocumentFormat is the format private documents are converted to on upload
+const privateDocumentFormat = "jpg"
+
+// OpenPrivateDocument downloads a private document through a signed URL that expires after a minute,
+// the URL never leaves the server. Documents uploaded before private storage still hold their old
+// public URL, those are fetched as is
+func OpenPrivateDocument(publicID string) (io.ReadCloser, int64, error) {
+	downloadURL := publicID
+	if !strings.HasPrefix(publicID, "https://") {
+		expiresAt := time.Now().Add(time.Minute)
+		signedURL, err := config.Cloud.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+			PublicID:     publicID,
+			Format:       privateDocumentFormat,
+			DeliveryType: api.Authenticated,
+			ExpiresAt:    &expiresAt,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		downloadURL = signedURL
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	res, err := client.Get(downloadURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, 0, fmt.Errorf("failed to download private document: %s", res.Status)
+	}
+	return res.Body, res.ContentLength, nil
+}
+
+// DeletePrivateDocument removes a private document, documents uploaded before private storage are
+// removed through their public URL
+func DeletePrivateDocument(publicID string) error {
+	if publicID == "" {
+		return nil
+	}
+	if strings.HasPrefix(publicID, "https://") {
+		return DeleteFromCloudinary(publicID)
+	}
+
+	_, err := config.Cloud.Upload.Destroy(context.Background(), uploader.DestroyParams{
+		PublicID: publicID,
+		Type:     api.Authenticated,
+	})
+	if err != nil {
+		log.Printf("failed to delete private document from Cloudinary: %v", err)
+	}
+	return err
+}
+
 func CleanupImageOnError(imageURL string) {
 	if imageURL != "" {
 		_ = DeleteFromCloudinary(imageURL)
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"log"
+	"time"
+
+	"github.com/fiqrioemry/event_ticketing_system_app/server/config"
+	"github.com/fiqrioemry/event_ticketing_system_app/server/services"
+)
+
+// ExportWorker runs queued export jobs in the background, each runner takes one job at a time and polls
+// the queue again once it is empty
+type ExportWorker struct {
+	service     services.ExportService
+	concurrency int
+	interval    time.Duration
+}
+
+func NewExportWorker(service services.ExportService) *ExportWorker {
+	return &ExportWorker{
+		service:     service,
+		concurrency: max(config.AppConfig.ExportWorkerConcurrency, 1),
+		interval:    config.AppConfig.ExportWorkerPollInterval,
+	}
+}
+
+func (w *ExportWorker) Start() {
+	// jobs left processing by a previous run are picked up again
+	if err := w.service.RequeueStaleJobs(); err != nil {
+		log.Println("Error requeueing stale export jobs:", err)
+	}
+
+	for range w.concurrency {
+		go w.run()
+	}
+	log.Printf("Export worker started with %d runners", w.concurrency)
+}
+
+func (w *ExportWorker) run() {
+	for {
+		processed, err := w.process()
+		if err != nil {
+			log.Println("Error processing export job:", err)
+		}
+		if !processed || err != nil {
+			time.Sleep(w.interval)
+		}
+	}
+}
+
+// process keeps a panicking job from taking the runner down with it, panics of the report itself are
+// caught by the export service and fail the job
+func (w *ExportWorker) process() (processed bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Export job panicked:", r)
+			processed = true
+		}
+	}()
+	return w.service.ProcessNextJob()
+}